@@ -9,6 +9,7 @@ import (
 	"github.com/gclub/internal/config"
 	"github.com/gclub/internal/middleware"
 	"github.com/gclub/internal/repository"
+	"github.com/gclub/internal/scheduler"
 	"github.com/gclub/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -23,21 +24,127 @@ func main() {
 
 	// Initialize database
 	db := config.InitDB()
+	loyalty := config.LoadLoyaltyConfig()
+	if err := loyalty.Validate(); err != nil {
+		log.Fatalf("Invalid loyalty config: %v", err)
+	}
+
+	// Register the campaign types campaigns can use
+	campaignTypes, err := service.NewCampaignTypeRegistry(service.BuiltinCampaignTypes()...)
+	if err != nil {
+		log.Fatalf("Failed to register campaign types: %v", err)
+	}
 
 	// Initialize repositories
+	transactor := repository.NewTransactor(db)
 	userRepo := repository.NewUserRepository(db)
 	couponRepo := repository.NewCouponRepository(db)
 	campaignRepo := repository.NewCampaignRepository(db)
+	pointsRepo := repository.NewPointsRepository(db)
+	tierRepo := repository.NewTierRepository(db)
+	purchaseRepo := repository.NewPurchaseRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	rewardRepo := repository.NewRewardRepository(db)
+	conversionRateRepo := repository.NewConversionRateRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	householdRepo := repository.NewHouseholdRepository(db)
+	referralRepo := repository.NewReferralRepository(db)
+	celebrationRepo := repository.NewCelebrationRepository(db)
+	challengeRepo := repository.NewChallengeRepository(db)
+	leaderboardRepo := repository.NewLeaderboardRepository(db)
+	segmentRepo := repository.NewSegmentRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	experimentRepo := repository.NewExperimentRepository(db)
+	approvalRepo := repository.NewApprovalRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
 
 	// Initialize services
 	userService := service.NewUserService(userRepo)
-	couponService := service.NewCouponService(couponRepo)
-	campaignService := service.NewCampaignService(campaignRepo)
+	couponService := service.NewCouponService(couponRepo, userRepo, tierRepo, segmentRepo, loyalty)
+	challengeService := service.NewChallengeService(challengeRepo, tierRepo)
+	leaderboardService := service.NewLeaderboardService(leaderboardRepo, userRepo, challengeRepo)
+	campaignService := service.NewCampaignService(campaignRepo, userRepo, pointsRepo, tierRepo, segmentRepo, experimentRepo, campaignTypes, loyalty, challengeService)
+	pointsService := service.NewPointsService(pointsRepo, userRepo)
+	tierService := service.NewTierService(tierRepo, userRepo, pointsRepo)
+	referralService := service.NewReferralService(referralRepo, userRepo, loyalty)
+	celebrationService := service.NewCelebrationService(celebrationRepo, userRepo, loyalty)
+	transactionService := service.NewTransactionService(purchaseRepo, userRepo, campaignRepo, tierRepo, segmentRepo, experimentRepo, campaignTypes, loyalty, referralService, challengeService)
+	refundService := service.NewRefundService(refundRepo, purchaseRepo, pointsRepo, userRepo, loyalty)
+	rewardService := service.NewRewardService(rewardRepo, userRepo)
+	conversionService := service.NewConversionService(conversionRateRepo, couponRepo, pointsRepo, userRepo, transactor)
+	transferService := service.NewTransferService(transferRepo, householdRepo, userRepo, loyalty)
+	householdService := service.NewHouseholdService(householdRepo, transferRepo, userRepo, loyalty)
+	segmentService := service.NewSegmentService(segmentRepo, userRepo)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, loyalty)
+	experimentService := service.NewExperimentService(experimentRepo, campaignRepo, campaignTypes)
+	approvalService := service.NewApprovalService(approvalRepo, campaignRepo, couponRepo)
+	templateService := service.NewTemplateService(templateRepo, campaignService, couponService)
+	simulationService := service.NewSimulationService(purchaseRepo, campaignRepo, userRepo, tierRepo, segmentRepo, campaignTypes, loyalty)
 
 	// Initialize handlers
-	userHandler := api.NewUserHandler(userService)
+	userHandler := api.NewUserHandler(userService, referralService)
 	couponHandler := api.NewCouponHandler(couponService)
 	campaignHandler := api.NewCampaignHandler(campaignService)
+	pointsHandler := api.NewPointsHandler(pointsService)
+	tierHandler := api.NewTierHandler(tierService)
+	transactionHandler := api.NewTransactionHandler(transactionService)
+	refundHandler := api.NewRefundHandler(refundService)
+	rewardHandler := api.NewRewardHandler(rewardService)
+	conversionHandler := api.NewConversionHandler(conversionService)
+	transferHandler := api.NewTransferHandler(transferService)
+	householdHandler := api.NewHouseholdHandler(householdService)
+	challengeHandler := api.NewChallengeHandler(challengeService)
+	leaderboardHandler := api.NewLeaderboardHandler(leaderboardService)
+	segmentHandler := api.NewSegmentHandler(segmentService)
+	analyticsHandler := api.NewAnalyticsHandler(analyticsService)
+	experimentHandler := api.NewExperimentHandler(experimentService)
+	approvalHandler := api.NewApprovalHandler(approvalService)
+	templateHandler := api.NewTemplateHandler(templateService)
+	simulationHandler := api.NewSimulationHandler(simulationService)
+
+	// Initialize background jobs
+	jobs := scheduler.New()
+	jobs.Every("expire-points", time.Hour, func(now time.Time) error {
+		expired, err := pointsService.ExpirePoints(now)
+		if expired > 0 {
+			log.Printf("Expired %d points", expired)
+		}
+		return err
+	})
+	jobs.Every("reevaluate-tiers", 24*time.Hour, func(now time.Time) error {
+		changed, err := tierService.ReevaluateAll(now)
+		if changed > 0 {
+			log.Printf("Updated membership tiers of %d members", changed)
+		}
+		return err
+	})
+	// Hourly, so members are rewarded on the day in their own time zone
+	jobs.Every("celebration-rewards", time.Hour, func(now time.Time) error {
+		issued, err := celebrationService.IssueRewards(now)
+		if issued > 0 {
+			log.Printf("Issued %d birthday and anniversary rewards", issued)
+		}
+		return err
+	})
+	jobs.Every("snapshot-leaderboards", 24*time.Hour, func(now time.Time) error {
+		taken, err := leaderboardService.SnapshotClosedPeriods(now)
+		if taken > 0 {
+			log.Printf("Took %d leaderboard snapshots", taken)
+		}
+		return err
+	})
+	jobs.Every("materialize-segments", 6*time.Hour, func(now time.Time) error {
+		refreshed, err := segmentService.MaterializeAll(now)
+		if refreshed > 0 {
+			log.Printf("Materialized %d segments", refreshed)
+		}
+		return err
+	})
+	jobs.Every("refresh-analytics", 24*time.Hour, func(now time.Time) error {
+		return analyticsService.RefreshRollups(now)
+	})
+	jobs.Start()
+	defer jobs.Stop()
 
 	// Initialize rate limiter
 	rateLimiter := middleware.NewRateLimiter(100, time.Minute)
@@ -75,6 +182,17 @@ func main() {
 		// User routes
 		userRoutes := protected.Group("/users")
 		{
+			userRoutes.GET("/points", pointsHandler.GetPoints)
+			userRoutes.GET("/points/history", pointsHandler.GetHistory)
+			userRoutes.GET("/points/expiring", pointsHandler.GetExpiringPoints)
+			userRoutes.GET("/points/transfers", transferHandler.ListTransfers)
+			userRoutes.POST("/points/transfers", transferHandler.SendPoints)
+			userRoutes.POST("/points/transfers/:id/accept", transferHandler.AcceptTransfer)
+			userRoutes.POST("/points/transfers/:id/decline", transferHandler.DeclineTransfer)
+			userRoutes.POST("/points/transfers/:id/cancel", transferHandler.CancelTransfer)
+			userRoutes.GET("/membership-level", tierHandler.GetMembershipLevel)
+			userRoutes.GET("/tier-history", tierHandler.GetTierHistory)
+			userRoutes.GET("/referrals", userHandler.GetReferralStats)
 			userRoutes.GET("/:id", userHandler.GetUser)
 			userRoutes.PUT("/:id", userHandler.UpdateUser)
 			userRoutes.DELETE("/:id", userHandler.DeleteUser)
@@ -85,22 +203,154 @@ func main() {
 		{
 			couponRoutes.POST("", couponHandler.CreateCoupon)
 			couponRoutes.GET("/:id", couponHandler.GetCoupon)
+			couponRoutes.POST("/:id/clone", couponHandler.CloneCoupon)
 			couponRoutes.PUT("/:id", couponHandler.UpdateCoupon)
 			couponRoutes.DELETE("/:id", couponHandler.DeleteCoupon)
 			couponRoutes.GET("/active", couponHandler.ListActiveCoupons)
 			couponRoutes.POST("/validate", couponHandler.ValidateCoupon)
+			couponRoutes.GET("/history", couponHandler.GetCouponHistory)
+			couponRoutes.GET("/conversions", conversionHandler.ListRates)
+			couponRoutes.POST("/convert", conversionHandler.ConvertPoints)
 		}
 
 		// Campaign routes
 		campaignRoutes := protected.Group("/campaigns")
 		{
-			campaignRoutes.POST("", campaignHandler.CreateCampaign)
+			campaignRoutes.POST("", middleware.RequireRole("admin"), campaignHandler.CreateCampaign)
 			campaignRoutes.GET("/:id", campaignHandler.GetCampaign)
-			campaignRoutes.PUT("/:id", campaignHandler.UpdateCampaign)
-			campaignRoutes.DELETE("/:id", campaignHandler.DeleteCampaign)
+			campaignRoutes.POST("/:id/clone", middleware.RequireRole("admin"), campaignHandler.CloneCampaign)
+			campaignRoutes.PUT("/:id", middleware.RequireRole("admin"), campaignHandler.UpdateCampaign)
+			campaignRoutes.DELETE("/:id", middleware.RequireRole("admin"), campaignHandler.DeleteCampaign)
 			campaignRoutes.GET("/active", campaignHandler.ListActiveCampaigns)
+			campaignRoutes.GET("/history", campaignHandler.GetParticipationHistory)
+			campaignRoutes.GET("/types", campaignHandler.ListCampaignTypes)
 			campaignRoutes.GET("/type/:type", campaignHandler.GetCampaignsByType)
-			campaignRoutes.POST("/apply", campaignHandler.ApplyCampaign)
+			campaignRoutes.POST("/apply", middleware.RequireRole("admin", "integration"), campaignHandler.ApplyCampaign)
+			campaignRoutes.POST("/resolve", campaignHandler.ResolveOffers)
+			campaignRoutes.POST("/simulate", middleware.RequireRole("admin"), simulationHandler.SimulateCampaign)
+			campaignRoutes.GET("/groups", campaignHandler.ListCampaignGroups)
+			campaignRoutes.PUT("/groups", middleware.RequireRole("admin"), campaignHandler.SaveCampaignGroup)
+			campaignRoutes.GET("/:id/occurrences", campaignHandler.ListOccurrences)
+			campaignRoutes.POST("/:id/participate", campaignHandler.Participate)
+			campaignRoutes.GET("/:id/allowance", campaignHandler.GetAllowance)
+			campaignRoutes.GET("/:id/report", middleware.RequireRole("admin"), campaignHandler.GetCampaignReport)
+			campaignRoutes.GET("/:id/variants", middleware.RequireRole("admin"), experimentHandler.ListVariants)
+			campaignRoutes.POST("/:id/variants", middleware.RequireRole("admin"), experimentHandler.CreateVariant)
+			campaignRoutes.DELETE("/:id/variants/:variant_id", middleware.RequireRole("admin"), experimentHandler.DeleteVariant)
+			campaignRoutes.GET("/:id/experiment", middleware.RequireRole("admin"), experimentHandler.GetExperimentReport)
+		}
+
+		// Template routes. Anyone who can create campaigns and coupons can
+		// use the library; admins maintain it.
+		templateRoutes := protected.Group("/templates")
+		{
+			templateRoutes.GET("", templateHandler.ListTemplates)
+			templateRoutes.POST("", middleware.RequireRole("admin"), templateHandler.CreateTemplate)
+			templateRoutes.GET("/:id", templateHandler.GetTemplate)
+			templateRoutes.PUT("/:id", middleware.RequireRole("admin"), templateHandler.UpdateTemplate)
+			templateRoutes.DELETE("/:id", middleware.RequireRole("admin"), templateHandler.DeleteTemplate)
+			templateRoutes.POST("/:id/use", templateHandler.UseTemplate)
+		}
+
+		// Approval routes. Makers submit campaigns and coupons for review,
+		// approvers publish or reject them.
+		approvalRoutes := protected.Group("/approvals")
+		{
+			approvalRoutes.GET("/pending", middleware.RequireRole("approver", "admin"), approvalHandler.ListPending)
+			approvalRoutes.GET("/:type/:id", middleware.RequireRole("approver", "admin"), approvalHandler.GetHistory)
+			approvalRoutes.POST("/:type/:id/submit", middleware.RequireRole("admin"), approvalHandler.Submit)
+			approvalRoutes.POST("/:type/:id/approve", middleware.RequireRole("approver"), approvalHandler.Approve)
+			approvalRoutes.POST("/:type/:id/reject", middleware.RequireRole("approver"), approvalHandler.Reject)
+		}
+
+		// Household routes
+		householdRoutes := protected.Group("/households")
+		{
+			householdRoutes.POST("", householdHandler.CreateHousehold)
+			householdRoutes.GET("/mine", householdHandler.GetHousehold)
+			householdRoutes.POST("/:id/members", householdHandler.InviteMember)
+			householdRoutes.PUT("/:id/members/:user_id", householdHandler.UpdateMember)
+			householdRoutes.DELETE("/:id/members/:user_id", householdHandler.RemoveMember)
+			householdRoutes.POST("/:id/join", householdHandler.JoinHousehold)
+			householdRoutes.POST("/:id/contribute", householdHandler.Contribute)
+			householdRoutes.POST("/:id/withdraw", householdHandler.Withdraw)
+			householdRoutes.GET("/:id/ledger", householdHandler.GetLedger)
+		}
+
+		// Challenge routes
+		challengeRoutes := protected.Group("/challenges")
+		{
+			challengeRoutes.GET("", challengeHandler.GetMyChallenges)
+			challengeRoutes.GET("/badges", challengeHandler.GetMyBadges)
+		}
+
+		// Leaderboard routes
+		leaderboardRoutes := protected.Group("/leaderboards")
+		{
+			leaderboardRoutes.GET("", leaderboardHandler.GetLeaderboard)
+			leaderboardRoutes.GET("/snapshots", leaderboardHandler.GetSnapshot)
+		}
+
+		// Reward routes
+		rewardRoutes := protected.Group("/rewards")
+		{
+			rewardRoutes.GET("/available", rewardHandler.ListAvailableRewards)
+			rewardRoutes.GET("/history", rewardHandler.GetRedemptionHistory)
+			rewardRoutes.GET("/:id", rewardHandler.GetReward)
+			rewardRoutes.POST("/:id/redeem", rewardHandler.RedeemReward)
+		}
+
+		// Transaction routes, used by point of sale and e-commerce systems
+		transactionRoutes := protected.Group("/transactions")
+		transactionRoutes.Use(middleware.RequireRole("admin", "integration"))
+		{
+			transactionRoutes.POST("", transactionHandler.CreateTransaction)
+			transactionRoutes.GET("/:order_id", transactionHandler.GetTransaction)
+			transactionRoutes.POST("/:order_id/refunds", refundHandler.CreateRefund)
+		}
+
+		// Admin routes
+		adminRoutes := protected.Group("/admin")
+		adminRoutes.Use(middleware.RequireRole("admin"))
+		{
+			adminRoutes.POST("/points/adjustments", pointsHandler.AdjustPoints)
+			adminRoutes.POST("/users/:id/points/reconcile", pointsHandler.ReconcileBalance)
+			adminRoutes.GET("/points/expiry-policies", pointsHandler.ListExpiryPolicies)
+			adminRoutes.PUT("/points/expiry-policies", pointsHandler.SetExpiryPolicy)
+			adminRoutes.GET("/tiers", tierHandler.ListTiers)
+			adminRoutes.POST("/tiers", tierHandler.CreateTier)
+			adminRoutes.PUT("/tiers/:id", tierHandler.UpdateTier)
+			adminRoutes.DELETE("/tiers/:id", tierHandler.DeleteTier)
+			adminRoutes.GET("/refunds/review", refundHandler.ListRefundsForReview)
+			adminRoutes.POST("/refunds/:id/resolve", refundHandler.ResolveRefund)
+			adminRoutes.POST("/rewards", rewardHandler.CreateReward)
+			adminRoutes.PUT("/rewards/:id", rewardHandler.UpdateReward)
+			adminRoutes.DELETE("/rewards/:id", rewardHandler.DeleteReward)
+			adminRoutes.GET("/rewards/redemptions", rewardHandler.ListRedemptions)
+			adminRoutes.PUT("/rewards/redemptions/:id/status", rewardHandler.UpdateRedemptionStatus)
+			adminRoutes.POST("/coupon-conversions", conversionHandler.CreateRate)
+			adminRoutes.PUT("/coupon-conversions/:id", conversionHandler.UpdateRate)
+			adminRoutes.DELETE("/coupon-conversions/:id", conversionHandler.DeleteRate)
+			adminRoutes.GET("/challenges", challengeHandler.ListChallenges)
+			adminRoutes.POST("/challenges", challengeHandler.CreateChallenge)
+			adminRoutes.PUT("/challenges/:id", challengeHandler.UpdateChallenge)
+			adminRoutes.DELETE("/challenges/:id", challengeHandler.DeleteChallenge)
+			adminRoutes.GET("/badges", challengeHandler.ListBadges)
+			adminRoutes.POST("/badges", challengeHandler.CreateBadge)
+			adminRoutes.GET("/segments", segmentHandler.ListSegments)
+			adminRoutes.POST("/segments", segmentHandler.CreateSegment)
+			adminRoutes.GET("/segments/:id", segmentHandler.GetSegment)
+			adminRoutes.PUT("/segments/:id", segmentHandler.UpdateSegment)
+			adminRoutes.DELETE("/segments/:id", segmentHandler.DeleteSegment)
+			adminRoutes.GET("/segments/:id/preview", segmentHandler.PreviewSegment)
+			adminRoutes.POST("/segments/:id/materialize", segmentHandler.MaterializeSegment)
+			adminRoutes.GET("/segments/:id/members", segmentHandler.ListSegmentMembers)
+			adminRoutes.GET("/users/:id/tags", segmentHandler.GetUserTags)
+			adminRoutes.PUT("/users/:id/tags", segmentHandler.SetUserTags)
+			adminRoutes.GET("/analytics/rfm", analyticsHandler.GetRFMSummary)
+			adminRoutes.GET("/analytics/rfm/members", analyticsHandler.ListRFMScores)
+			adminRoutes.GET("/analytics/cohorts", analyticsHandler.GetCohortRetention)
+			adminRoutes.GET("/analytics/points-liability", analyticsHandler.GetPointsLiability)
 		}
 	}
 
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	analyticsService service.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService}
+}
+
+// dateRange reads the from and to query parameters as dates. to defaults to
+// today and from to defaultDays before to.
+func dateRange(c *gin.Context, defaultDays int) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date like 2024-06-30"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -defaultDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date like 2024-01-01"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	return from, to, true
+}
+
+func (h *AnalyticsHandler) GetRFMSummary(c *gin.Context) {
+	from, to, ok := dateRange(c, 365)
+	if !ok {
+		return
+	}
+
+	summary, err := h.analyticsService.GetRFMSummary(from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *AnalyticsHandler) ListRFMScores(c *gin.Context) {
+	from, to, ok := dateRange(c, 365)
+	if !ok {
+		return
+	}
+	page, pageSize := pagination(c)
+
+	scores, total, err := h.analyticsService.ListRFMScores(from, to, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"members":   scores,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+func (h *AnalyticsHandler) GetCohortRetention(c *gin.Context) {
+	from, to, ok := dateRange(c, 365)
+	if !ok {
+		return
+	}
+
+	cohorts, err := h.analyticsService.GetCohortRetention(from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cohorts": cohorts})
+}
+
+func (h *AnalyticsHandler) GetPointsLiability(c *gin.Context) {
+	from, to, ok := dateRange(c, 30)
+	if !ok {
+		return
+	}
+
+	report, err := h.analyticsService.GetPointsLiability(from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
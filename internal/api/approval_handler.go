@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ApprovalHandler struct {
+	approvalService service.ApprovalService
+}
+
+func NewApprovalHandler(approvalService service.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{approvalService: approvalService}
+}
+
+type approvalRequest struct {
+	Comment string `json:"comment"`
+}
+
+func (h *ApprovalHandler) Submit(c *gin.Context) {
+	h.transition(c, h.approvalService.Submit, "Submitted for review")
+}
+
+func (h *ApprovalHandler) Approve(c *gin.Context) {
+	h.transition(c, h.approvalService.Approve, "Approved successfully")
+}
+
+func (h *ApprovalHandler) Reject(c *gin.Context) {
+	h.transition(c, h.approvalService.Reject, "Rejected successfully")
+}
+
+func (h *ApprovalHandler) transition(c *gin.Context, action func(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error), message string) {
+	var req approvalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	event, err := action(c.Param("type"), c.Param("id"), c.GetString("user_id"), req.Comment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "event": event})
+}
+
+func (h *ApprovalHandler) GetHistory(c *gin.Context) {
+	history, err := h.approvalService.History(c.Param("type"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+func (h *ApprovalHandler) ListPending(c *gin.Context) {
+	pending, err := h.approvalService.ListPending()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pending)
+}
+
+// actorID is the authenticated user, recorded as the maker of what they
+// create.
+func actorID(c *gin.Context) *uuid.UUID {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
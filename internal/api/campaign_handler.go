@@ -1,11 +1,16 @@
 package api
 
 import (
+	"encoding/csv"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
 	"github.com/gclub/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type CampaignHandler struct {
@@ -22,6 +27,7 @@ func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	campaign.CreatedBy = actorID(c)
 
 	if err := h.campaignService.CreateCampaign(&campaign); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -31,6 +37,22 @@ func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Campaign created successfully", "campaign": campaign})
 }
 
+func (h *CampaignHandler) CloneCampaign(c *gin.Context) {
+	var params service.TemplateParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, err := h.campaignService.CloneCampaign(c.Param("id"), params, actorID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Campaign cloned successfully", "campaign": campaign})
+}
+
 func (h *CampaignHandler) GetCampaign(c *gin.Context) {
 	id := c.Param("id")
 	campaign, err := h.campaignService.GetCampaignByID(id)
@@ -42,14 +64,27 @@ func (h *CampaignHandler) GetCampaign(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"campaign": campaign})
 }
 
+// updateCampaignRequest is a campaign whose is_active may be left out, so
+// editing other fields never pauses or resumes the campaign by accident.
+type updateCampaignRequest struct {
+	domain.Campaign
+	IsActive *bool `json:"is_active"`
+}
+
 func (h *CampaignHandler) UpdateCampaign(c *gin.Context) {
-	var campaign domain.Campaign
-	if err := c.ShouldBindJSON(&campaign); err != nil {
+	var req updateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.campaignService.UpdateCampaign(&campaign); err != nil {
+	campaign := req.Campaign
+	if id, err := uuid.Parse(c.Param("id")); err == nil {
+		campaign.ID = id
+	}
+	campaign.UpdatedBy = actorID(c)
+
+	if err := h.campaignService.UpdateCampaign(&campaign, req.IsActive); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -77,6 +112,53 @@ func (h *CampaignHandler) ListActiveCampaigns(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"campaigns": campaigns})
 }
 
+func (h *CampaignHandler) ListOccurrences(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	occurrences, err := h.campaignService.ListOccurrences(c.Param("id"), limit)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+}
+
+func (h *CampaignHandler) Participate(c *gin.Context) {
+	enrollment, err := h.campaignService.Participate(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Enrolled in campaign successfully", "enrollment": enrollment})
+}
+
+func (h *CampaignHandler) GetAllowance(c *gin.Context) {
+	allowance, err := h.campaignService.GetAllowance(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"allowance": allowance})
+}
+
+func (h *CampaignHandler) GetParticipationHistory(c *gin.Context) {
+	page, pageSize := pagination(c)
+	history, total, err := h.campaignService.ParticipationHistory(c.GetString("user_id"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaigns": history,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
 func (h *CampaignHandler) GetCampaignsByType(c *gin.Context) {
 	campaignType := c.Param("type")
 	campaigns, err := h.campaignService.GetCampaignsByType(campaignType)
@@ -92,6 +174,7 @@ func (h *CampaignHandler) ApplyCampaign(c *gin.Context) {
 	var request struct {
 		CampaignID     string  `json:"campaign_id" binding:"required"`
 		UserID         string  `json:"user_id" binding:"required"`
+		OrderRef       string  `json:"order_ref" binding:"required"`
 		PurchaseAmount float64 `json:"purchase_amount" binding:"required"`
 	}
 
@@ -100,11 +183,148 @@ func (h *CampaignHandler) ApplyCampaign(c *gin.Context) {
 		return
 	}
 
-	result, err := h.campaignService.ApplyCampaign(request.CampaignID, request.UserID, request.PurchaseAmount)
+	result, err := h.campaignService.ApplyCampaign(request.CampaignID, request.UserID, request.OrderRef, request.PurchaseAmount)
+	if errors.Is(err, repository.ErrOrderRefConflict) || errors.Is(err, repository.ErrGroupAlreadyApplied) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"result": result})
+	c.JSON(http.StatusOK, gin.H{
+		"result":        result.Result,
+		"points_earned": result.PointsEarned,
+		"balance":       result.Balance,
+		"allowance":     result.Allowance,
+		"duplicate":     result.Duplicate,
+	})
+}
+
+// GetCampaignReport returns the campaign report as JSON, or as CSV with
+// format=csv: one row per day and group, followed by a total row per group.
+func (h *CampaignHandler) GetCampaignReport(c *gin.Context) {
+	report, err := h.campaignService.GetCampaignReport(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, gin.H{"report": report})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=campaign-"+report.CampaignID.String()+"-report.csv")
+	c.Status(http.StatusOK)
+
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	group := func(holdout bool) string {
+		if holdout {
+			return "holdout"
+		}
+		return "campaign"
+	}
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"date", "group", "applications", "members", "points", "discount", "revenue"})
+	for _, day := range report.Days {
+		w.Write([]string{
+			day.Day.Format("2006-01-02"),
+			group(day.Holdout),
+			strconv.FormatInt(day.Applications, 10),
+			strconv.FormatInt(day.Members, 10),
+			strconv.FormatInt(day.Points, 10),
+			money(day.Discount),
+			money(day.Revenue),
+		})
+	}
+	w.Write([]string{
+		"total",
+		group(false),
+		strconv.FormatInt(report.Applications, 10),
+		strconv.FormatInt(report.Members, 10),
+		strconv.FormatInt(report.PointsIssued, 10),
+		money(report.DiscountIssued),
+		money(report.Revenue),
+	})
+	if report.Holdout != nil {
+		w.Write([]string{
+			"total",
+			group(true),
+			strconv.FormatInt(report.Holdout.Applications, 10),
+			strconv.FormatInt(report.Holdout.Members, 10),
+			strconv.FormatInt(report.Holdout.Points, 10),
+			money(report.Holdout.Discount),
+			money(report.Holdout.Revenue),
+		})
+	}
+	w.Flush()
+}
+
+// ResolveOffers resolves the caller's own offers. Admins and integrations,
+// which resolve baskets on behalf of members, pass the member's user_id.
+func (h *CampaignHandler) ResolveOffers(c *gin.Context) {
+	var request struct {
+		UserID         string                `json:"user_id"`
+		PurchaseAmount float64               `json:"purchase_amount"`
+		Items          []domain.PurchaseItem `json:"items"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The basket total defaults to the sum of its items
+	amount := request.PurchaseAmount
+	if amount == 0 {
+		for _, item := range request.Items {
+			amount += float64(item.Quantity) * item.UnitPrice
+		}
+	}
+
+	userID := c.GetString("user_id")
+	if role := c.GetString("role"); (role == "admin" || role == "integration") && request.UserID != "" {
+		userID = request.UserID
+	}
+
+	resolution, err := h.campaignService.ResolveOffers(userID, amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resolution)
+}
+
+func (h *CampaignHandler) ListCampaignTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"types": h.campaignService.ListCampaignTypes()})
+}
+
+func (h *CampaignHandler) ListCampaignGroups(c *gin.Context) {
+	groups, err := h.campaignService.ListCampaignGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+func (h *CampaignHandler) SaveCampaignGroup(c *gin.Context) {
+	var group domain.CampaignGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.campaignService.SaveCampaignGroup(&group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign group saved successfully", "group": group})
 }
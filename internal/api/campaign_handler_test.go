@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCampaignService implements the calls the tests make; any other call
+// panics on the nil embedded interface.
+type MockCampaignService struct {
+	service.CampaignService
+	mock.Mock
+}
+
+func (m *MockCampaignService) ResolveOffers(userID string, purchaseAmount float64) (*service.OfferResolution, error) {
+	args := m.Called(userID, purchaseAmount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OfferResolution), args.Error(1)
+}
+
+func TestCampaignHandler_ResolveOffers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		role     string
+		wantUser string
+	}{
+		{name: "members resolve their own offers", role: "member", wantUser: "caller"},
+		{name: "admins resolve for the member given", role: "admin", wantUser: "member-1"},
+		{name: "integrations resolve for the member given", role: "integration", wantUser: "member-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaignService := new(MockCampaignService)
+			campaignService.On("ResolveOffers", tt.wantUser, 25.0).Return(&service.OfferResolution{PurchaseAmount: 25}, nil)
+
+			router := gin.New()
+			router.POST("/resolve", func(c *gin.Context) {
+				c.Set("user_id", "caller")
+				c.Set("role", tt.role)
+			}, NewCampaignHandler(campaignService).ResolveOffers)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/resolve", strings.NewReader(`{"user_id":"member-1","purchase_amount":25}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			campaignService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChallengeHandler struct {
+	challengeService service.ChallengeService
+}
+
+func NewChallengeHandler(challengeService service.ChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{challengeService: challengeService}
+}
+
+func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
+	var challenge domain.Challenge
+	if err := c.ShouldBindJSON(&challenge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.challengeService.CreateChallenge(&challenge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Challenge created successfully", "challenge": challenge})
+}
+
+func (h *ChallengeHandler) UpdateChallenge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid challenge id"})
+		return
+	}
+
+	var challenge domain.Challenge
+	if err := c.ShouldBindJSON(&challenge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	challenge.ID = id
+
+	if err := h.challengeService.UpdateChallenge(&challenge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Challenge updated successfully"})
+}
+
+func (h *ChallengeHandler) DeleteChallenge(c *gin.Context) {
+	if err := h.challengeService.DeleteChallenge(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Challenge deleted successfully"})
+}
+
+func (h *ChallengeHandler) ListChallenges(c *gin.Context) {
+	challenges, err := h.challengeService.ListChallenges()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"challenges": challenges})
+}
+
+func (h *ChallengeHandler) CreateBadge(c *gin.Context) {
+	var badge domain.Badge
+	if err := c.ShouldBindJSON(&badge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.challengeService.CreateBadge(&badge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Badge created successfully", "badge": badge})
+}
+
+func (h *ChallengeHandler) ListBadges(c *gin.Context) {
+	badges, err := h.challengeService.ListBadges()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"badges": badges})
+}
+
+func (h *ChallengeHandler) GetMyChallenges(c *gin.Context) {
+	challenges, err := h.challengeService.GetMemberChallenges(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"challenges": challenges})
+}
+
+func (h *ChallengeHandler) GetMyBadges(c *gin.Context) {
+	badges, err := h.challengeService.GetMemberBadges(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"badges": badges})
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConversionHandler struct {
+	conversionService service.ConversionService
+}
+
+func NewConversionHandler(conversionService service.ConversionService) *ConversionHandler {
+	return &ConversionHandler{conversionService: conversionService}
+}
+
+func (h *ConversionHandler) CreateRate(c *gin.Context) {
+	var rate domain.CouponConversionRate
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.conversionService.CreateRate(&rate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Conversion rate created successfully", "rate": rate})
+}
+
+func (h *ConversionHandler) UpdateRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversion rate id"})
+		return
+	}
+
+	var rate domain.CouponConversionRate
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rate.ID = id
+
+	if err := h.conversionService.UpdateRate(&rate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conversion rate updated successfully"})
+}
+
+func (h *ConversionHandler) DeleteRate(c *gin.Context) {
+	if err := h.conversionService.DeleteRate(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conversion rate deleted successfully"})
+}
+
+func (h *ConversionHandler) ListRates(c *gin.Context) {
+	rates, err := h.conversionService.ListActiveRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates})
+}
+
+func (h *ConversionHandler) ConvertPoints(c *gin.Context) {
+	var request struct {
+		RateID string `json:"rate_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coupon, balance, err := h.conversionService.ConvertPoints(c.GetString("user_id"), request.RateID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientPoints) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"coupon": coupon, "balance": balance})
+}
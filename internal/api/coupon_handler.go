@@ -6,6 +6,7 @@ import (
 	"github.com/gclub/internal/domain"
 	"github.com/gclub/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type CouponHandler struct {
@@ -22,6 +23,7 @@ func (h *CouponHandler) CreateCoupon(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	coupon.CreatedBy = actorID(c)
 
 	if err := h.couponService.CreateCoupon(&coupon); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -31,6 +33,22 @@ func (h *CouponHandler) CreateCoupon(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Coupon created successfully", "coupon": coupon})
 }
 
+func (h *CouponHandler) CloneCoupon(c *gin.Context) {
+	var params service.TemplateParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coupon, err := h.couponService.CloneCoupon(c.Param("id"), params, actorID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Coupon cloned successfully", "coupon": coupon})
+}
+
 func (h *CouponHandler) GetCoupon(c *gin.Context) {
 	id := c.Param("id")
 	coupon, err := h.couponService.GetCouponByID(id)
@@ -49,6 +67,10 @@ func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
 		return
 	}
 
+	if id, err := uuid.Parse(c.Param("id")); err == nil {
+		coupon.ID = id
+	}
+
 	if err := h.couponService.UpdateCoupon(&coupon); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -88,7 +110,7 @@ func (h *CouponHandler) ValidateCoupon(c *gin.Context) {
 		return
 	}
 
-	coupon, err := h.couponService.ValidateAndApplyCoupon(request.Code, request.PurchaseAmount)
+	coupon, err := h.couponService.ValidateAndApplyCoupon(request.Code, c.GetString("user_id"), request.PurchaseAmount)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -96,3 +118,13 @@ func (h *CouponHandler) ValidateCoupon(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"coupon": coupon})
 }
+
+func (h *CouponHandler) GetCouponHistory(c *gin.Context) {
+	coupons, err := h.couponService.GetCouponHistory(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coupons": coupons})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ExperimentHandler struct {
+	experimentService service.ExperimentService
+}
+
+func NewExperimentHandler(experimentService service.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{experimentService: experimentService}
+}
+
+func (h *ExperimentHandler) CreateVariant(c *gin.Context) {
+	var variant domain.CampaignVariant
+	if err := c.ShouldBindJSON(&variant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.experimentService.CreateVariant(c.Param("id"), &variant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Variant created successfully", "variant": variant})
+}
+
+func (h *ExperimentHandler) ListVariants(c *gin.Context) {
+	variants, err := h.experimentService.ListVariants(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variants": variants})
+}
+
+func (h *ExperimentHandler) DeleteVariant(c *gin.Context) {
+	if err := h.experimentService.DeleteVariant(c.Param("id"), c.Param("variant_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Variant deleted successfully"})
+}
+
+func (h *ExperimentHandler) GetExperimentReport(c *gin.Context) {
+	report, err := h.experimentService.GetExperimentReport(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"experiment": report})
+}
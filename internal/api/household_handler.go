@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gclub/internal/repository"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type HouseholdHandler struct {
+	householdService service.HouseholdService
+}
+
+func NewHouseholdHandler(householdService service.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{householdService: householdService}
+}
+
+func (h *HouseholdHandler) CreateHousehold(c *gin.Context) {
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household, err := h.householdService.CreateHousehold(c.GetString("user_id"), request.Name)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Household created successfully", "household": household})
+}
+
+func (h *HouseholdHandler) GetHousehold(c *gin.Context) {
+	household, members, err := h.householdService.GetHousehold(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"household": household, "members": members})
+}
+
+type householdMemberRequest struct {
+	Email       string `json:"email"`
+	Contributes bool   `json:"contributes"`
+	CanRedeem   bool   `json:"can_redeem"`
+}
+
+func (h *HouseholdHandler) InviteMember(c *gin.Context) {
+	var request householdMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+
+	member, err := h.householdService.InviteMember(c.Param("id"), c.GetString("user_id"), request.Email, request.Contributes, request.CanRedeem)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Member invited successfully", "member": member})
+}
+
+func (h *HouseholdHandler) JoinHousehold(c *gin.Context) {
+	member, err := h.householdService.JoinHousehold(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Joined household successfully", "member": member})
+}
+
+func (h *HouseholdHandler) UpdateMember(c *gin.Context) {
+	var request householdMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.householdService.UpdateMember(c.Param("id"), c.GetString("user_id"), c.Param("user_id"), request.Contributes, request.CanRedeem)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated successfully", "member": member})
+}
+
+func (h *HouseholdHandler) RemoveMember(c *gin.Context) {
+	if err := h.householdService.RemoveMember(c.Param("id"), c.GetString("user_id"), c.Param("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+type householdPointsRequest struct {
+	Points int `json:"points" binding:"required"`
+}
+
+func (h *HouseholdHandler) Contribute(c *gin.Context) {
+	var request householdPointsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, pool, err := h.householdService.Contribute(c.Param("id"), c.GetString("user_id"), request.Points)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balance": balance, "household_points": pool})
+}
+
+func (h *HouseholdHandler) Withdraw(c *gin.Context) {
+	var request householdPointsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, pool, err := h.householdService.Withdraw(c.Param("id"), c.GetString("user_id"), request.Points)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balance": balance, "household_points": pool})
+}
+
+func (h *HouseholdHandler) GetLedger(c *gin.Context) {
+	page, pageSize := pagination(c)
+	entries, total, err := h.householdService.GetLedger(c.Param("id"), c.GetString("user_id"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": entries,
+		"page":         page,
+		"page_size":    pageSize,
+		"total":        total,
+	})
+}
+
+func householdErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrInsufficientPoints) ||
+		errors.Is(err, repository.ErrInsufficientPoolPoints) ||
+		errors.Is(err, repository.ErrAlreadyInHousehold) ||
+		errors.Is(err, repository.ErrTransferLimit) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
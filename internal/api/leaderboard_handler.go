@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LeaderboardHandler struct {
+	leaderboardService service.LeaderboardService
+}
+
+func NewLeaderboardHandler(leaderboardService service.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{leaderboardService: leaderboardService}
+}
+
+func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at must be a date like 2024-06-30"})
+			return
+		}
+		at = parsed
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	board, err := h.leaderboardService.GetLeaderboard(
+		c.GetString("user_id"),
+		c.DefaultQuery("scope", "global"),
+		c.Query("key"),
+		c.Query("period"),
+		at,
+		limit,
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+func (h *LeaderboardHandler) GetSnapshot(c *gin.Context) {
+	snapshot, err := h.leaderboardService.GetSnapshot(c.DefaultQuery("scope", "global"), c.Query("key"), c.Query("period"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"snapshot": snapshot})
+}
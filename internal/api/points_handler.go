@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PointsHandler struct {
+	pointsService service.PointsService
+}
+
+func NewPointsHandler(pointsService service.PointsService) *PointsHandler {
+	return &PointsHandler{pointsService: pointsService}
+}
+
+func (h *PointsHandler) GetPoints(c *gin.Context) {
+	balance, err := h.pointsService.GetBalance(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"points": balance})
+}
+
+func (h *PointsHandler) GetHistory(c *gin.Context) {
+	page, pageSize := pagination(c)
+	entries, total, err := h.pointsService.GetHistory(c.GetString("user_id"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": entries,
+		"page":         page,
+		"page_size":    pageSize,
+		"total":        total,
+	})
+}
+
+func (h *PointsHandler) AdjustPoints(c *gin.Context) {
+	var request struct {
+		UserID     string `json:"user_id" binding:"required"`
+		Points     int    `json:"points" binding:"required"`
+		ReasonCode string `json:"reason_code" binding:"required"`
+		Note       string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, balance, err := h.pointsService.AdjustPoints(request.UserID, request.Points, request.ReasonCode, request.Note, c.GetString("user_id"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientPoints) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"transaction": entry, "balance": balance})
+}
+
+func (h *PointsHandler) ReconcileBalance(c *gin.Context) {
+	cached, ledger, err := h.pointsService.ReconcileBalance(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"previous_balance": cached, "balance": ledger})
+}
+
+func (h *PointsHandler) GetExpiringPoints(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	total, lots, err := h.pointsService.GetExpiringPoints(c.GetString("user_id"), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expiring_points": total, "expiring_soon": lots})
+}
+
+func (h *PointsHandler) ListExpiryPolicies(c *gin.Context) {
+	policies, err := h.pointsService.ListExpiryPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+func (h *PointsHandler) SetExpiryPolicy(c *gin.Context) {
+	var policy domain.PointsExpiryPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.pointsService.SetExpiryPolicy(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Expiry policy saved successfully", "policy": policy})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type RefundHandler struct {
+	refundService service.RefundService
+}
+
+func NewRefundHandler(refundService service.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+func (h *RefundHandler) CreateRefund(c *gin.Context) {
+	var request struct {
+		RefundID string  `json:"refund_id" binding:"required"`
+		Amount   float64 `json:"amount" binding:"required"`
+		Reason   string  `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.refundService.RefundPurchase(c.Param("order_id"), &domain.Refund{
+		RefundRef: request.RefundID,
+		Amount:    request.Amount,
+		Reason:    request.Reason,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Duplicate {
+		status = http.StatusOK
+	}
+	c.JSON(status, result)
+}
+
+func (h *RefundHandler) ListRefundsForReview(c *gin.Context) {
+	refunds, err := h.refundService.ListRefundsForReview()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds})
+}
+
+func (h *RefundHandler) ResolveRefund(c *gin.Context) {
+	var request struct {
+		Action string `json:"action" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.refundService.ResolveRefund(c.Param("id"), request.Action)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RewardHandler struct {
+	rewardService service.RewardService
+}
+
+func NewRewardHandler(rewardService service.RewardService) *RewardHandler {
+	return &RewardHandler{rewardService: rewardService}
+}
+
+func (h *RewardHandler) CreateReward(c *gin.Context) {
+	var reward domain.Reward
+	if err := c.ShouldBindJSON(&reward); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.rewardService.CreateReward(&reward); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Reward created successfully", "reward": reward})
+}
+
+func (h *RewardHandler) GetReward(c *gin.Context) {
+	reward, err := h.rewardService.GetRewardByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reward": reward})
+}
+
+func (h *RewardHandler) UpdateReward(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reward id"})
+		return
+	}
+
+	var reward domain.Reward
+	if err := c.ShouldBindJSON(&reward); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reward.ID = id
+
+	if err := h.rewardService.UpdateReward(&reward); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reward updated successfully"})
+}
+
+func (h *RewardHandler) DeleteReward(c *gin.Context) {
+	if err := h.rewardService.DeleteReward(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reward deleted successfully"})
+}
+
+func (h *RewardHandler) ListAvailableRewards(c *gin.Context) {
+	rewards, err := h.rewardService.ListAvailableRewards()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rewards": rewards})
+}
+
+func (h *RewardHandler) RedeemReward(c *gin.Context) {
+	redemption, balance, err := h.rewardService.RedeemReward(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientPoints) || errors.Is(err, repository.ErrOutOfStock) ||
+			errors.Is(err, repository.ErrRedemptionLimit) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"redemption": redemption, "balance": balance})
+}
+
+func (h *RewardHandler) GetRedemptionHistory(c *gin.Context) {
+	redemptions, err := h.rewardService.GetRedemptionHistory(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redemptions": redemptions})
+}
+
+func (h *RewardHandler) ListRedemptions(c *gin.Context) {
+	page, pageSize := pagination(c)
+	redemptions, total, err := h.rewardService.ListRedemptions(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"redemptions": redemptions,
+		"page":        page,
+		"page_size":   pageSize,
+		"total":       total,
+	})
+}
+
+func (h *RewardHandler) UpdateRedemptionStatus(c *gin.Context) {
+	var request struct {
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redemption, err := h.rewardService.UpdateRedemptionStatus(c.Param("id"), request.Status, request.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redemption": redemption})
+}
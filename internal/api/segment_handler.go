@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SegmentHandler struct {
+	segmentService service.SegmentService
+}
+
+func NewSegmentHandler(segmentService service.SegmentService) *SegmentHandler {
+	return &SegmentHandler{segmentService: segmentService}
+}
+
+func (h *SegmentHandler) CreateSegment(c *gin.Context) {
+	var segment domain.Segment
+	if err := c.ShouldBindJSON(&segment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.segmentService.CreateSegment(&segment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Segment created successfully", "segment": segment})
+}
+
+func (h *SegmentHandler) GetSegment(c *gin.Context) {
+	segment, err := h.segmentService.GetSegmentByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Segment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, segment)
+}
+
+func (h *SegmentHandler) UpdateSegment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid segment id"})
+		return
+	}
+
+	var segment domain.Segment
+	if err := c.ShouldBindJSON(&segment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	segment.ID = id
+
+	if err := h.segmentService.UpdateSegment(&segment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Segment updated successfully"})
+}
+
+func (h *SegmentHandler) DeleteSegment(c *gin.Context) {
+	if err := h.segmentService.DeleteSegment(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Segment deleted successfully"})
+}
+
+func (h *SegmentHandler) ListSegments(c *gin.Context) {
+	segments, err := h.segmentService.ListSegments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"segments": segments})
+}
+
+func (h *SegmentHandler) PreviewSegment(c *gin.Context) {
+	count, users, err := h.segmentService.PreviewSegment(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"member_count": count, "sample": users})
+}
+
+func (h *SegmentHandler) MaterializeSegment(c *gin.Context) {
+	segment, err := h.segmentService.MaterializeSegment(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Segment materialized successfully", "segment": segment})
+}
+
+func (h *SegmentHandler) ListSegmentMembers(c *gin.Context) {
+	page, pageSize := pagination(c)
+	users, total, err := h.segmentService.ListSegmentMembers(c.Param("id"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"members":   users,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+func (h *SegmentHandler) GetUserTags(c *gin.Context) {
+	tags, err := h.segmentService.GetUserTags(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (h *SegmentHandler) SetUserTags(c *gin.Context) {
+	var request struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.segmentService.SetUserTags(c.Param("id"), request.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tags updated successfully", "tags": tags})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type SimulationHandler struct {
+	simulationService service.SimulationService
+}
+
+func NewSimulationHandler(simulationService service.SimulationService) *SimulationHandler {
+	return &SimulationHandler{simulationService: simulationService}
+}
+
+func (h *SimulationHandler) SimulateCampaign(c *gin.Context) {
+	var req struct {
+		CampaignID string           `json:"campaign_id"`
+		Campaign   *domain.Campaign `json:"campaign"`
+		From       time.Time        `json:"from" binding:"required"`
+		To         time.Time        `json:"to" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	simulation, err := h.simulationService.SimulateCampaign(req.CampaignID, req.Campaign, req.From, req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"simulation": simulation})
+}
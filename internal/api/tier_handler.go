@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TierHandler struct {
+	tierService service.TierService
+}
+
+func NewTierHandler(tierService service.TierService) *TierHandler {
+	return &TierHandler{tierService: tierService}
+}
+
+func (h *TierHandler) CreateTier(c *gin.Context) {
+	var tier domain.Tier
+	if err := c.ShouldBindJSON(&tier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tierService.CreateTier(&tier); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tier created successfully", "tier": tier})
+}
+
+func (h *TierHandler) ListTiers(c *gin.Context) {
+	tiers, err := h.tierService.ListTiers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tiers": tiers})
+}
+
+func (h *TierHandler) UpdateTier(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tier id"})
+		return
+	}
+
+	var tier domain.Tier
+	if err := c.ShouldBindJSON(&tier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tier.ID = id
+
+	if err := h.tierService.UpdateTier(&tier); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tier updated successfully"})
+}
+
+func (h *TierHandler) DeleteTier(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.tierService.DeleteTier(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tier deleted successfully"})
+}
+
+func (h *TierHandler) GetMembershipLevel(c *gin.Context) {
+	membership, err := h.tierService.GetMembership(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, membership)
+}
+
+func (h *TierHandler) GetTierHistory(c *gin.Context) {
+	history, err := h.tierService.GetTierHistory(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
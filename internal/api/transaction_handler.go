@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TransactionHandler struct {
+	transactionService service.TransactionService
+}
+
+func NewTransactionHandler(transactionService service.TransactionService) *TransactionHandler {
+	return &TransactionHandler{transactionService: transactionService}
+}
+
+func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
+	var request struct {
+		OrderID     string                `json:"order_id" binding:"required"`
+		MemberID    string                `json:"member_id"`
+		MemberEmail string                `json:"member_email"`
+		Store       string                `json:"store"`
+		Channel     string                `json:"channel"`
+		Currency    string                `json:"currency"`
+		Subtotal    float64               `json:"subtotal"`
+		Discount    float64               `json:"discount"`
+		Total       float64               `json:"total"`
+		PurchasedAt time.Time             `json:"purchased_at"`
+		Items       []domain.PurchaseItem `json:"items"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	purchase := &domain.Purchase{
+		OrderID:     request.OrderID,
+		Store:       request.Store,
+		Channel:     request.Channel,
+		Currency:    request.Currency,
+		Subtotal:    request.Subtotal,
+		Discount:    request.Discount,
+		Total:       request.Total,
+		PurchasedAt: request.PurchasedAt,
+		Items:       request.Items,
+	}
+
+	result, err := h.transactionService.RecordPurchase(purchase, request.MemberID, request.MemberEmail)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Duplicate {
+		status = http.StatusOK
+	}
+	c.JSON(status, result)
+}
+
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	purchase, err := h.transactionService.GetPurchase(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transaction": purchase})
+}
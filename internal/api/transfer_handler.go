@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/gclub/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	transferService service.TransferService
+}
+
+func NewTransferHandler(transferService service.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+func (h *TransferHandler) SendPoints(c *gin.Context) {
+	var request struct {
+		RecipientEmail string `json:"recipient_email" binding:"required,email"`
+		Points         int    `json:"points" binding:"required"`
+		Message        string `json:"message"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferService.SendPoints(c.GetString("user_id"), request.RecipientEmail, request.Points, request.Message)
+	if err != nil {
+		switch {
+		case transfer != nil && transfer.Status == domain.TransferBlocked:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "transfer": transfer})
+		case errors.Is(err, repository.ErrInsufficientPoints):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Transfer sent successfully", "transfer": transfer})
+}
+
+func (h *TransferHandler) ListTransfers(c *gin.Context) {
+	page, pageSize := pagination(c)
+	transfers, total, err := h.transferService.ListTransfers(c.GetString("user_id"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transfers": transfers,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+func (h *TransferHandler) AcceptTransfer(c *gin.Context) {
+	transfer, balance, err := h.transferService.AcceptTransfer(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer accepted successfully", "transfer": transfer, "balance": balance})
+}
+
+func (h *TransferHandler) DeclineTransfer(c *gin.Context) {
+	transfer, err := h.transferService.DeclineTransfer(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer declined successfully", "transfer": transfer})
+}
+
+func (h *TransferHandler) CancelTransfer(c *gin.Context) {
+	transfer, err := h.transferService.CancelTransfer(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer cancelled successfully", "transfer": transfer})
+}
+
+func transferErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrTransferNotPending) || errors.Is(err, repository.ErrInsufficientPoints) ||
+		errors.Is(err, repository.ErrTransferLimit) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -4,31 +4,62 @@ import (
 	"net/http"
 
 	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/middleware"
 	"github.com/gclub/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService     service.UserService
+	referralService service.ReferralService
 }
 
-func NewUserHandler(userService service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService service.UserService, referralService service.ReferralService) *UserHandler {
+	return &UserHandler{
+		userService:     userService,
+		referralService: referralService,
+	}
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
-	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var request struct {
+		domain.User
+		ReferralCode string `json:"referral_code"`
+		DeviceID     string `json:"device_id"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	user := request.User
+	user.SignupIP = c.ClientIP()
+	user.DeviceID = request.DeviceID
 	if err := h.userService.Register(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	response := gin.H{"message": "User registered successfully"}
+	if request.ReferralCode != "" {
+		// A bad code never blocks the sign-up itself
+		if _, err := h.referralService.AttachReferral(&user, request.ReferralCode); err != nil {
+			response["referral_error"] = err.Error()
+		}
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *UserHandler) GetReferralStats(c *gin.Context) {
+	stats, err := h.referralService.GetReferralStats(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
 }
 
 func (h *UserHandler) Login(c *gin.Context) {
@@ -48,7 +79,13 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	token, err := middleware.GenerateToken(user.ID.String(), user.Email, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "user": user})
 }
 
 func (h *UserHandler) GetUser(c *gin.Context) {
@@ -63,11 +100,18 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 }
 
 func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	var user domain.User
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	user.ID = id
 
 	if err := h.userService.UpdateUser(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Register(user *domain.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserService) Login(email, password string) (*domain.User, error) {
+	args := m.Called(email, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUserByID(id string) (*domain.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateUser(user *domain.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DeleteUser(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestUserHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := &domain.User{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}
+
+	tests := []struct {
+		name       string
+		user       *domain.User
+		loginErr   error
+		wantStatus int
+	}{
+		{name: "issues a token carrying the member's role", user: admin, wantStatus: http.StatusOK},
+		{name: "wrong password", loginErr: errors.New("invalid credentials"), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService := new(MockUserService)
+			userService.On("Login", "admin@example.com", "secret").Return(tt.user, tt.loginErr)
+
+			router := gin.New()
+			router.POST("/login", NewUserHandler(userService, nil).Login)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"admin@example.com","password":"secret"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Token string `json:"token"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			// The token must pass the auth middleware with its claims intact
+			router.GET("/whoami", middleware.AuthMiddleware(), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id"), "email": c.GetString("email"), "role": c.GetString("role")})
+			})
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.Header.Set("Authorization", "Bearer "+body.Token)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var claims map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &claims))
+			assert.Equal(t, admin.ID.String(), claims["user_id"])
+			assert.Equal(t, admin.Email, claims["email"])
+			assert.Equal(t, "admin", claims["role"])
+		})
+	}
+}
@@ -30,6 +30,46 @@ func InitDB() *gorm.DB {
 		&domain.User{},
 		&domain.Coupon{},
 		&domain.Campaign{},
+		&domain.PointsTransaction{},
+		&domain.PointsLot{},
+		&domain.PointsExpiryPolicy{},
+		&domain.Tier{},
+		&domain.TierHistory{},
+		&domain.Purchase{},
+		&domain.PurchaseItem{},
+		&domain.Refund{},
+		&domain.Reward{},
+		&domain.RewardRedemption{},
+		&domain.RewardRedemptionLot{},
+		&domain.CouponConversionRate{},
+		&domain.PointsTransfer{},
+		&domain.Household{},
+		&domain.HouseholdMember{},
+		&domain.HouseholdPointsTransaction{},
+		&domain.HouseholdPointsLot{},
+		&domain.Referral{},
+		&domain.CelebrationReward{},
+		&domain.Challenge{},
+		&domain.ChallengeProgress{},
+		&domain.ChallengeEvent{},
+		&domain.Badge{},
+		&domain.MemberBadge{},
+		&domain.LeaderboardScore{},
+		&domain.LeaderboardSnapshot{},
+		&domain.LeaderboardEntry{},
+		&domain.Segment{},
+		&domain.SegmentMember{},
+		&domain.UserTag{},
+		&domain.MemberActivityDay{},
+		&domain.CohortRetention{},
+		&domain.PointsLiability{},
+		&domain.CampaignGroup{},
+		&domain.CampaignEnrollment{},
+		&domain.ApprovalEvent{},
+		&domain.Template{},
+		&domain.CampaignApplication{},
+		&domain.CampaignVariant{},
+		&domain.ExperimentAssignment{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
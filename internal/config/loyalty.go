@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gclub/internal/domain"
+)
+
+// LoyaltyConfig holds the loyalty program rules that are set per deployment.
+type LoyaltyConfig struct {
+	// Base points earned per unit of currency spent
+	PointsPerCurrencyUnit float64
+	// What one point is worth in currency, for valuing the points liability
+	PointValue float64
+	// What to do when a refund claws back more points than the member holds:
+	// allow_debt, cap_at_zero or flag_for_review
+	RefundBalancePolicy string
+	// Most points a member may send to other members per day
+	TransferDailyLimit int
+	// Most transfers a member may send per day
+	TransferMaxPerDay int
+	// Members younger than this cannot send points
+	TransferMinAccountAgeDays int
+	// Most distinct members a member may receive points from per day
+	TransferMaxSendersPerDay int
+	// Hours the recipient has to accept a transfer
+	TransferExpiryHours int
+	// How referrals are rewarded: points or coupon
+	ReferralRewardType string
+	// Points, or coupon value, for the member who referred
+	ReferralReferrerReward float64
+	// Points, or coupon value, for the new member
+	ReferralRefereeReward float64
+	// Smallest first purchase that qualifies a referral
+	ReferralMinPurchase float64
+	// Days a referral reward coupon stays valid
+	ReferralCouponValidityDays int
+	// Time zone for members who have not set their own
+	DefaultTimezone string
+	// How birthdays are rewarded: points or coupon
+	BirthdayRewardType string
+	// Points, or coupon value, on a member's birthday; 0 disables it
+	BirthdayReward float64
+	// How membership anniversaries are rewarded: points or coupon
+	AnniversaryRewardType string
+	// Points, or coupon value, on a membership anniversary; 0 disables it
+	AnniversaryReward float64
+	// Days a birthday or anniversary coupon stays valid
+	CelebrationCouponValidityDays int
+	// Coupons worth this much or more need approval before going live; 0
+	// disables approval for coupons
+	CouponApprovalThreshold float64
+}
+
+func LoadLoyaltyConfig() LoyaltyConfig {
+	return LoyaltyConfig{
+		PointsPerCurrencyUnit: getEnvFloat("POINTS_PER_CURRENCY_UNIT", 1),
+		PointValue:            getEnvFloat("POINT_VALUE", 0.01),
+		RefundBalancePolicy:   getEnv("REFUND_BALANCE_POLICY", "cap_at_zero"),
+
+		TransferDailyLimit:        getEnvInt("TRANSFER_DAILY_LIMIT", 5000),
+		TransferMaxPerDay:         getEnvInt("TRANSFER_MAX_PER_DAY", 5),
+		TransferMinAccountAgeDays: getEnvInt("TRANSFER_MIN_ACCOUNT_AGE_DAYS", 30),
+		TransferMaxSendersPerDay:  getEnvInt("TRANSFER_MAX_SENDERS_PER_DAY", 3),
+		TransferExpiryHours:       getEnvInt("TRANSFER_EXPIRY_HOURS", 72),
+
+		ReferralRewardType:         getEnv("REFERRAL_REWARD_TYPE", "points"),
+		ReferralReferrerReward:     getEnvFloat("REFERRAL_REFERRER_REWARD", 500),
+		ReferralRefereeReward:      getEnvFloat("REFERRAL_REFEREE_REWARD", 250),
+		ReferralMinPurchase:        getEnvFloat("REFERRAL_MIN_PURCHASE", 0),
+		ReferralCouponValidityDays: getEnvInt("REFERRAL_COUPON_VALIDITY_DAYS", 30),
+
+		DefaultTimezone:               getEnv("DEFAULT_TIMEZONE", "UTC"),
+		BirthdayRewardType:            getEnv("BIRTHDAY_REWARD_TYPE", "points"),
+		BirthdayReward:                getEnvFloat("BIRTHDAY_REWARD", 100),
+		AnniversaryRewardType:         getEnv("ANNIVERSARY_REWARD_TYPE", "points"),
+		AnniversaryReward:             getEnvFloat("ANNIVERSARY_REWARD", 100),
+		CelebrationCouponValidityDays: getEnvInt("CELEBRATION_COUPON_VALIDITY_DAYS", 30),
+
+		CouponApprovalThreshold: getEnvFloat("COUPON_APPROVAL_THRESHOLD", 50),
+	}
+}
+
+// Validate rejects settings the program cannot run with, so a typo fails at
+// startup instead of silently changing behaviour.
+func (c LoyaltyConfig) Validate() error {
+	switch c.RefundBalancePolicy {
+	case domain.RefundPolicyAllowDebt, domain.RefundPolicyCapAtZero, domain.RefundPolicyFlagForReview:
+	default:
+		return fmt.Errorf("REFUND_BALANCE_POLICY must be allow_debt, cap_at_zero or flag_for_review, got %q", c.RefundBalancePolicy)
+	}
+	return nil
+}
+
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
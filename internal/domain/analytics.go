@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MemberActivityDay rolls up one member's purchases on one day (UTC), net of
+// refunds. RFM scores are computed from it for any date range.
+type MemberActivityDay struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key" json:"-"`
+	Day    time.Time `gorm:"type:date;not null;uniqueIndex:idx_member_activity_day,priority:1" json:"day"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_member_activity_day,priority:2;index" json:"user_id"`
+	Orders int       `gorm:"not null" json:"orders"`
+	Spend  float64   `gorm:"not null" json:"spend"`
+}
+
+func (a *MemberActivityDay) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// CohortRetention is how many members of a signup month cohort made a
+// purchase in a later month, e.g. cohort 2024-01 in month 2024-03 (offset 2).
+type CohortRetention struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"-"`
+	Cohort        string    `gorm:"not null;uniqueIndex:idx_cohort_month" json:"cohort"` // 2024-01
+	Month         string    `gorm:"not null;uniqueIndex:idx_cohort_month" json:"month"`
+	MonthOffset   int       `gorm:"not null" json:"month_offset"`
+	CohortSize    int       `gorm:"not null" json:"cohort_size"`
+	ActiveMembers int       `gorm:"not null" json:"active_members"`
+}
+
+func (r *CohortRetention) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// PointsLiability is the program's outstanding points at the end of a day,
+// valued in currency. Household pool points are included.
+type PointsLiability struct {
+	ID                uuid.UUID `gorm:"type:uuid;primary_key" json:"-"`
+	Day               time.Time `gorm:"type:date;uniqueIndex;not null" json:"day"`
+	OutstandingPoints int64     `gorm:"not null" json:"outstanding_points"`
+	Members           int64     `gorm:"not null" json:"members"`         // members with a positive balance
+	ExpiringPoints    int64     `gorm:"not null" json:"expiring_points"` // due to expire in the next 30 days
+	PointValue        float64   `gorm:"not null" json:"point_value"`     // currency value of one point
+	Liability         float64   `gorm:"not null" json:"liability"`       // outstanding points valued in currency
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (l *PointsLiability) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Approval states. Campaigns and high-value coupons start as drafts, are
+// submitted for review and approved or rejected by an approver. Only
+// approved items go live; rejected ones can be edited and resubmitted.
+const (
+	ApprovalDraft    = "draft"
+	ApprovalPending  = "pending_review"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// Items that go through approval
+const (
+	ApprovalEntityCampaign = "campaign"
+	ApprovalEntityCoupon   = "coupon"
+)
+
+// ApprovalEvent is one change of an item's approval state. Events are never
+// updated or deleted, so they form the item's full state history.
+type ApprovalEvent struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	EntityType string     `gorm:"not null;index:idx_approval_event_entity,priority:1" json:"entity_type"`
+	EntityID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_approval_event_entity,priority:2" json:"entity_id"`
+	FromStatus string     `json:"from_status,omitempty"`
+	ToStatus   string     `gorm:"not null" json:"to_status"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (e *ApprovalEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// recordDraft starts the state history of an item created as a draft.
+func recordDraft(tx *gorm.DB, entityType string, entityID uuid.UUID, createdBy *uuid.UUID) error {
+	return tx.Create(&ApprovalEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		ToStatus:   ApprovalDraft,
+		ActorID:    createdBy,
+		Comment:    "created",
+	}).Error
+}
@@ -8,18 +8,37 @@ import (
 )
 
 type Campaign struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	Name        string         `gorm:"not null" json:"name"`
-	Description string         `json:"description"`
-	Type        string         `gorm:"not null" json:"type"` // points_multiplier, special_offer, etc.
-	Value       float64        `gorm:"not null" json:"value"`
-	StartDate   time.Time      `json:"start_date"`
-	EndDate     time.Time      `json:"end_date"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	Conditions  string         `gorm:"type:jsonb" json:"conditions"` // JSON string for flexible conditions
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                       uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name                     string         `gorm:"not null" json:"name"`
+	Description              string         `json:"description"`
+	Type                     string         `gorm:"not null" json:"type"` // a registered campaign type, e.g. points_multiplier
+	Value                    float64        `gorm:"not null" json:"value"`
+	StartDate                time.Time      `json:"start_date"`
+	EndDate                  time.Time      `json:"end_date"`
+	Timezone                 string         `json:"timezone"`                 // IANA name; recurring windows are wall-clock times in this zone
+	Recurrence               string         `json:"recurrence,omitempty"`     // RRULE subset, e.g. FREQ=WEEKLY;BYDAY=TU;BYHOUR=17;BYMINUTE=0
+	WindowMinutes            int            `json:"window_minutes,omitempty"` // length of each recurring window
+	IsActive                 bool           `gorm:"default:true" json:"is_active"`
+	Conditions               string         `gorm:"type:jsonb" json:"conditions"`                // JSON string for flexible conditions
+	SegmentID                *uuid.UUID     `gorm:"type:uuid;index" json:"segment_id,omitempty"` // restricts the campaign to a segment's members
+	HoldoutPercent           int            `json:"holdout_percent"`                             // share of eligible members withheld from the campaign to measure its effect
+	Group                    string         `gorm:"index" json:"group,omitempty"`                // campaigns in a group compete under the group's policy
+	Priority                 int            `json:"priority"`                                    // lower goes first under the priority policy
+	EnrollmentRequired       bool           `json:"enrollment_required"`                         // only members who opted in are eligible
+	EnrollmentCap            int            `json:"enrollment_cap,omitempty"`                    // maximum number of enrolled members, 0 for no limit
+	EnrollmentOpensAt        *time.Time     `json:"enrollment_opens_at,omitempty"`               // members can enroll from, defaults to any time before the end date
+	EnrollmentClosesAt       *time.Time     `json:"enrollment_closes_at,omitempty"`              // members can enroll until
+	EnrolledCount            int            `gorm:"not null;default:0" json:"enrolled_count"`
+	MaxApplicationsPerDay    int            `json:"max_applications_per_day,omitempty"`                     // per member, days in the campaign's zone; 0 for no limit
+	MaxApplicationsPerWeek   int            `json:"max_applications_per_week,omitempty"`                    // per member, weeks start on Monday
+	MaxApplicationsPerMember int            `json:"max_applications_per_member,omitempty"`                  // per member over the life of the campaign
+	MaxPointsPerMember       int            `json:"max_points_per_member,omitempty"`                        // the last application is cut down to fit
+	ApprovalStatus           string         `gorm:"not null;default:approved;index" json:"approval_status"` // draft, pending_review, approved or rejected
+	CreatedBy                *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	UpdatedBy                *uuid.UUID     `gorm:"type:uuid" json:"updated_by,omitempty"` // last to edit the definition
+	CreatedAt                time.Time      `json:"created_at"`
+	UpdatedAt                time.Time      `json:"updated_at"`
+	DeletedAt                gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
@@ -28,3 +47,60 @@ func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AfterCreate starts the approval history of campaigns created as drafts,
+// in the same transaction.
+func (c *Campaign) AfterCreate(tx *gorm.DB) error {
+	if c.ApprovalStatus != ApprovalDraft {
+		return nil
+	}
+	return recordDraft(tx, ApprovalEntityCampaign, c.ID, c.CreatedBy)
+}
+
+// Campaign group policies
+const (
+	CampaignPolicyHighest  = "highest"  // only the campaign giving the member the most applies
+	CampaignPolicyAdditive = "additive" // every eligible campaign applies
+	CampaignPolicyPriority = "priority" // only the eligible campaign with the lowest priority number applies
+)
+
+// CampaignGroup sets how overlapping campaigns in a group combine. Groups
+// without a policy use highest.
+type CampaignGroup struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	Policy      string    `gorm:"not null" json:"policy"` // highest, additive or priority
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (g *CampaignGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// CampaignApplication records a campaign applying to an order, or being
+// withheld from it because the member is in the campaign's holdout group.
+// Campaign reports are built from these facts.
+type CampaignApplication struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	CampaignID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_application_order;index:idx_campaign_application_day,priority:1" json:"campaign_id"`
+	OrderRef       string     `gorm:"not null;uniqueIndex:idx_campaign_application_order" json:"order_ref"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Holdout        bool       `gorm:"not null" json:"holdout"`
+	VariantID      *uuid.UUID `gorm:"type:uuid" json:"variant_id,omitempty"` // experiment variant the member was in
+	PurchaseAmount float64    `json:"purchase_amount"`
+	Points         int        `json:"points"`
+	Discount       float64    `json:"discount"`
+	AppliedAt      time.Time  `gorm:"not null;index:idx_campaign_application_day,priority:2" json:"applied_at"`
+}
+
+func (a *CampaignApplication) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Celebration kinds
+const (
+	CelebrationBirthday    = "birthday"
+	CelebrationAnniversary = "anniversary"
+)
+
+// CelebrationReward records a birthday or anniversary reward, so a member
+// receives each one at most once a year.
+type CelebrationReward struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_celebration_once" json:"user_id"`
+	Kind      string     `gorm:"not null;uniqueIndex:idx_celebration_once" json:"kind"` // birthday or anniversary
+	Year      int        `gorm:"not null;uniqueIndex:idx_celebration_once" json:"year"`
+	Points    int        `json:"points"`
+	CouponID  *uuid.UUID `gorm:"type:uuid" json:"coupon_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (r *CelebrationReward) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Challenge types
+const (
+	ChallengeVisits     = "visits"     // purchases on distinct days
+	ChallengeStreak     = "streak"     // consecutive days or weeks with a purchase
+	ChallengeCategories = "categories" // distinct item categories bought
+	ChallengeSpend      = "spend"      // total amount spent
+	ChallengeCampaigns  = "campaigns"  // campaigns taken part in
+)
+
+// Challenge is a goal members work towards during its run, e.g. "visit 5
+// times this month". Conditions use the campaign conditions model, so
+// min_purchase, min_tier and tiers apply, plus stores and categories to
+// restrict which purchases and items count.
+type Challenge struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name         string         `gorm:"not null" json:"name"`
+	Description  string         `json:"description"`
+	Type         string         `gorm:"not null" json:"type"` // visits, streak, categories, spend or campaigns
+	Goal         int            `gorm:"not null" json:"goal"`
+	Period       string         `json:"period"` // day or week, for streaks
+	Conditions   string         `gorm:"type:jsonb" json:"conditions"`
+	RewardPoints int            `json:"reward_points"`
+	BadgeID      *uuid.UUID     `gorm:"type:uuid" json:"badge_id,omitempty"`
+	StartDate    time.Time      `json:"start_date"`
+	EndDate      time.Time      `json:"end_date"`
+	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (c *Challenge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// ChallengeProgress is a member's progress on a challenge.
+type ChallengeProgress struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	ChallengeID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_challenge_member;index:idx_challenge_rank,priority:1" json:"challenge_id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_challenge_member" json:"user_id"`
+	Progress    int        `gorm:"index:idx_challenge_rank,priority:2,sort:desc" json:"progress"`
+	LastPeriod  string     `json:"-"` // last day or week that counted
+	Categories  string     `json:"-"` // JSON list of categories bought so far
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (p *ChallengeProgress) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// ChallengeEvent records an event that counted towards a challenge, so
+// replayed events never count twice.
+type ChallengeEvent struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	ChallengeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_challenge_event" json:"challenge_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_challenge_event" json:"user_id"`
+	EventKey    string    `gorm:"not null;uniqueIndex:idx_challenge_event" json:"event_key"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (e *ChallengeEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+type Badge struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
+	Description string         `json:"description"`
+	ImageURL    string         `json:"image_url"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (b *Badge) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// MemberBadge is a badge a member earned by completing a challenge.
+type MemberBadge struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_member_badge" json:"user_id"`
+	BadgeID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_member_badge" json:"badge_id"`
+	ChallengeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_member_badge" json:"challenge_id"`
+	Badge       *Badge    `gorm:"foreignKey:BadgeID" json:"badge,omitempty"`
+	CreatedAt   time.Time `json:"awarded_at"`
+}
+
+func (b *MemberBadge) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
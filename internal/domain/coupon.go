@@ -8,21 +8,27 @@ import (
 )
 
 type Coupon struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	Code        string         `gorm:"uniqueIndex;not null" json:"code"`
-	Description string         `json:"description"`
-	Discount    float64        `gorm:"not null" json:"discount"`
-	Type        string         `gorm:"not null" json:"type"` // percentage or fixed
-	MinPurchase float64        `json:"min_purchase"`
-	MaxDiscount float64        `json:"max_discount"`
-	StartDate   time.Time      `json:"start_date"`
-	EndDate     time.Time      `json:"end_date"`
-	UsageLimit  int            `json:"usage_limit"`
-	UsedCount   int            `gorm:"default:0" json:"used_count"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Code           string         `gorm:"uniqueIndex;not null" json:"code"`
+	Description    string         `json:"description"`
+	Discount       float64        `gorm:"not null" json:"discount"`
+	Type           string         `gorm:"not null" json:"type"` // percentage or fixed
+	MinPurchase    float64        `json:"min_purchase"`
+	MaxDiscount    float64        `json:"max_discount"`
+	MinTier        string         `json:"min_tier"`                                    // restricts the coupon to members of this tier or above
+	UserID         *uuid.UUID     `gorm:"type:uuid;index" json:"user_id,omitempty"`    // set for coupons issued to a single member
+	SegmentID      *uuid.UUID     `gorm:"type:uuid;index" json:"segment_id,omitempty"` // restricts the coupon to a segment's members
+	Source         string         `json:"source,omitempty"`                            // how a member-bound coupon was issued, e.g. points_conversion
+	StartDate      time.Time      `json:"start_date"`
+	EndDate        time.Time      `json:"end_date"`
+	UsageLimit     int            `json:"usage_limit"`
+	UsedCount      int            `gorm:"default:0" json:"used_count"`
+	IsActive       bool           `gorm:"default:true" json:"is_active"`
+	ApprovalStatus string         `gorm:"not null;default:approved;index" json:"approval_status"` // high-value coupons start as drafts
+	CreatedBy      *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (c *Coupon) BeforeCreate(tx *gorm.DB) error {
@@ -31,3 +37,34 @@ func (c *Coupon) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AfterCreate starts the approval history of coupons created as drafts, in
+// the same transaction.
+func (c *Coupon) AfterCreate(tx *gorm.DB) error {
+	if c.ApprovalStatus != ApprovalDraft {
+		return nil
+	}
+	return recordDraft(tx, ApprovalEntityCoupon, c.ID, c.CreatedBy)
+}
+
+// CouponConversionRate lets members trade points for a personal fixed
+// discount coupon, e.g. 500 points for a 5.00 coupon valid for 90 days.
+type CouponConversionRate struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name         string         `gorm:"not null" json:"name"`
+	Points       int            `gorm:"not null" json:"points"`
+	CouponValue  float64        `gorm:"not null" json:"coupon_value"`
+	ValidityDays int            `gorm:"not null" json:"validity_days"`
+	MinPurchase  float64        `json:"min_purchase"`
+	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (r *CouponConversionRate) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
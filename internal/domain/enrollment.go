@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CampaignEnrollment records a member opting in to a campaign. Campaigns
+// with EnrollmentRequired only apply to enrolled members.
+type CampaignEnrollment struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	CampaignID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_enrollment_member" json:"campaign_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_enrollment_member;index" json:"user_id"`
+	EnrolledAt time.Time `gorm:"not null" json:"enrolled_at"`
+}
+
+func (e *CampaignEnrollment) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CampaignVariant is one arm of a campaign experiment. It overrides the
+// campaign's value and, if set, its conditions. Members are split between
+// variants by weight, after the campaign's holdout is taken out as control.
+type CampaignVariant struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	CampaignID uuid.UUID      `gorm:"type:uuid;not null;index" json:"campaign_id"`
+	Name       string         `gorm:"not null" json:"name"`
+	Weight     int            `gorm:"not null" json:"weight"`
+	Value      *float64       `json:"value,omitempty"`
+	Conditions string         `gorm:"type:jsonb" json:"conditions,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (v *CampaignVariant) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// ExperimentAssignment is the arm a member was put in for a campaign the
+// first time they were evaluated for it. It never changes afterwards, even
+// if the holdout or variant weights do.
+type ExperimentAssignment struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	CampaignID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_experiment_member" json:"campaign_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_experiment_member" json:"user_id"`
+	Control    bool       `gorm:"not null" json:"control"`
+	VariantID  *uuid.UUID `gorm:"type:uuid" json:"variant_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (a *ExperimentAssignment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
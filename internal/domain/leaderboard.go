@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Leaderboard scopes
+const (
+	LeaderboardGlobal    = "global"
+	LeaderboardClub      = "club"
+	LeaderboardStore     = "store"
+	LeaderboardChallenge = "challenge"
+)
+
+// LeaderboardScore is the points a member earned in one scope and period,
+// e.g. the club "downtown" in "2024-06". Scores are kept up to date as points
+// are earned, so rankings never have to sum the ledger.
+type LeaderboardScore struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key" json:"-"`
+	Scope    string    `gorm:"not null;uniqueIndex:idx_leaderboard_member;index:idx_leaderboard_rank,priority:1" json:"scope"`
+	ScopeKey string    `gorm:"not null;uniqueIndex:idx_leaderboard_member;index:idx_leaderboard_rank,priority:2" json:"scope_key"`
+	Period   string    `gorm:"not null;uniqueIndex:idx_leaderboard_member;index:idx_leaderboard_rank,priority:3" json:"period"` // 2024-06 or 2024-W23
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_leaderboard_member" json:"user_id"`
+	Points   int       `gorm:"not null;index:idx_leaderboard_rank,priority:4,sort:desc" json:"points"`
+}
+
+func (s *LeaderboardScore) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// LeaderboardSnapshot keeps the final standings of a leaderboard once its
+// period is over.
+type LeaderboardSnapshot struct {
+	ID       uuid.UUID           `gorm:"type:uuid;primary_key" json:"id"`
+	Scope    string              `gorm:"not null;uniqueIndex:idx_leaderboard_snapshot" json:"scope"`
+	ScopeKey string              `gorm:"not null;uniqueIndex:idx_leaderboard_snapshot" json:"scope_key"`
+	Period   string              `gorm:"not null;uniqueIndex:idx_leaderboard_snapshot" json:"period"`
+	TakenAt  time.Time           `json:"taken_at"`
+	Entries  []*LeaderboardEntry `gorm:"foreignKey:SnapshotID" json:"entries"`
+}
+
+func (s *LeaderboardSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// LeaderboardEntry is a ranked member, either live or in a snapshot.
+type LeaderboardEntry struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"-"`
+	SnapshotID uuid.UUID `gorm:"type:uuid;index" json:"-"`
+	Rank       int       `json:"rank"`
+	UserID     uuid.UUID `gorm:"type:uuid" json:"user_id"`
+	Name       string    `json:"name"`
+	Points     int       `json:"points"`
+}
+
+func (e *LeaderboardEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Points ledger entry types
+const (
+	PointsEarn        = "earn"
+	PointsRedeem      = "redeem"
+	PointsAdjust      = "adjust"
+	PointsExpire      = "expire"
+	PointsReverse     = "reverse"
+	PointsTransferOut = "transfer_out"
+	PointsTransferIn  = "transfer_in"
+)
+
+// Reason codes accepted for manual adjustments
+var AdjustmentReasonCodes = map[string]bool{
+	"goodwill":         true,
+	"correction":       true,
+	"service_recovery": true,
+	"fraud":            true,
+	"migration":        true,
+}
+
+// PointsTransaction is an append-only ledger entry. Points is signed: credits
+// are positive and debits are negative. User.Points caches the running sum.
+type PointsTransaction struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type           string     `gorm:"not null;index" json:"type"` // earn, redeem, adjust, expire, reverse, transfer_out, transfer_in
+	Points         int        `gorm:"not null" json:"points"`
+	BalanceAfter   int        `json:"balance_after"`
+	CampaignID     *uuid.UUID `gorm:"type:uuid;index" json:"campaign_id,omitempty"`
+	OrderRef       string     `gorm:"index" json:"order_ref,omitempty"`
+	Store          string     `json:"store,omitempty"`                              // store of the purchase the points came from
+	ReversesID     *uuid.UUID `gorm:"type:uuid;index" json:"reverses_id,omitempty"` // the entry a reversal claws back
+	PairID         *uuid.UUID `gorm:"type:uuid;index" json:"pair_id,omitempty"`     // shared by both legs of a transfer
+	ReasonCode     string     `json:"reason_code,omitempty"`
+	Note           string     `json:"note,omitempty"`
+	CreatedBy      *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	IdempotencyKey *string    `gorm:"uniqueIndex" json:"-"` // prevents the same movement from being recorded twice
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (p *PointsTransaction) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultPointsExpiryMonths applies to clubs without an expiry policy.
+const DefaultPointsExpiryMonths = 12
+
+// PointsLot tracks a batch of credited points so they can be consumed FIFO
+// and expired once they lapse.
+type PointsLot struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index:idx_points_lots_user_earned" json:"user_id"`
+	TransactionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Points        int        `gorm:"not null" json:"points"`
+	Remaining     int        `gorm:"not null" json:"remaining"`
+	EarnedAt      time.Time  `gorm:"not null;index:idx_points_lots_user_earned" json:"earned_at"`
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at,omitempty"` // nil when the club's points never expire
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (l *PointsLot) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// PointsExpiryPolicy sets how long points earned by members of a club stay
+// valid. The policy with an empty club applies to members without a club.
+type PointsExpiryPolicy struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Club         string    `gorm:"uniqueIndex" json:"club"`
+	ExpiryMonths int       `gorm:"not null" json:"expiry_months"` // 0 means points never expire
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (p *PointsExpiryPolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
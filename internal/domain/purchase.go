@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Purchase is a completed order reported by a point of sale or e-commerce
+// system. Points are earned once per order ID.
+type Purchase struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID        string         `gorm:"uniqueIndex;not null" json:"order_id"`
+	UserID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Store          string         `gorm:"index" json:"store"`
+	Channel        string         `json:"channel"` // pos or ecommerce
+	Currency       string         `json:"currency"`
+	Subtotal       float64        `json:"subtotal"`
+	Discount       float64        `json:"discount"`
+	Total          float64        `gorm:"not null" json:"total"`
+	PointsEarned   int            `json:"points_earned"`
+	RefundedAmount float64        `json:"refunded_amount"`
+	PurchasedAt    time.Time      `gorm:"index" json:"purchased_at"`
+	Items          []PurchaseItem `gorm:"foreignKey:PurchaseID" json:"items"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func (p *Purchase) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+type PurchaseItem struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	PurchaseID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	SKU        string    `json:"sku"`
+	Name       string    `json:"name"`
+	Category   string    `json:"category"`
+	Quantity   int       `json:"quantity"`
+	UnitPrice  float64   `json:"unit_price"`
+	Total      float64   `json:"total"`
+}
+
+func (i *PurchaseItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// Refund statuses
+const (
+	RefundCompleted = "completed"
+	RefundInReview  = "review"
+	RefundResolved  = "resolved"
+)
+
+// Policies for refunds that claw back more points than the member holds
+const (
+	RefundPolicyAllowDebt     = "allow_debt"
+	RefundPolicyCapAtZero     = "cap_at_zero"
+	RefundPolicyFlagForReview = "flag_for_review"
+)
+
+// Refund reverses the points a purchase earned in proportion to the refunded
+// amount. PointsDeficit is the part of the reversal the member's balance could
+// not cover.
+type Refund struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	RefundRef      string    `gorm:"uniqueIndex;not null" json:"refund_id"`
+	PurchaseID     uuid.UUID `gorm:"type:uuid;not null;index" json:"purchase_id"`
+	OrderID        string    `gorm:"index" json:"order_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount         float64   `gorm:"not null" json:"amount"`
+	Reason         string    `json:"reason"`
+	PointsReversed int       `json:"points_reversed"`
+	PointsDeficit  int       `json:"points_deficit"`
+	Status         string    `gorm:"not null;index" json:"status"` // completed, review, resolved
+	Resolution     string    `json:"resolution,omitempty"`         // applied_debt or waived
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// SettleReversals fits the reversal entries to the member's balance under
+// policy. Unless debt is allowed, entries are cut down to what the member
+// still holds and the rest is recorded as the deficit; flag_for_review also
+// puts the refund in review.
+func (r *Refund) SettleReversals(entries []*PointsTransaction, balance int, policy string) {
+	owed := 0
+	for _, entry := range entries {
+		owed -= entry.Points
+	}
+
+	available := balance
+	if available < 0 {
+		available = 0
+	}
+	if owed > available && policy != RefundPolicyAllowDebt {
+		// Only claw back what the member still holds
+		remaining := available
+		for _, entry := range entries {
+			take := -entry.Points
+			if take > remaining {
+				take = remaining
+			}
+			entry.Points = -take
+			remaining -= take
+		}
+		r.PointsDeficit = owed - available
+		if policy == RefundPolicyFlagForReview {
+			r.Status = RefundInReview
+		}
+	}
+	r.PointsReversed = owed - r.PointsDeficit
+}
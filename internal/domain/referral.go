@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Referral statuses
+const (
+	ReferralPending  = "pending"
+	ReferralRewarded = "rewarded"
+	ReferralRejected = "rejected"
+)
+
+// Referral attributes a new member to the member whose code they signed up
+// with. Both are rewarded once the new member makes a qualifying purchase.
+type Referral struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	ReferrerID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"referrer_id"`
+	RefereeID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"referee_id"`
+	Code           string     `gorm:"not null" json:"code"`
+	Status         string     `gorm:"not null;index" json:"status"` // pending, rewarded, rejected
+	RejectReason   string     `json:"reject_reason,omitempty"`
+	SignupIP       string     `gorm:"index" json:"-"`
+	DeviceID       string     `gorm:"index" json:"-"`
+	ReferrerPoints int        `json:"referrer_points"`
+	RewardedAt     *time.Time `json:"rewarded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (r *Referral) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
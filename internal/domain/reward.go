@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Reward struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name           string         `gorm:"not null" json:"name"`
+	Description    string         `json:"description"`
+	Category       string         `json:"category"`
+	ImageURL       string         `json:"image_url"`
+	Points         int            `gorm:"not null" json:"points"` // cost in points
+	Stock          *int           `json:"stock"`                  // nil for unlimited inventory
+	PerMemberLimit int            `json:"per_member_limit"`       // 0 for no limit
+	IsActive       bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (r *Reward) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Redemption statuses
+const (
+	RedemptionRequested = "requested"
+	RedemptionFulfilled = "fulfilled"
+	RedemptionCancelled = "cancelled"
+	RedemptionRefunded  = "refunded"
+)
+
+type RewardRedemption struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	RewardID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"reward_id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Points      int        `gorm:"not null" json:"points"`
+	Status      string     `gorm:"not null;index" json:"status"` // requested, fulfilled, cancelled, refunded
+	Note        string     `json:"note"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
+	Reward      *Reward    `gorm:"foreignKey:RewardID" json:"reward,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (r *RewardRedemption) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RewardRedemptionLot is the part of a member's lot a redemption spent.
+// Cancelling or refunding the redemption gives the points back with the
+// lot's original earn and expiry dates.
+type RewardRedemptionLot struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	RedemptionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"redemption_id"`
+	Points       int        `gorm:"not null" json:"points"`
+	EarnedAt     time.Time  `gorm:"not null" json:"earned_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // nil when the points never expire
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (l *RewardRedemptionLot) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Segment is an audience of members defined by rules. Campaigns and coupons
+// that target a segment only apply to its members.
+type Segment struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name           string         `gorm:"uniqueIndex;not null" json:"name"`
+	Description    string         `json:"description"`
+	Rules          string         `gorm:"type:jsonb;not null" json:"rules"` // SegmentRules as JSON
+	IsActive       bool           `gorm:"default:true" json:"is_active"`
+	MemberCount    int            `json:"member_count"` // as of the last materialization
+	MaterializedAt *time.Time     `json:"materialized_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (s *Segment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SegmentRules are the conditions a member must meet to belong to a segment.
+// Every rule that is set must hold; list rules match any of their values.
+type SegmentRules struct {
+	Tiers               []string   `json:"tiers,omitempty"`
+	MinTier             string     `json:"min_tier,omitempty"`
+	Clubs               []string   `json:"clubs,omitempty"`
+	Tags                []string   `json:"tags,omitempty"`
+	MinPoints           *int       `json:"min_points,omitempty"`
+	MaxPoints           *int       `json:"max_points,omitempty"`
+	PurchasedWithinDays int        `json:"purchased_within_days,omitempty"` // last purchase at most this many days ago
+	NoPurchaseForDays   int        `json:"no_purchase_for_days,omitempty"`  // no purchase for at least this many days
+	MinSpend            float64    `json:"min_spend,omitempty"`             // net of refunds
+	SpendDays           int        `json:"spend_days,omitempty"`            // window for min_spend, all time when zero
+	SignedUpAfter       *time.Time `json:"signed_up_after,omitempty"`
+	SignedUpBefore      *time.Time `json:"signed_up_before,omitempty"`
+}
+
+// SegmentMember is a member of a segment as of its last materialization.
+type SegmentMember struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	SegmentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_segment_member" json:"segment_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_segment_member;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *SegmentMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// UserTag is a free-form label admins attach to members, e.g. vip or staff.
+type UserTag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_tag" json:"user_id"`
+	Tag       string    `gorm:"not null;uniqueIndex:idx_user_tag;index" json:"tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (t *UserTag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
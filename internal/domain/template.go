@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kinds of template
+const (
+	TemplateCampaign = "campaign"
+	TemplateCoupon   = "coupon"
+)
+
+// Template is a reusable campaign or coupon definition. String values in the
+// definition may hold the placeholders {{start_date}}, {{end_date}},
+// {{value}} and {{name_suffix}}, filled in each time the template is used.
+type Template struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
+	Description string         `json:"description"`
+	Kind        string         `gorm:"not null;index" json:"kind"`            // campaign or coupon
+	Definition  string         `gorm:"type:jsonb;not null" json:"definition"` // campaign or coupon JSON with placeholders
+	CreatedBy   *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (t *Template) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
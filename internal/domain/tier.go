@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tier is a membership level. Members qualify for the highest ranked tier
+// whose threshold they reach with points earned over the tier's window.
+type Tier struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name             string         `gorm:"uniqueIndex;not null" json:"name"`
+	Rank             int            `gorm:"uniqueIndex;not null" json:"rank"` // higher is better
+	QualifyingPoints int            `gorm:"not null" json:"qualifying_points"`
+	WindowDays       int            `gorm:"default:365" json:"window_days"`
+	PointsMultiplier float64        `gorm:"default:1" json:"points_multiplier"`
+	GraceDays        int            `json:"grace_days"` // how long a member keeps the tier after falling below it
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (t *Tier) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+type TierHistory struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	FromTier  string    `json:"from_tier"`
+	ToTier    string    `json:"to_tier"`
+	Reason    string    `gorm:"not null" json:"reason"` // upgraded, downgraded, grace_started
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *TierHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
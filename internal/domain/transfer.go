@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transfer statuses
+const (
+	TransferPending   = "pending"
+	TransferCompleted = "completed"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired"
+	TransferBlocked   = "blocked"
+)
+
+// PointsTransfer is a gift of points from one member to another. Points only
+// move once the recipient accepts.
+type PointsTransfer struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	SenderID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"sender_id"`
+	RecipientID uuid.UUID  `gorm:"type:uuid;not null;index" json:"recipient_id"`
+	Points      int        `gorm:"not null" json:"points"`
+	Message     string     `json:"message"`
+	Status      string     `gorm:"not null;index" json:"status"` // pending, completed, declined, cancelled, expired, blocked
+	BlockReason string     `json:"block_reason,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (t *PointsTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Household member roles and statuses
+const (
+	HouseholdRoleOwner  = "owner"
+	HouseholdRoleMember = "member"
+
+	HouseholdInvited = "invited"
+	HouseholdActive  = "active"
+)
+
+// Household pools the points of a family. Contributing members earn into the
+// pool and members allowed to redeem can withdraw from it.
+type Household struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	OwnerID   uuid.UUID `gorm:"type:uuid;not null" json:"owner_id"`
+	Points    int       `gorm:"default:0" json:"points"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (h *Household) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+type HouseholdMember struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HouseholdID uuid.UUID `gorm:"type:uuid;not null;index" json:"household_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"` // a member belongs to one household
+	Role        string    `gorm:"not null" json:"role"`                          // owner or member
+	Status      string    `gorm:"not null" json:"status"`                        // invited until the member joins
+	Contributes bool      `json:"contributes"`                                   // earned points go to the pool
+	CanRedeem   bool      `json:"can_redeem"`                                    // may withdraw from the pool
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (m *HouseholdMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// HouseholdPointsTransaction is the pool side of a movement between a member
+// and the household. It shares its PairID with the member's ledger entry.
+// Contributions are transfer_in, earnings swept into the pool earn, and
+// withdrawals transfer_out. Swept earnings a refund claws back leave the pool
+// as reverse entries with the same SourceID. Expired pool points are expire
+// entries paired with their lot.
+type HouseholdPointsTransaction struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	HouseholdID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"household_id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type         string     `gorm:"not null" json:"type"` // transfer_in, transfer_out, earn, reverse or expire
+	Points       int        `gorm:"not null" json:"points"`
+	BalanceAfter int        `json:"balance_after"`
+	PairID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"pair_id"`
+	SourceID     *uuid.UUID `gorm:"type:uuid;index" json:"source_id,omitempty"` // the member earn entry swept into the pool
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (t *HouseholdPointsTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// HouseholdPointsLot is a batch of points in a household pool. It keeps the
+// earn and expiry dates of the member lot it came from, so pooling points
+// never extends their life.
+type HouseholdPointsLot struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	HouseholdID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_household_points_lots_household_earned" json:"household_id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"` // member who pooled the points
+	TransactionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Points        int        `gorm:"not null" json:"points"`
+	Remaining     int        `gorm:"not null" json:"remaining"`
+	EarnedAt      time.Time  `gorm:"not null;index:idx_household_points_lots_household_earned" json:"earned_at"`
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at,omitempty"` // nil when the points never expire
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (l *HouseholdPointsLot) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -8,15 +8,25 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	Name      string         `json:"name"`
-	Phone     string         `json:"phone"`
-	Points    int            `gorm:"default:0" json:"points"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Email             string         `gorm:"uniqueIndex;not null" json:"email"`
+	Password          string         `gorm:"not null" json:"-"`
+	Name              string         `json:"name"`
+	Phone             string         `json:"phone"`
+	Club              string         `gorm:"index" json:"club"`
+	Birthdate         *time.Time     `gorm:"type:date" json:"birthdate,omitempty"`
+	Timezone          string         `json:"timezone"`                   // IANA name, e.g. Europe/Berlin; empty means the program default
+	LeaderboardOptOut bool           `json:"leaderboard_opt_out"`        // hides the member from leaderboards
+	Points            int            `gorm:"default:0" json:"points"`    // cached balance, the points ledger is authoritative
+	Role              string         `gorm:"default:member" json:"role"` // member, admin, approver or integration
+	Tier              string         `json:"tier"`
+	TierGraceUntil    *time.Time     `json:"tier_grace_until,omitempty"`
+	ReferralCode      *string        `gorm:"uniqueIndex" json:"referral_code,omitempty"`
+	SignupIP          string         `json:"-"`
+	DeviceID          string         `json:"-"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
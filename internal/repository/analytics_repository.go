@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RFMScore is a member's recency, frequency and monetary value over a date
+// range, each scored 1 (lowest fifth of members) to 5 (highest fifth).
+type RFMScore struct {
+	UserID         uuid.UUID `gorm:"column:user_id" json:"user_id"`
+	LastPurchase   time.Time `gorm:"column:last_purchase" json:"last_purchase"`
+	Frequency      int       `gorm:"column:frequency" json:"frequency"`
+	Monetary       float64   `gorm:"column:monetary" json:"monetary"`
+	RecencyScore   int       `gorm:"column:r_score" json:"recency_score"`
+	FrequencyScore int       `gorm:"column:f_score" json:"frequency_score"`
+	MonetaryScore  int       `gorm:"column:m_score" json:"monetary_score"`
+}
+
+// RFMCell is how many members share a combination of RFM scores.
+type RFMCell struct {
+	RecencyScore   int   `gorm:"column:r_score"`
+	FrequencyScore int   `gorm:"column:f_score"`
+	MonetaryScore  int   `gorm:"column:m_score"`
+	Members        int64 `gorm:"column:members"`
+}
+
+// PointsOutstanding is the program's unredeemed points at a point in time.
+type PointsOutstanding struct {
+	Points   int64
+	Members  int64
+	Expiring int64
+}
+
+// rfmQuery scores every member with purchases in a date range. Ties are
+// broken by user id so scores are stable between requests.
+const rfmQuery = `WITH activity AS (
+	SELECT user_id, MAX(day) AS last_purchase, SUM(orders) AS frequency, SUM(spend) AS monetary
+	FROM member_activity_days
+	WHERE day >= ? AND day <= ?
+	GROUP BY user_id
+), scored AS (
+	SELECT user_id, last_purchase, frequency, monetary,
+		NTILE(5) OVER (ORDER BY last_purchase, user_id) AS r_score,
+		NTILE(5) OVER (ORDER BY frequency, user_id) AS f_score,
+		NTILE(5) OVER (ORDER BY monetary, user_id) AS m_score
+	FROM activity
+)
+`
+
+type AnalyticsRepository interface {
+	RFMScores(from, to time.Time, offset, limit int) ([]*RFMScore, int64, error)
+	RFMDistribution(from, to time.Time) ([]*RFMCell, error)
+	ListCohorts(fromCohort, toCohort string) ([]*domain.CohortRetention, error)
+	ListLiability(from, to time.Time) ([]*domain.PointsLiability, error)
+	LatestActivityDay() (*time.Time, error)
+	RebuildActivity(since time.Time) error
+	RebuildCohorts() error
+	Outstanding(now time.Time, expiringWithin time.Duration) (*PointsOutstanding, error)
+	RecordLiability(liability *domain.PointsLiability) error
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+func (r *analyticsRepository) RFMScores(from, to time.Time, offset, limit int) ([]*RFMScore, int64, error) {
+	var total int64
+	err := r.db.Model(&domain.MemberActivityDay{}).
+		Where("day >= ? AND day <= ?", from, to).
+		Distinct("user_id").
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var scores []*RFMScore
+	err = r.db.Raw(rfmQuery+`SELECT * FROM scored
+		ORDER BY r_score + f_score + m_score DESC, monetary DESC, user_id
+		LIMIT ? OFFSET ?`, from, to, limit, offset).
+		Scan(&scores).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return scores, total, nil
+}
+
+func (r *analyticsRepository) RFMDistribution(from, to time.Time) ([]*RFMCell, error) {
+	var cells []*RFMCell
+	err := r.db.Raw(rfmQuery+`SELECT r_score, f_score, m_score, COUNT(*) AS members
+		FROM scored
+		GROUP BY r_score, f_score, m_score`, from, to).
+		Scan(&cells).Error
+	if err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+func (r *analyticsRepository) ListCohorts(fromCohort, toCohort string) ([]*domain.CohortRetention, error) {
+	var rows []*domain.CohortRetention
+	err := r.db.Where("cohort >= ? AND cohort <= ?", fromCohort, toCohort).
+		Order("cohort ASC, month_offset ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *analyticsRepository) ListLiability(from, to time.Time) ([]*domain.PointsLiability, error) {
+	var rows []*domain.PointsLiability
+	err := r.db.Where("day >= ? AND day <= ?", from, to).
+		Order("day ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *analyticsRepository) LatestActivityDay() (*time.Time, error) {
+	var days []time.Time
+	err := r.db.Model(&domain.MemberActivityDay{}).
+		Order("day DESC").
+		Limit(1).
+		Pluck("day", &days).Error
+	if err != nil || len(days) == 0 {
+		return nil, err
+	}
+	return &days[0], nil
+}
+
+// RebuildActivity recomputes the daily activity rollup from since onwards.
+// Days before since are left as they are.
+func (r *analyticsRepository) RebuildActivity(since time.Time) error {
+	since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var rows []*domain.MemberActivityDay
+		err := tx.Model(&domain.Purchase{}).
+			Select("DATE(purchased_at) AS day, user_id, COUNT(*) AS orders, SUM(total - refunded_amount) AS spend").
+			Where("purchased_at >= ?", since).
+			Group("DATE(purchased_at), user_id").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("day >= ?", since).Delete(&domain.MemberActivityDay{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// RebuildCohorts recomputes the retention of every signup month cohort.
+// Each cohort gets a row for its signup month even if nobody bought.
+func (r *analyticsRepository) RebuildCohorts() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var sizes []struct {
+			Cohort string
+			Size   int
+		}
+		err := tx.Model(&domain.User{}).
+			Select("TO_CHAR(created_at, 'YYYY-MM') AS cohort, COUNT(*) AS size").
+			Group("cohort").
+			Scan(&sizes).Error
+		if err != nil {
+			return err
+		}
+
+		var active []struct {
+			Cohort  string
+			Month   string
+			Members int
+		}
+		err = tx.Table("member_activity_days").
+			Select("TO_CHAR(users.created_at, 'YYYY-MM') AS cohort, TO_CHAR(member_activity_days.day, 'YYYY-MM') AS month, COUNT(DISTINCT member_activity_days.user_id) AS members").
+			Joins("JOIN users ON users.id = member_activity_days.user_id AND users.deleted_at IS NULL").
+			Group("cohort, month").
+			Scan(&active).Error
+		if err != nil {
+			return err
+		}
+
+		rows := make(map[[2]string]*domain.CohortRetention, len(sizes)+len(active))
+		sizeOf := make(map[string]int, len(sizes))
+		for _, size := range sizes {
+			sizeOf[size.Cohort] = size.Size
+			rows[[2]string{size.Cohort, size.Cohort}] = &domain.CohortRetention{Cohort: size.Cohort, Month: size.Cohort, CohortSize: size.Size}
+		}
+		for _, a := range active {
+			offset := monthOffset(a.Cohort, a.Month)
+			if offset < 0 {
+				continue
+			}
+			rows[[2]string{a.Cohort, a.Month}] = &domain.CohortRetention{
+				Cohort:        a.Cohort,
+				Month:         a.Month,
+				MonthOffset:   offset,
+				CohortSize:    sizeOf[a.Cohort],
+				ActiveMembers: a.Members,
+			}
+		}
+
+		if err := tx.Where("1 = 1").Delete(&domain.CohortRetention{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		list := make([]*domain.CohortRetention, 0, len(rows))
+		for _, row := range rows {
+			list = append(list, row)
+		}
+		return tx.CreateInBatches(list, 500).Error
+	})
+}
+
+// Outstanding sums the positive balances of members and household pools,
+// and the points due to expire within the given window.
+func (r *analyticsRepository) Outstanding(now time.Time, expiringWithin time.Duration) (*PointsOutstanding, error) {
+	var outstanding PointsOutstanding
+	err := r.db.Model(&domain.User{}).
+		Select("COALESCE(SUM(points), 0) AS points, COUNT(*) AS members").
+		Where("points > 0").
+		Scan(&outstanding).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var pools int64
+	err = r.db.Model(&domain.Household{}).
+		Select("COALESCE(SUM(points), 0)").
+		Where("points > 0").
+		Scan(&pools).Error
+	if err != nil {
+		return nil, err
+	}
+	outstanding.Points += pools
+
+	err = r.db.Model(&domain.PointsLot{}).
+		Select("COALESCE(SUM(remaining), 0)").
+		Where("remaining > 0 AND expires_at > ? AND expires_at <= ?", now, now.Add(expiringWithin)).
+		Scan(&outstanding.Expiring).Error
+	if err != nil {
+		return nil, err
+	}
+	return &outstanding, nil
+}
+
+// RecordLiability stores the liability for its day, replacing an earlier
+// figure for the same day.
+func (r *analyticsRepository) RecordLiability(liability *domain.PointsLiability) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"outstanding_points", "members", "expiring_points", "point_value", "liability", "created_at"}),
+	}).Create(liability).Error
+}
+
+// monthOffset is how many months month is after cohort, both formatted as
+// 2006-01.
+func monthOffset(cohort, month string) int {
+	from, err := time.Parse("2006-01", cohort)
+	if err != nil {
+		return -1
+	}
+	to, err := time.Parse("2006-01", month)
+	if err != nil {
+		return -1
+	}
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidApprovalTransition = errors.New("item is not in a state that allows this")
+
+type ApprovalRepository interface {
+	Transition(event *domain.ApprovalEvent, from ...string) error
+	History(entityType, entityID string) ([]*domain.ApprovalEvent, error)
+	ListPendingCampaigns() ([]*domain.Campaign, error)
+	ListPendingCoupons() ([]*domain.Coupon, error)
+}
+
+type approvalRepository struct {
+	db *gorm.DB
+}
+
+func NewApprovalRepository(db *gorm.DB) ApprovalRepository {
+	return &approvalRepository{db: db}
+}
+
+// approvalModel is the table holding items of entityType.
+func approvalModel(entityType string) (interface{}, error) {
+	switch entityType {
+	case domain.ApprovalEntityCampaign:
+		return &domain.Campaign{}, nil
+	case domain.ApprovalEntityCoupon:
+		return &domain.Coupon{}, nil
+	}
+	return nil, errors.New("unknown approval item type")
+}
+
+// Transition moves the item to event.ToStatus if it is in one of the from
+// states, and appends the event to its history in the same transaction. The
+// item row is locked, so two reviewers cannot both act on it.
+func (r *approvalRepository) Transition(event *domain.ApprovalEvent, from ...string) error {
+	model, err := approvalModel(event.EntityType)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current struct{ ApprovalStatus string }
+		if err := tx.Model(model).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("approval_status").Where("id = ?", event.EntityID).
+			Take(&current).Error; err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range from {
+			if current.ApprovalStatus == status {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return ErrInvalidApprovalTransition
+		}
+
+		if err := tx.Model(model).Where("id = ?", event.EntityID).
+			UpdateColumn("approval_status", event.ToStatus).Error; err != nil {
+			return err
+		}
+		event.FromStatus = current.ApprovalStatus
+		return tx.Create(event).Error
+	})
+}
+
+// History returns the item's approval events, oldest first.
+func (r *approvalRepository) History(entityType, entityID string) ([]*domain.ApprovalEvent, error) {
+	var events []*domain.ApprovalEvent
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *approvalRepository) ListPendingCampaigns() ([]*domain.Campaign, error) {
+	var campaigns []*domain.Campaign
+	err := r.db.Where("approval_status = ?", domain.ApprovalPending).Order("created_at ASC").Find(&campaigns).Error
+	if err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func (r *approvalRepository) ListPendingCoupons() ([]*domain.Coupon, error) {
+	var coupons []*domain.Coupon
+	err := r.db.Where("approval_status = ?", domain.ApprovalPending).Order("created_at ASC").Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
@@ -1,10 +1,67 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrEnrollmentFull      = errors.New("campaign enrollment is full")
+	ErrAlreadyEnrolled     = errors.New("already enrolled in this campaign")
+	ErrCampaignCapReached  = errors.New("campaign limit reached for this member")
+	ErrOrderRefConflict    = errors.New("order was already applied for another member")
+	ErrGroupAlreadyApplied = errors.New("another campaign in the group already applied to this order")
 )
 
+// CampaignUsage is how often a campaign applied to a member, today and this
+// week in the campaign's zone and over its life, and the points it earned
+// them.
+type CampaignUsage struct {
+	Day    int64 `gorm:"column:day"`
+	Week   int64 `gorm:"column:week"`
+	Total  int64 `gorm:"column:total"`
+	Points int64 `gorm:"column:points"`
+}
+
+// CampaignParticipation is a member's part in one campaign: their
+// enrollment, if any, and what the campaign earned them.
+type CampaignParticipation struct {
+	CampaignID    uuid.UUID  `json:"campaign_id"`
+	Name          string     `json:"name"`
+	Type          string     `json:"type"`
+	EnrolledAt    *time.Time `json:"enrolled_at,omitempty"`
+	Applications  int64      `json:"applications"`
+	Points        int64      `json:"points"`
+	Discount      float64    `json:"discount"`
+	LastAppliedAt *time.Time `json:"last_applied_at,omitempty"`
+}
+
+// CampaignApplicationTotals sums the applications of a campaign, either to
+// the members who received it or to its holdout group.
+type CampaignApplicationTotals struct {
+	Applications int64   `json:"applications"`
+	Members      int64   `json:"members"`
+	Points       int64   `json:"points"`
+	Discount     float64 `json:"discount"`
+	Revenue      float64 `json:"revenue"`
+}
+
+// CampaignApplicationDay is one day of a campaign's applications.
+type CampaignApplicationDay struct {
+	Day          time.Time `json:"day"`
+	Holdout      bool      `json:"holdout"`
+	Applications int64     `json:"applications"`
+	Members      int64     `json:"members"`
+	Points       int64     `json:"points"`
+	Discount     float64   `json:"discount"`
+	Revenue      float64   `json:"revenue"`
+}
+
 type CampaignRepository interface {
 	Create(campaign *domain.Campaign) error
 	FindByID(id string) (*domain.Campaign, error)
@@ -12,6 +69,16 @@ type CampaignRepository interface {
 	Delete(id string) error
 	ListActive() ([]*domain.Campaign, error)
 	FindByType(campaignType string) ([]*domain.Campaign, error)
+	ListGroups() ([]*domain.CampaignGroup, error)
+	SaveGroup(group *domain.CampaignGroup) error
+	RecordApplication(application *domain.CampaignApplication) error
+	ApplicationTotals(campaignID string, holdout bool) (*CampaignApplicationTotals, error)
+	ApplicationsByDay(campaignID string) ([]*CampaignApplicationDay, error)
+	Apply(campaign *domain.Campaign, application *domain.CampaignApplication, entry *domain.PointsTransaction) (*CampaignUsage, int, bool, error)
+	Usage(campaign *domain.Campaign, userID string, at time.Time) (*CampaignUsage, error)
+	Enroll(enrollment *domain.CampaignEnrollment) error
+	EnrolledIn(userID string, campaignIDs []uuid.UUID) ([]uuid.UUID, error)
+	ListParticipation(userID string, offset, limit int) ([]*CampaignParticipation, int64, error)
 }
 
 type campaignRepository struct {
@@ -45,7 +112,7 @@ func (r *campaignRepository) Delete(id string) error {
 
 func (r *campaignRepository) ListActive() ([]*domain.Campaign, error) {
 	var campaigns []*domain.Campaign
-	err := r.db.Where("is_active = ? AND end_date > NOW()", true).Find(&campaigns).Error
+	err := r.db.Where("is_active = ? AND approval_status = ? AND end_date > NOW()", true, domain.ApprovalApproved).Find(&campaigns).Error
 	if err != nil {
 		return nil, err
 	}
@@ -54,9 +121,296 @@ func (r *campaignRepository) ListActive() ([]*domain.Campaign, error) {
 
 func (r *campaignRepository) FindByType(campaignType string) ([]*domain.Campaign, error) {
 	var campaigns []*domain.Campaign
-	err := r.db.Where("type = ? AND is_active = ? AND end_date > NOW()", campaignType, true).Find(&campaigns).Error
+	err := r.db.Where("type = ? AND is_active = ? AND approval_status = ? AND end_date > NOW()", campaignType, true, domain.ApprovalApproved).Find(&campaigns).Error
 	if err != nil {
 		return nil, err
 	}
 	return campaigns, nil
 }
+
+func (r *campaignRepository) ListGroups() ([]*domain.CampaignGroup, error) {
+	var groups []*domain.CampaignGroup
+	err := r.db.Order("name ASC").Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// SaveGroup creates the group or updates the policy of the group with the
+// same name.
+func (r *campaignRepository) SaveGroup(group *domain.CampaignGroup) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"policy", "description", "updated_at"}),
+	}).Create(group).Error
+}
+
+// RecordApplication stores an application fact. An order is only counted
+// once per campaign, so repeated submissions are ignored.
+func (r *campaignRepository) RecordApplication(application *domain.CampaignApplication) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(application).Error
+}
+
+func (r *campaignRepository) ApplicationTotals(campaignID string, holdout bool) (*CampaignApplicationTotals, error) {
+	var totals CampaignApplicationTotals
+	err := r.db.Model(&domain.CampaignApplication{}).
+		Select("COUNT(*) AS applications, COUNT(DISTINCT user_id) AS members, COALESCE(SUM(points), 0) AS points, COALESCE(SUM(discount), 0) AS discount, COALESCE(SUM(purchase_amount), 0) AS revenue").
+		Where("campaign_id = ? AND holdout = ?", campaignID, holdout).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+func (r *campaignRepository) ApplicationsByDay(campaignID string) ([]*CampaignApplicationDay, error) {
+	var days []*CampaignApplicationDay
+	err := r.db.Model(&domain.CampaignApplication{}).
+		Select("DATE(applied_at) AS day, holdout, COUNT(*) AS applications, COUNT(DISTINCT user_id) AS members, COALESCE(SUM(points), 0) AS points, COALESCE(SUM(discount), 0) AS discount, COALESCE(SUM(purchase_amount), 0) AS revenue").
+		Where("campaign_id = ?", campaignID).
+		Group("DATE(applied_at), holdout").
+		Order("day ASC, holdout ASC").
+		Scan(&days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// Apply records the application and credits entry, if any, within the
+// campaign's per-member caps. The member row is locked first, so concurrent
+// applications for the same member are counted one after the other. If the
+// order was already recorded for the campaign, application is filled in with
+// the stored one, nothing is credited again and the call reports a replay; an
+// order recorded for another member fails with ErrOrderRefConflict. It
+// returns the member's usage including the application, and their balance.
+// Unless the campaign's group is additive, an order that another campaign of
+// the group already applied to fails with ErrGroupAlreadyApplied.
+func (r *campaignRepository) Apply(campaign *domain.Campaign, application *domain.CampaignApplication, entry *domain.PointsTransaction) (*CampaignUsage, int, bool, error) {
+	var usage *CampaignUsage
+	var balance int
+	var replayed bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", application.UserID).First(&user).Error; err != nil {
+			return err
+		}
+		balance = user.Points
+
+		var existing domain.CampaignApplication
+		err := tx.Where("campaign_id = ? AND order_ref = ?", application.CampaignID, application.OrderRef).
+			First(&existing).Error
+		if err == nil {
+			if existing.UserID != application.UserID {
+				return ErrOrderRefConflict
+			}
+			*application = existing
+			replayed = true
+			usage, err = campaignUsage(tx, campaign, application.UserID, application.AppliedAt)
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := checkGroupStacking(tx, campaign, application); err != nil {
+			return err
+		}
+		if usage, err = limitApplication(tx, campaign, application); err != nil {
+			return err
+		}
+		if entry != nil && application.Points > 0 {
+			entry.Points = application.Points
+			if _, balance, err = recordPoints(tx, entry); err != nil {
+				return err
+			}
+		}
+		return tx.Create(application).Error
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	return usage, balance, replayed, nil
+}
+
+func (r *campaignRepository) Usage(campaign *domain.Campaign, userID string, at time.Time) (*CampaignUsage, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	return campaignUsage(r.db, campaign, id, at)
+}
+
+// checkGroupStacking stops campaigns of a highest or priority group from
+// stacking on one order. It runs within tx, whose member row must be locked.
+// Groups without a saved policy use highest.
+func checkGroupStacking(tx *gorm.DB, campaign *domain.Campaign, application *domain.CampaignApplication) error {
+	if campaign.Group == "" {
+		return nil
+	}
+	var group domain.CampaignGroup
+	err := tx.Where("name = ?", campaign.Group).First(&group).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if group.Policy == domain.CampaignPolicyAdditive {
+		return nil
+	}
+
+	var count int64
+	err = tx.Model(&domain.CampaignApplication{}).
+		Joins("JOIN campaigns ON campaigns.id = campaign_applications.campaign_id").
+		Where(`campaigns."group" = ? AND campaign_applications.campaign_id <> ?`, campaign.Group, campaign.ID).
+		Where("campaign_applications.user_id = ? AND campaign_applications.order_ref = ? AND NOT campaign_applications.holdout",
+			application.UserID, application.OrderRef).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrGroupAlreadyApplied
+	}
+	return nil
+}
+
+// limitApplication enforces the campaign's per-member caps on application
+// within tx, whose member row must be locked. The points cap lowers
+// application.Points to what is left; when a cap leaves nothing,
+// ErrCampaignCapReached is returned. It returns the usage including the
+// application.
+func limitApplication(tx *gorm.DB, campaign *domain.Campaign, application *domain.CampaignApplication) (*CampaignUsage, error) {
+	usage, err := campaignUsage(tx, campaign, application.UserID, application.AppliedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	reached := func(max int, count int64) bool { return max > 0 && count >= int64(max) }
+	if reached(campaign.MaxApplicationsPerDay, usage.Day) ||
+		reached(campaign.MaxApplicationsPerWeek, usage.Week) ||
+		reached(campaign.MaxApplicationsPerMember, usage.Total) {
+		return nil, ErrCampaignCapReached
+	}
+	if campaign.MaxPointsPerMember > 0 && application.Points > 0 {
+		left := int64(campaign.MaxPointsPerMember) - usage.Points
+		if left <= 0 {
+			return nil, ErrCampaignCapReached
+		}
+		if int64(application.Points) > left {
+			application.Points = int(left)
+		}
+	}
+
+	usage.Day++
+	usage.Week++
+	usage.Total++
+	usage.Points += int64(application.Points)
+	return usage, nil
+}
+
+// campaignUsage counts the member's applications of the campaign, leaving
+// out holdout facts.
+func campaignUsage(db *gorm.DB, campaign *domain.Campaign, userID uuid.UUID, at time.Time) (*CampaignUsage, error) {
+	day, week := usageWindows(campaign, at)
+	var usage CampaignUsage
+	err := db.Model(&domain.CampaignApplication{}).
+		Select("COUNT(*) FILTER (WHERE applied_at >= ?) AS day, COUNT(*) FILTER (WHERE applied_at >= ?) AS week, COUNT(*) AS total, COALESCE(SUM(points), 0) AS points", day, week).
+		Where("campaign_id = ? AND user_id = ? AND NOT holdout", campaign.ID, userID).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// usageWindows returns the start of the day and of the week (Monday) at t in
+// the campaign's zone.
+func usageWindows(campaign *domain.Campaign, t time.Time) (time.Time, time.Time) {
+	loc, err := time.LoadLocation(campaign.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return day, week
+}
+
+// Enroll enrolls the member in the campaign. The campaign row is locked
+// while the cap is checked, so concurrent enrollments cannot overfill it.
+func (r *campaignRepository) Enroll(enrollment *domain.CampaignEnrollment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var campaign domain.Campaign
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", enrollment.CampaignID).First(&campaign).Error; err != nil {
+			return err
+		}
+		if campaign.EnrollmentCap > 0 && campaign.EnrolledCount >= campaign.EnrollmentCap {
+			return ErrEnrollmentFull
+		}
+
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(enrollment)
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected == 0 {
+			return ErrAlreadyEnrolled
+		}
+
+		return tx.Model(&domain.Campaign{}).Where("id = ?", campaign.ID).
+			UpdateColumn("enrolled_count", gorm.Expr("enrolled_count + 1")).Error
+	})
+}
+
+// EnrolledIn returns which of the campaigns the member is enrolled in.
+func (r *campaignRepository) EnrolledIn(userID string, campaignIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(campaignIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&domain.CampaignEnrollment{}).
+		Where("user_id = ? AND campaign_id IN ?", userID, campaignIDs).
+		Pluck("campaign_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// participationQuery joins the member's enrollments with their application
+// facts per campaign. Deleted campaigns stay in the history.
+const participationQuery = `
+FROM campaigns c
+LEFT JOIN campaign_enrollments e ON e.campaign_id = c.id AND e.user_id = @user
+LEFT JOIN (
+	SELECT campaign_id, COUNT(*) AS applications, SUM(points) AS points, SUM(discount) AS discount, MAX(applied_at) AS last_applied_at
+	FROM campaign_applications
+	WHERE user_id = @user AND NOT holdout
+	GROUP BY campaign_id
+) a ON a.campaign_id = c.id
+WHERE e.id IS NOT NULL OR a.campaign_id IS NOT NULL`
+
+// ListParticipation lists the campaigns the member enrolled in or earned
+// from, most recent activity first.
+func (r *campaignRepository) ListParticipation(userID string, offset, limit int) ([]*CampaignParticipation, int64, error) {
+	args := map[string]interface{}{"user": userID, "offset": offset, "limit": limit}
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*) "+participationQuery, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var participation []*CampaignParticipation
+	err := r.db.Raw(`SELECT c.id AS campaign_id, c.name, c.type, e.enrolled_at,
+		COALESCE(a.applications, 0) AS applications, COALESCE(a.points, 0) AS points,
+		COALESCE(a.discount, 0) AS discount, a.last_applied_at `+participationQuery+`
+		ORDER BY GREATEST(e.enrolled_at, a.last_applied_at) DESC
+		OFFSET @offset LIMIT @limit`, args).Scan(&participation).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return participation, total, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errAlreadyIssued rolls back a reward the member already received.
+var errAlreadyIssued = errors.New("celebration reward already issued")
+
+type CelebrationRepository interface {
+	Issue(reward *domain.CelebrationReward, entry *domain.PointsTransaction, coupon *domain.Coupon) (bool, error)
+}
+
+type celebrationRepository struct {
+	db *gorm.DB
+}
+
+func NewCelebrationRepository(db *gorm.DB) CelebrationRepository {
+	return &celebrationRepository{db: db}
+}
+
+// Issue records the reward together with its points or coupon in a single
+// transaction. It returns false without issuing anything if the member
+// already received this reward for the year.
+func (r *celebrationRepository) Issue(reward *domain.CelebrationReward, entry *domain.PointsTransaction, coupon *domain.Coupon) (bool, error) {
+	issued := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if coupon != nil {
+			if err := tx.Create(coupon).Error; err != nil {
+				return err
+			}
+			reward.CouponID = &coupon.ID
+		}
+		if entry != nil {
+			reward.Points = entry.Points
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reward)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Already rewarded this year, so drop the coupon again
+			return errAlreadyIssued
+		}
+
+		if entry != nil {
+			if _, _, err := recordPoints(tx, entry); err != nil {
+				return err
+			}
+		}
+		issued = true
+		return nil
+	})
+	if errors.Is(err, errAlreadyIssued) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return issued, nil
+}
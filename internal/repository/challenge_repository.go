@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChallengeRepository interface {
+	Create(challenge *domain.Challenge) error
+	FindByID(id string) (*domain.Challenge, error)
+	Update(challenge *domain.Challenge) error
+	Delete(id string) error
+	List() ([]*domain.Challenge, error)
+	ListActive(now time.Time) ([]*domain.Challenge, error)
+	ListProgressByUser(userID string) ([]*domain.ChallengeProgress, error)
+	Advance(challenge *domain.Challenge, userID uuid.UUID, eventKey string, advance func(progress *domain.ChallengeProgress)) (*domain.ChallengeProgress, error)
+	CreateBadge(badge *domain.Badge) error
+	ListBadges() ([]*domain.Badge, error)
+	ListMemberBadges(userID string) ([]*domain.MemberBadge, error)
+}
+
+type challengeRepository struct {
+	db *gorm.DB
+}
+
+func NewChallengeRepository(db *gorm.DB) ChallengeRepository {
+	return &challengeRepository{db: db}
+}
+
+func (r *challengeRepository) Create(challenge *domain.Challenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *challengeRepository) FindByID(id string) (*domain.Challenge, error) {
+	var challenge domain.Challenge
+	err := r.db.Where("id = ?", id).First(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *challengeRepository) Update(challenge *domain.Challenge) error {
+	return r.db.Save(challenge).Error
+}
+
+func (r *challengeRepository) Delete(id string) error {
+	return r.db.Delete(&domain.Challenge{}, "id = ?", id).Error
+}
+
+func (r *challengeRepository) List() ([]*domain.Challenge, error) {
+	var challenges []*domain.Challenge
+	err := r.db.Order("start_date DESC").Find(&challenges).Error
+	if err != nil {
+		return nil, err
+	}
+	return challenges, nil
+}
+
+func (r *challengeRepository) ListActive(now time.Time) ([]*domain.Challenge, error) {
+	var challenges []*domain.Challenge
+	err := r.db.Where("is_active = ? AND start_date <= ? AND end_date >= ?", true, now, now).
+		Order("end_date ASC").
+		Find(&challenges).Error
+	if err != nil {
+		return nil, err
+	}
+	return challenges, nil
+}
+
+func (r *challengeRepository) ListProgressByUser(userID string) ([]*domain.ChallengeProgress, error) {
+	var progress []*domain.ChallengeProgress
+	err := r.db.Where("user_id = ?", userID).Find(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// Advance applies an event to the member's progress on the challenge. Each
+// event key counts once; replays return the progress unchanged. When advance
+// completes the challenge, the badge and points are awarded in the same
+// transaction.
+func (r *challengeRepository) Advance(challenge *domain.Challenge, userID uuid.UUID, eventKey string, advance func(progress *domain.ChallengeProgress)) (*domain.ChallengeProgress, error) {
+	var progress domain.ChallengeProgress
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Make sure the progress row exists, then lock it
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.ChallengeProgress{
+			ChallengeID: challenge.ID,
+			UserID:      userID,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("challenge_id = ? AND user_id = ?", challenge.ID, userID).
+			First(&progress).Error; err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.ChallengeEvent{
+			ChallengeID: challenge.ID,
+			UserID:      userID,
+			EventKey:    eventKey,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 || progress.CompletedAt != nil {
+			return nil
+		}
+
+		advance(&progress)
+		if err := tx.Save(&progress).Error; err != nil {
+			return err
+		}
+		if progress.CompletedAt == nil {
+			return nil
+		}
+
+		if challenge.BadgeID != nil {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.MemberBadge{
+				UserID:      userID,
+				BadgeID:     *challenge.BadgeID,
+				ChallengeID: challenge.ID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if challenge.RewardPoints > 0 {
+			key := "challenge:" + challenge.ID.String() + ":" + userID.String()
+			if _, _, err := recordPoints(tx, &domain.PointsTransaction{
+				UserID:         userID,
+				Type:           domain.PointsEarn,
+				Points:         challenge.RewardPoints,
+				Note:           "challenge completed: " + challenge.Name,
+				IdempotencyKey: &key,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+func (r *challengeRepository) CreateBadge(badge *domain.Badge) error {
+	return r.db.Create(badge).Error
+}
+
+func (r *challengeRepository) ListBadges() ([]*domain.Badge, error) {
+	var badges []*domain.Badge
+	err := r.db.Order("name ASC").Find(&badges).Error
+	if err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
+
+func (r *challengeRepository) ListMemberBadges(userID string) ([]*domain.MemberBadge, error) {
+	var badges []*domain.MemberBadge
+	err := r.db.Preload("Badge").Where("user_id = ?", userID).Order("created_at DESC").Find(&badges).Error
+	if err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ConversionRateRepository interface {
+	Create(rate *domain.CouponConversionRate) error
+	FindByID(id string) (*domain.CouponConversionRate, error)
+	Update(rate *domain.CouponConversionRate) error
+	Delete(id string) error
+	ListActive() ([]*domain.CouponConversionRate, error)
+}
+
+type conversionRateRepository struct {
+	db *gorm.DB
+}
+
+func NewConversionRateRepository(db *gorm.DB) ConversionRateRepository {
+	return &conversionRateRepository{db: db}
+}
+
+func (r *conversionRateRepository) Create(rate *domain.CouponConversionRate) error {
+	return r.db.Create(rate).Error
+}
+
+func (r *conversionRateRepository) FindByID(id string) (*domain.CouponConversionRate, error) {
+	var rate domain.CouponConversionRate
+	err := r.db.Where("id = ?", id).First(&rate).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *conversionRateRepository) Update(rate *domain.CouponConversionRate) error {
+	return r.db.Save(rate).Error
+}
+
+func (r *conversionRateRepository) Delete(id string) error {
+	return r.db.Delete(&domain.CouponConversionRate{}, "id = ?", id).Error
+}
+
+func (r *conversionRateRepository) ListActive() ([]*domain.CouponConversionRate, error) {
+	var rates []*domain.CouponConversionRate
+	err := r.db.Where("is_active = ?", true).Order("points ASC").Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
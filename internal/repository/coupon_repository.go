@@ -13,6 +13,8 @@ type CouponRepository interface {
 	Delete(id string) error
 	ListActive() ([]*domain.Coupon, error)
 	IncrementUsageCount(id string) error
+	ListByUser(userID string) ([]*domain.Coupon, error)
+	WithTx(tx Tx) CouponRepository
 }
 
 type couponRepository struct {
@@ -23,6 +25,11 @@ func NewCouponRepository(db *gorm.DB) CouponRepository {
 	return &couponRepository{db: db}
 }
 
+// WithTx returns a repository that runs its queries in tx.
+func (r *couponRepository) WithTx(tx Tx) CouponRepository {
+	return &couponRepository{db: tx.db}
+}
+
 func (r *couponRepository) Create(coupon *domain.Coupon) error {
 	return r.db.Create(coupon).Error
 }
@@ -53,9 +60,11 @@ func (r *couponRepository) Delete(id string) error {
 	return r.db.Delete(&domain.Coupon{}, "id = ?", id).Error
 }
 
+// ListActive returns the live public coupons. Coupons issued to a single
+// member are private and only listed by ListByUser.
 func (r *couponRepository) ListActive() ([]*domain.Coupon, error) {
 	var coupons []*domain.Coupon
-	err := r.db.Where("is_active = ? AND end_date > NOW()", true).Find(&coupons).Error
+	err := r.db.Where("is_active = ? AND approval_status = ? AND end_date > NOW() AND user_id IS NULL", true, domain.ApprovalApproved).Find(&coupons).Error
 	if err != nil {
 		return nil, err
 	}
@@ -66,3 +75,13 @@ func (r *couponRepository) IncrementUsageCount(id string) error {
 	return r.db.Model(&domain.Coupon{}).Where("id = ?", id).
 		UpdateColumn("used_count", gorm.Expr("used_count + ?", 1)).Error
 }
+
+// ListByUser returns the coupons issued to a member, newest first.
+func (r *couponRepository) ListByUser(userID string) ([]*domain.Coupon, error) {
+	var coupons []*domain.Coupon
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
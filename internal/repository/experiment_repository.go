@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExperimentArmStats sums the applications of one arm of a campaign
+// experiment: the control group, a variant, or the campaign itself when it
+// has no variants. SumSquares is the sum of squared order values, for the
+// variance.
+type ExperimentArmStats struct {
+	Control      bool       `gorm:"column:holdout"`
+	VariantID    *uuid.UUID `gorm:"column:variant_id"`
+	Applications int64      `gorm:"column:applications"`
+	Members      int64      `gorm:"column:members"`
+	Points       int64      `gorm:"column:points"`
+	Revenue      float64    `gorm:"column:revenue"`
+	SumSquares   float64    `gorm:"column:sum_squares"`
+}
+
+// ExperimentArmCount is how many members were assigned to an arm.
+type ExperimentArmCount struct {
+	Control   bool       `gorm:"column:control"`
+	VariantID *uuid.UUID `gorm:"column:variant_id"`
+	Members   int64      `gorm:"column:members"`
+}
+
+type ExperimentRepository interface {
+	CreateVariant(variant *domain.CampaignVariant) error
+	ListVariants(campaignIDs ...uuid.UUID) ([]*domain.CampaignVariant, error)
+	DeleteVariant(campaignID, id string) error
+	Assign(assignment *domain.ExperimentAssignment) (*domain.ExperimentAssignment, error)
+	ListAssignments(userID uuid.UUID, campaignIDs ...uuid.UUID) ([]*domain.ExperimentAssignment, error)
+	ArmStats(campaignID string) ([]*ExperimentArmStats, error)
+	AssignmentCounts(campaignID string) ([]*ExperimentArmCount, error)
+}
+
+type experimentRepository struct {
+	db *gorm.DB
+}
+
+func NewExperimentRepository(db *gorm.DB) ExperimentRepository {
+	return &experimentRepository{db: db}
+}
+
+func (r *experimentRepository) CreateVariant(variant *domain.CampaignVariant) error {
+	return r.db.Create(variant).Error
+}
+
+// ListVariants returns the variants of the given campaigns in the order they
+// were created.
+func (r *experimentRepository) ListVariants(campaignIDs ...uuid.UUID) ([]*domain.CampaignVariant, error) {
+	var variants []*domain.CampaignVariant
+	if len(campaignIDs) == 0 {
+		return variants, nil
+	}
+	err := r.db.Where("campaign_id IN ?", campaignIDs).Order("created_at ASC").Find(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *experimentRepository) DeleteVariant(campaignID, id string) error {
+	return r.db.Delete(&domain.CampaignVariant{}, "id = ? AND campaign_id = ?", id, campaignID).Error
+}
+
+// Assign stores the assignment unless the member already has one for the
+// campaign, and returns the assignment that holds.
+func (r *experimentRepository) Assign(assignment *domain.ExperimentAssignment) (*domain.ExperimentAssignment, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(assignment).Error; err != nil {
+		return nil, err
+	}
+
+	var stored domain.ExperimentAssignment
+	err := r.db.Where("campaign_id = ? AND user_id = ?", assignment.CampaignID, assignment.UserID).First(&stored).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// ListAssignments returns the member's stored assignments for the given
+// campaigns.
+func (r *experimentRepository) ListAssignments(userID uuid.UUID, campaignIDs ...uuid.UUID) ([]*domain.ExperimentAssignment, error) {
+	var assignments []*domain.ExperimentAssignment
+	if len(campaignIDs) == 0 {
+		return assignments, nil
+	}
+	err := r.db.Where("user_id = ? AND campaign_id IN ?", userID, campaignIDs).Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *experimentRepository) ArmStats(campaignID string) ([]*ExperimentArmStats, error) {
+	var stats []*ExperimentArmStats
+	err := r.db.Model(&domain.CampaignApplication{}).
+		Select("holdout, variant_id, COUNT(*) AS applications, COUNT(DISTINCT user_id) AS members, COALESCE(SUM(points), 0) AS points, COALESCE(SUM(purchase_amount), 0) AS revenue, COALESCE(SUM(purchase_amount * purchase_amount), 0) AS sum_squares").
+		Where("campaign_id = ?", campaignID).
+		Group("holdout, variant_id").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *experimentRepository) AssignmentCounts(campaignID string) ([]*ExperimentArmCount, error) {
+	var counts []*ExperimentArmCount
+	err := r.db.Model(&domain.ExperimentAssignment{}).
+		Select("control, variant_id, COUNT(*) AS members").
+		Where("campaign_id = ?", campaignID).
+		Group("control, variant_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,533 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAlreadyInHousehold     = errors.New("member already belongs to a household")
+	ErrInsufficientPoolPoints = errors.New("insufficient household points")
+)
+
+type HouseholdRepository interface {
+	Create(household *domain.Household, owner *domain.HouseholdMember) error
+	FindByID(id string) (*domain.Household, error)
+	FindMember(userID string) (*domain.HouseholdMember, error)
+	ListMembers(householdID string) ([]*domain.HouseholdMember, error)
+	AddMember(member *domain.HouseholdMember) error
+	UpdateMember(member *domain.HouseholdMember) error
+	RemoveMember(member *domain.HouseholdMember) error
+	Contribute(member *domain.HouseholdMember, points int, limits TransferLimits) (int, int, error)
+	Withdraw(member *domain.HouseholdMember, points int) (int, int, error)
+	SumMovedSince(userID, pointsType string, since time.Time) (int, int, error)
+	CountContributorsSince(householdID, excludeUserID string, since time.Time) (int, error)
+	ListLedger(householdID string, offset, limit int) ([]*domain.HouseholdPointsTransaction, int64, error)
+}
+
+type householdRepository struct {
+	db *gorm.DB
+}
+
+func NewHouseholdRepository(db *gorm.DB) HouseholdRepository {
+	return &householdRepository{db: db}
+}
+
+func (r *householdRepository) Create(household *domain.Household, owner *domain.HouseholdMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(household).Error; err != nil {
+			return err
+		}
+		owner.HouseholdID = household.ID
+		return createHouseholdMember(tx, owner)
+	})
+}
+
+func (r *householdRepository) FindByID(id string) (*domain.Household, error) {
+	var household domain.Household
+	if err := r.db.Where("id = ?", id).First(&household).Error; err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+func (r *householdRepository) FindMember(userID string) (*domain.HouseholdMember, error) {
+	var member domain.HouseholdMember
+	if err := r.db.Where("user_id = ?", userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *householdRepository) ListMembers(householdID string) ([]*domain.HouseholdMember, error) {
+	var members []*domain.HouseholdMember
+	err := r.db.Where("household_id = ?", householdID).Order("created_at ASC").Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *householdRepository) AddMember(member *domain.HouseholdMember) error {
+	return createHouseholdMember(r.db, member)
+}
+
+func createHouseholdMember(tx *gorm.DB, member *domain.HouseholdMember) error {
+	var count int64
+	if err := tx.Model(&domain.HouseholdMember{}).Where("user_id = ?", member.UserID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadyInHousehold
+	}
+	return tx.Create(member).Error
+}
+
+func (r *householdRepository) UpdateMember(member *domain.HouseholdMember) error {
+	return r.db.Save(member).Error
+}
+
+func (r *householdRepository) RemoveMember(member *domain.HouseholdMember) error {
+	return r.db.Delete(member).Error
+}
+
+// Contribute moves points from the member's balance into the household pool.
+// The member's transfer limits are checked under the lock, so concurrent
+// contributions and transfers cannot all pass. It returns the member's and
+// the pool's new balances.
+func (r *householdRepository) Contribute(member *domain.HouseholdMember, points int, limits TransferLimits) (int, int, error) {
+	var balance, pool int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", member.UserID).First(&user).Error; err != nil {
+			return err
+		}
+		if user.Points < points {
+			return ErrInsufficientPoints
+		}
+		if err := checkGivingLimits(tx, user.ID, points, limits); err != nil {
+			return err
+		}
+
+		var err error
+		balance, pool, err = contributeToHousehold(tx, &user, member.HouseholdID, domain.PointsTransferIn, nil, points)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return balance, pool, nil
+}
+
+// Withdraw moves points from the household pool to the member's balance,
+// where they can be redeemed like any other points. The member takes over
+// the pool's lots with their original expiry dates. It returns the member's
+// and the pool's new balances.
+func (r *householdRepository) Withdraw(member *domain.HouseholdMember, points int) (int, int, error) {
+	var balance, pool int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the member before the household, as contributions do, so
+		// concurrent movements cannot deadlock
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", member.UserID).First(&user).Error; err != nil {
+			return err
+		}
+		var household domain.Household
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", member.HouseholdID).First(&household).Error; err != nil {
+			return err
+		}
+		if household.Points < points {
+			return ErrInsufficientPoolPoints
+		}
+
+		var err error
+		balance, pool, err = withdrawFromHousehold(tx, &user, &household, domain.PointsTransferOut, nil, points, "household withdrawal")
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return balance, pool, nil
+}
+
+// SumMovedSince returns the points and number of movements of the given type
+// between the member and their household since the given time: transfer_in
+// for contributions, transfer_out for withdrawals. Earnings swept into the
+// pool are not counted.
+func (r *householdRepository) SumMovedSince(userID, pointsType string, since time.Time) (int, int, error) {
+	var result struct {
+		Points int
+		Count  int
+	}
+	err := r.db.Model(&domain.HouseholdPointsTransaction{}).
+		Where("user_id = ? AND type = ? AND created_at >= ?", userID, pointsType, since).
+		Select("COALESCE(SUM(ABS(points)), 0) AS points, COUNT(*) AS count").
+		Scan(&result).Error
+	return result.Points, result.Count, err
+}
+
+// CountContributorsSince returns how many distinct members other than the
+// given one contributed to the household since the given time.
+func (r *householdRepository) CountContributorsSince(householdID, excludeUserID string, since time.Time) (int, error) {
+	var count int64
+	err := r.db.Model(&domain.HouseholdPointsTransaction{}).
+		Where("household_id = ? AND type = ? AND created_at >= ?", householdID, domain.PointsTransferIn, since).
+		Where("user_id <> ?", excludeUserID).
+		Distinct("user_id").
+		Count(&count).Error
+	return int(count), err
+}
+
+func (r *householdRepository) ListLedger(householdID string, offset, limit int) ([]*domain.HouseholdPointsTransaction, int64, error) {
+	query := r.db.Model(&domain.HouseholdPointsTransaction{}).Where("household_id = ?", householdID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*domain.HouseholdPointsTransaction
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// sweepToHousehold moves freshly earned points into the pool of the member's
+// household if they contribute to one, and returns the member's balance
+// afterwards. The caller holds the lock on the member row.
+func sweepToHousehold(tx *gorm.DB, user *domain.User, entry *domain.PointsTransaction, balance int) (int, error) {
+	var member domain.HouseholdMember
+	err := tx.Where("user_id = ? AND status = ? AND contributes = ?", user.ID, domain.HouseholdActive, true).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return balance, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Never take the member below zero, e.g. when the points paid off a debt
+	points := entry.Points
+	if points > balance {
+		points = balance
+	}
+	if points <= 0 {
+		return balance, nil
+	}
+
+	user.Points = balance
+	balance, _, err = contributeToHousehold(tx, user, member.HouseholdID, domain.PointsEarn, &entry.ID, points)
+	return balance, err
+}
+
+// contributeToHousehold records both legs of a movement from the member to
+// the household pool within tx, recording the pool side as poolType and,
+// for swept earnings, the earn entry as sourceID. The pool takes over the
+// member's lots with their original expiry dates. The caller holds the lock
+// on the member row and has checked the member holds the points.
+func contributeToHousehold(tx *gorm.DB, user *domain.User, householdID uuid.UUID, poolType string, sourceID *uuid.UUID, points int) (int, int, error) {
+	var household domain.Household
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", householdID).First(&household).Error; err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+	pairID := uuid.New()
+	balance := user.Points - points
+	if err := tx.Create(&domain.PointsTransaction{
+		UserID:       user.ID,
+		Type:         domain.PointsTransferOut,
+		Points:       -points,
+		BalanceAfter: balance,
+		PairID:       &pairID,
+		Note:         "household contribution",
+		CreatedAt:    now,
+	}).Error; err != nil {
+		return 0, 0, err
+	}
+	slices, err := consumeLots(tx, user.ID.String(), points, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Model(&domain.User{}).Where("id = ?", user.ID).
+		UpdateColumn("points", balance).Error; err != nil {
+		return 0, 0, err
+	}
+
+	pool := household.Points + points
+	in := &domain.HouseholdPointsTransaction{
+		HouseholdID:  household.ID,
+		UserID:       user.ID,
+		Type:         poolType,
+		Points:       points,
+		BalanceAfter: pool,
+		PairID:       pairID,
+		SourceID:     sourceID,
+		CreatedAt:    now,
+	}
+	if err := tx.Create(in).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := poolLots(tx, user, in, slices); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Model(&household).UpdateColumn("points", pool).Error; err != nil {
+		return 0, 0, err
+	}
+
+	user.Points = balance
+	return balance, pool, nil
+}
+
+// withdrawFromHousehold records both legs of a movement from the household
+// pool to the member within tx, recording the pool side as poolType with
+// sourceID. The member takes over the pool's lots with their original expiry
+// dates. The caller holds the locks on the member and then the household row
+// and has checked the pool holds the points. It returns the member's and the
+// pool's new balances.
+func withdrawFromHousehold(tx *gorm.DB, user *domain.User, household *domain.Household, poolType string, sourceID *uuid.UUID, points int, note string) (int, int, error) {
+	now := time.Now()
+	pairID := uuid.New()
+	pool := household.Points - points
+	if err := tx.Create(&domain.HouseholdPointsTransaction{
+		HouseholdID:  household.ID,
+		UserID:       user.ID,
+		Type:         poolType,
+		Points:       -points,
+		BalanceAfter: pool,
+		PairID:       pairID,
+		SourceID:     sourceID,
+		CreatedAt:    now,
+	}).Error; err != nil {
+		return 0, 0, err
+	}
+	slices, err := consumeHouseholdLots(tx, household.ID, points)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Model(household).UpdateColumn("points", pool).Error; err != nil {
+		return 0, 0, err
+	}
+	household.Points = pool
+
+	balance := user.Points + points
+	in := &domain.PointsTransaction{
+		UserID:       user.ID,
+		Type:         domain.PointsTransferIn,
+		Points:       points,
+		BalanceAfter: balance,
+		PairID:       &pairID,
+		Note:         note,
+		CreatedAt:    now,
+	}
+	if err := tx.Create(in).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := takeOverLots(tx, user, in, slices); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Model(&domain.User{}).Where("id = ?", user.ID).
+		UpdateColumn("points", balance).Error; err != nil {
+		return 0, 0, err
+	}
+
+	user.Points = balance
+	return balance, pool, nil
+}
+
+// returnSweptPoints takes back from the household pool what was swept there
+// from the earn entries the reversals claw back, as far as the pool still
+// holds it, so a refund debits the pool before the member. The caller holds
+// the lock on the member row. It returns the member's balance afterwards.
+func returnSweptPoints(tx *gorm.DB, user *domain.User, reversals []*domain.PointsTransaction) (int, error) {
+	for _, reversal := range reversals {
+		if reversal.ReversesID == nil || reversal.Points >= 0 {
+			continue
+		}
+
+		// Earn legs are positive and earlier returns negative
+		var legs []*domain.HouseholdPointsTransaction
+		if err := tx.Where("source_id = ?", *reversal.ReversesID).Find(&legs).Error; err != nil {
+			return 0, err
+		}
+		if len(legs) == 0 {
+			continue
+		}
+		swept := 0
+		for _, leg := range legs {
+			swept += leg.Points
+		}
+		points := -reversal.Points
+		if points > swept {
+			points = swept
+		}
+
+		var household domain.Household
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", legs[0].HouseholdID).First(&household).Error; err != nil {
+			return 0, err
+		}
+		if points > household.Points {
+			points = household.Points
+		}
+		if points <= 0 {
+			continue
+		}
+
+		if _, _, err := withdrawFromHousehold(tx, user, &household, domain.PointsReverse, reversal.ReversesID, points, "household refund"); err != nil {
+			return 0, err
+		}
+	}
+	return user.Points, nil
+}
+
+// poolLots opens pool lots for a contribution from the slices taken off the
+// member's lots. Points the slices do not cover, from balances that predate
+// lot tracking, open a lot under the member's club policy.
+func poolLots(tx *gorm.DB, user *domain.User, in *domain.HouseholdPointsTransaction, slices []lotSlice) error {
+	unbacked := in.Points
+	for _, slice := range slices {
+		unbacked -= slice.Points
+		if err := tx.Create(&domain.HouseholdPointsLot{
+			HouseholdID:   in.HouseholdID,
+			UserID:        user.ID,
+			TransactionID: in.ID,
+			Points:        slice.Points,
+			Remaining:     slice.Points,
+			EarnedAt:      slice.EarnedAt,
+			ExpiresAt:     slice.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if unbacked <= 0 {
+		return nil
+	}
+
+	expiresAt, err := lotExpiry(tx, user.Club, in.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&domain.HouseholdPointsLot{
+		HouseholdID:   in.HouseholdID,
+		UserID:        user.ID,
+		TransactionID: in.ID,
+		Points:        unbacked,
+		Remaining:     unbacked,
+		EarnedAt:      in.CreatedAt,
+		ExpiresAt:     expiresAt,
+	}).Error
+}
+
+// consumeHouseholdLots takes points from the pool's lots, oldest first, and
+// returns what it took. Pools that predate lot tracking may not be fully
+// covered, in which case the remainder is simply not backed by a lot.
+func consumeHouseholdLots(tx *gorm.DB, householdID uuid.UUID, points int) ([]lotSlice, error) {
+	var lots []*domain.HouseholdPointsLot
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("household_id = ? AND remaining > 0", householdID).
+		Order("earned_at ASC, id ASC").
+		Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var taken []lotSlice
+	for _, lot := range lots {
+		if points == 0 {
+			break
+		}
+		take := lot.Remaining
+		if take > points {
+			take = points
+		}
+		if err := tx.Model(lot).UpdateColumn("remaining", lot.Remaining-take).Error; err != nil {
+			return nil, err
+		}
+		taken = append(taken, lotSlice{Points: take, EarnedAt: lot.EarnedAt, ExpiresAt: lot.ExpiresAt})
+		points -= take
+	}
+	return taken, nil
+}
+
+// expireHouseholdLots writes off every pool lot that lapsed before now,
+// recording one expire entry per lot on the household ledger, and returns
+// the number of points expired.
+func expireHouseholdLots(db *gorm.DB, now time.Time) (int, error) {
+	var householdIDs []string
+	err := db.Model(&domain.HouseholdPointsLot{}).
+		Where("remaining > 0 AND expires_at <= ?", now).
+		Distinct("household_id").
+		Pluck("household_id", &householdIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, householdID := range householdIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var household domain.Household
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", householdID).First(&household).Error; err != nil {
+				return err
+			}
+
+			var lots []*domain.HouseholdPointsLot
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("household_id = ? AND remaining > 0 AND expires_at <= ?", householdID, now).
+				Order("earned_at ASC, id ASC").
+				Find(&lots).Error; err != nil {
+				return err
+			}
+
+			pool := household.Points
+			for _, lot := range lots {
+				// Never expire more than the pool still holds
+				points := lot.Remaining
+				if points > pool {
+					points = pool
+				}
+				if points > 0 {
+					pool -= points
+					if err := tx.Create(&domain.HouseholdPointsTransaction{
+						HouseholdID:  household.ID,
+						UserID:       lot.UserID,
+						Type:         domain.PointsExpire,
+						Points:       -points,
+						BalanceAfter: pool,
+						PairID:       lot.ID,
+						CreatedAt:    now,
+					}).Error; err != nil {
+						return err
+					}
+					expired += points
+				}
+				if err := tx.Model(lot).UpdateColumn("remaining", 0).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Model(&household).UpdateColumn("points", pool).Error
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
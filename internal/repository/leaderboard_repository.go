@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaderboardKey identifies one leaderboard within a period.
+type LeaderboardKey struct {
+	Scope    string
+	ScopeKey string
+}
+
+type LeaderboardRepository interface {
+	Top(scope, key, period string, limit int) ([]*domain.LeaderboardEntry, error)
+	Rank(scope, key, period, userID string) (int, int, error)
+	ChallengeTop(challengeID string, limit int) ([]*domain.LeaderboardEntry, error)
+	ChallengeRank(challengeID, userID string) (int, int, error)
+	ListKeys(period string) ([]LeaderboardKey, error)
+	SnapshotExists(scope, key, period string) (bool, error)
+	CreateSnapshot(snapshot *domain.LeaderboardSnapshot) error
+	FindSnapshot(scope, key, period string) (*domain.LeaderboardSnapshot, error)
+}
+
+type leaderboardRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaderboardRepository(db *gorm.DB) LeaderboardRepository {
+	return &leaderboardRepository{db: db}
+}
+
+// scores returns the visible scores of one leaderboard. Members who opted
+// out are left out of the ranking altogether.
+func (r *leaderboardRepository) scores(scope, key, period string) *gorm.DB {
+	return r.db.Table("leaderboard_scores AS s").
+		Joins("JOIN users AS u ON u.id = s.user_id AND u.deleted_at IS NULL").
+		Where("s.scope = ? AND s.scope_key = ? AND s.period = ?", scope, key, period).
+		Where("u.leaderboard_opt_out = ? AND s.points > 0", false)
+}
+
+func (r *leaderboardRepository) progress(challengeID string) *gorm.DB {
+	return r.db.Table("challenge_progresses AS s").
+		Joins("JOIN users AS u ON u.id = s.user_id AND u.deleted_at IS NULL").
+		Where("s.challenge_id = ?", challengeID).
+		Where("u.leaderboard_opt_out = ? AND s.progress > 0", false)
+}
+
+func (r *leaderboardRepository) Top(scope, key, period string, limit int) ([]*domain.LeaderboardEntry, error) {
+	return topEntries(r.scores(scope, key, period), "s.points", limit)
+}
+
+func (r *leaderboardRepository) Rank(scope, key, period, userID string) (int, int, error) {
+	return memberRank(r.scores(scope, key, period), r.scores(scope, key, period), "s.points", userID)
+}
+
+func (r *leaderboardRepository) ChallengeTop(challengeID string, limit int) ([]*domain.LeaderboardEntry, error) {
+	return topEntries(r.progress(challengeID), "s.progress", limit)
+}
+
+func (r *leaderboardRepository) ChallengeRank(challengeID, userID string) (int, int, error) {
+	return memberRank(r.progress(challengeID), r.progress(challengeID), "s.progress", userID)
+}
+
+// topEntries reads the first limit rows of a ranking. Members with the same
+// points share a rank.
+func topEntries(query *gorm.DB, column string, limit int) ([]*domain.LeaderboardEntry, error) {
+	var entries []*domain.LeaderboardEntry
+	err := query.Select("s.user_id, u.name, " + column + " AS points").
+		Order(column + " DESC, s.user_id ASC").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range entries {
+		entry.Rank = i + 1
+		if i > 0 && entry.Points == entries[i-1].Points {
+			entry.Rank = entries[i-1].Rank
+		}
+	}
+	return entries, nil
+}
+
+// memberRank returns the member's rank and points, counting only the members
+// ahead of them. The rank is 0 if the member is not on the leaderboard.
+func memberRank(own, ahead *gorm.DB, column, userID string) (int, int, error) {
+	var points int
+	err := own.Where("s.user_id = ?", userID).Select(column).Limit(1).Scan(&points).Error
+	if err != nil || points == 0 {
+		return 0, 0, err
+	}
+
+	var count int64
+	if err := ahead.Where(column+" > ?", points).Count(&count).Error; err != nil {
+		return 0, 0, err
+	}
+	return int(count) + 1, points, nil
+}
+
+// ListKeys returns every leaderboard with scores in the period.
+func (r *leaderboardRepository) ListKeys(period string) ([]LeaderboardKey, error) {
+	var keys []LeaderboardKey
+	err := r.db.Model(&domain.LeaderboardScore{}).
+		Where("period = ?", period).
+		Distinct("scope", "scope_key").
+		Scan(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *leaderboardRepository) SnapshotExists(scope, key, period string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.LeaderboardSnapshot{}).
+		Where("scope = ? AND scope_key = ? AND period = ?", scope, key, period).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *leaderboardRepository) CreateSnapshot(snapshot *domain.LeaderboardSnapshot) error {
+	return r.db.Create(snapshot).Error
+}
+
+func (r *leaderboardRepository) FindSnapshot(scope, key, period string) (*domain.LeaderboardSnapshot, error) {
+	var snapshot domain.LeaderboardSnapshot
+	err := r.db.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("rank ASC, points DESC")
+	}).Where("scope = ? AND scope_key = ? AND period = ?", scope, key, period).First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// LeaderboardPeriods returns the monthly and weekly periods t falls in, e.g.
+// "2024-06" and "2024-W23".
+func LeaderboardPeriods(t time.Time) (string, string) {
+	t = t.UTC()
+	year, week := t.ISOWeek()
+	return t.Format("2006-01"), fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// bumpLeaderboards adds the points of a ledger entry to the member's scores.
+// Only earnings and refund clawbacks count, so leaderboards rank points
+// earned rather than balances.
+func bumpLeaderboards(tx *gorm.DB, user *domain.User, entry *domain.PointsTransaction) error {
+	if !(entry.Type == domain.PointsEarn && entry.Points > 0) && !(entry.Type == domain.PointsReverse && entry.Points < 0) {
+		return nil
+	}
+
+	keys := []LeaderboardKey{{Scope: domain.LeaderboardGlobal}}
+	if user.Club != "" {
+		keys = append(keys, LeaderboardKey{Scope: domain.LeaderboardClub, ScopeKey: user.Club})
+	}
+	if entry.Store != "" {
+		keys = append(keys, LeaderboardKey{Scope: domain.LeaderboardStore, ScopeKey: entry.Store})
+	}
+
+	month, week := LeaderboardPeriods(entry.CreatedAt)
+	for _, key := range keys {
+		for _, period := range []string{month, week} {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "scope"}, {Name: "scope_key"}, {Name: "period"}, {Name: "user_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"points": gorm.Expr("leaderboard_scores.points + ?", entry.Points),
+				}),
+			}).Create(&domain.LeaderboardScore{
+				Scope:    key.Scope,
+				ScopeKey: key.ScopeKey,
+				Period:   period,
+				UserID:   user.ID,
+				Points:   entry.Points,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientPoints = errors.New("insufficient points")
+
+type PointsRepository interface {
+	Record(entry *domain.PointsTransaction) (*domain.PointsTransaction, int, error)
+	WithTx(tx Tx) PointsRepository
+	ListByUser(userID string, offset, limit int) ([]*domain.PointsTransaction, int64, error)
+	ListByOrder(userID, orderRef string) ([]*domain.PointsTransaction, error)
+	LedgerBalance(userID string) (int, error)
+	SumEarned(userID string, since time.Time) (int, error)
+	Reconcile(userID string) (int, int, error)
+	ExpireLots(now time.Time) (int, error)
+	ListExpiringLots(userID string, before time.Time) ([]*domain.PointsLot, error)
+	ListExpiryPolicies() ([]*domain.PointsExpiryPolicy, error)
+	SaveExpiryPolicy(policy *domain.PointsExpiryPolicy) error
+}
+
+type pointsRepository struct {
+	db *gorm.DB
+}
+
+func NewPointsRepository(db *gorm.DB) PointsRepository {
+	return &pointsRepository{db: db}
+}
+
+// WithTx returns a repository that runs its queries in tx.
+func (r *pointsRepository) WithTx(tx Tx) PointsRepository {
+	return &pointsRepository{db: tx.db}
+}
+
+// Record appends entry to the ledger and applies its points to the user's
+// cached balance in a single transaction. See recordPoints.
+func (r *pointsRepository) Record(entry *domain.PointsTransaction) (*domain.PointsTransaction, int, error) {
+	var recorded *domain.PointsTransaction
+	var balance int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		recorded, balance, err = recordPoints(tx, entry)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return recorded, balance, nil
+}
+
+// recordPoints appends entry to the ledger within tx and applies its points
+// to the user's cached balance. Credits open a new lot that expires according
+// to the member's club policy, debits consume the oldest lots first. Debits
+// that would take the balance below zero fail with ErrInsufficientPoints. If
+// an entry with the same idempotency key already exists, the existing entry
+// is returned and the balance is left untouched.
+//
+// Other repositories use it to move points atomically with their own writes.
+func recordPoints(tx *gorm.DB, entry *domain.PointsTransaction) (*domain.PointsTransaction, int, error) {
+	return applyPoints(tx, entry, false)
+}
+
+// recordPointsAllowingDebt is recordPoints for debits that may leave the
+// member with a negative balance.
+func recordPointsAllowingDebt(tx *gorm.DB, entry *domain.PointsTransaction) (*domain.PointsTransaction, int, error) {
+	return applyPoints(tx, entry, true)
+}
+
+// recordPointsWithLots is recordPoints for movements that keep the dates of
+// the points they move. Debits return the lot slices they consumed, and
+// credits take over slices instead of opening a lot under the club policy.
+func recordPointsWithLots(tx *gorm.DB, entry *domain.PointsTransaction, slices []lotSlice) (*domain.PointsTransaction, int, []lotSlice, error) {
+	return applyPointsWithLots(tx, entry, false, slices)
+}
+
+func applyPoints(tx *gorm.DB, entry *domain.PointsTransaction, allowDebt bool) (*domain.PointsTransaction, int, error) {
+	entry, balance, _, err := applyPointsWithLots(tx, entry, allowDebt, nil)
+	return entry, balance, err
+}
+
+func applyPointsWithLots(tx *gorm.DB, entry *domain.PointsTransaction, allowDebt bool, slices []lotSlice) (*domain.PointsTransaction, int, []lotSlice, error) {
+	// Lock the member row so concurrent movements are serialized
+	var user domain.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", entry.UserID).First(&user).Error; err != nil {
+		return nil, 0, nil, err
+	}
+
+	if entry.IdempotencyKey != nil {
+		var existing domain.PointsTransaction
+		err := tx.Where("idempotency_key = ?", *entry.IdempotencyKey).First(&existing).Error
+		if err == nil {
+			return &existing, user.Points, nil, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, nil, err
+		}
+	}
+
+	balance := user.Points + entry.Points
+	if entry.Points < 0 && balance < 0 && !allowDebt {
+		return nil, 0, nil, ErrInsufficientPoints
+	}
+
+	entry.BalanceAfter = balance
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, 0, nil, err
+	}
+
+	var taken []lotSlice
+	if entry.Points > 0 && balance > 0 && len(slices) > 0 {
+		// Points coming back keep the dates they had when they left
+		if err := takeOverLots(tx, &user, entry, slices); err != nil {
+			return nil, 0, nil, err
+		}
+	} else if entry.Points > 0 && balance > 0 {
+		// Credits that pay off a debt first are only partly backed by a lot
+		lotPoints := entry.Points
+		if lotPoints > balance {
+			lotPoints = balance
+		}
+		if err := openLot(tx, &user, entry, lotPoints); err != nil {
+			return nil, 0, nil, err
+		}
+	} else if entry.Points < 0 {
+		var err error
+		if taken, err = consumeLots(tx, user.ID.String(), -entry.Points, entry.ReversesID); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	err := tx.Model(&domain.User{}).Where("id = ?", user.ID).
+		UpdateColumn("points", balance).Error
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if err := bumpLeaderboards(tx, &user, entry); err != nil {
+		return nil, 0, nil, err
+	}
+
+	if entry.Type == domain.PointsEarn && entry.Points > 0 {
+		if balance, err = sweepToHousehold(tx, &user, entry, balance); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	return entry, balance, taken, nil
+}
+
+func openLot(tx *gorm.DB, user *domain.User, entry *domain.PointsTransaction, points int) error {
+	expiresAt, err := lotExpiry(tx, user.Club, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&domain.PointsLot{
+		UserID:        user.ID,
+		TransactionID: entry.ID,
+		Points:        points,
+		Remaining:     points,
+		EarnedAt:      entry.CreatedAt,
+		ExpiresAt:     expiresAt,
+	}).Error
+}
+
+// lotExpiry returns when points a member of the club earns at earnedAt
+// expire, or nil if the club's points never expire.
+func lotExpiry(tx *gorm.DB, club string, earnedAt time.Time) (*time.Time, error) {
+	months := domain.DefaultPointsExpiryMonths
+	var policy domain.PointsExpiryPolicy
+	err := tx.Where("club = ?", club).First(&policy).Error
+	if err == nil {
+		months = policy.ExpiryMonths
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if months <= 0 {
+		return nil, nil
+	}
+	expiresAt := earnedAt.AddDate(0, months, 0)
+	return &expiresAt, nil
+}
+
+// lotSlice is the part of a lot taken by a debit.
+type lotSlice struct {
+	Points    int
+	EarnedAt  time.Time
+	ExpiresAt *time.Time
+}
+
+// consumeLots takes points from the member's lots, oldest first, and returns
+// what it took. Reversals take from the lot of the entry they reverse before
+// any other. Balances that predate lot tracking may not be fully covered, in
+// which case the remainder is simply not backed by a lot.
+func consumeLots(tx *gorm.DB, userID string, points int, reverses *uuid.UUID) ([]lotSlice, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining > 0", userID)
+	if reverses != nil {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "transaction_id = ? DESC",
+			Vars:               []interface{}{*reverses},
+			WithoutParentheses: true,
+		}})
+	}
+
+	var lots []*domain.PointsLot
+	err := query.Order("earned_at ASC, id ASC").Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var taken []lotSlice
+	for _, lot := range lots {
+		if points == 0 {
+			break
+		}
+		take := lot.Remaining
+		if take > points {
+			take = points
+		}
+		if err := tx.Model(lot).UpdateColumn("remaining", lot.Remaining-take).Error; err != nil {
+			return nil, err
+		}
+		taken = append(taken, lotSlice{Points: take, EarnedAt: lot.EarnedAt, ExpiresAt: lot.ExpiresAt})
+		points -= take
+	}
+	return taken, nil
+}
+
+func (r *pointsRepository) ListByUser(userID string, offset, limit int) ([]*domain.PointsTransaction, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.PointsTransaction{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*domain.PointsTransaction
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// ListByOrder returns a member's ledger entries for an order. Campaign
+// applications carry order refs from the caller, so other members' entries
+// can share the ref.
+func (r *pointsRepository) ListByOrder(userID, orderRef string) ([]*domain.PointsTransaction, error) {
+	var entries []*domain.PointsTransaction
+	err := r.db.Where("user_id = ? AND order_ref = ?", userID, orderRef).Order("created_at ASC, id ASC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *pointsRepository) LedgerBalance(userID string) (int, error) {
+	var balance int
+	err := r.db.Model(&domain.PointsTransaction{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// SumEarned returns the points earned since the given time, net of any
+// refund clawbacks. Points given back from cancelled redemptions are not
+// earnings.
+func (r *pointsRepository) SumEarned(userID string, since time.Time) (int, error) {
+	var earned int
+	err := r.db.Model(&domain.PointsTransaction{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Where("type = ? OR (type = ? AND points < 0)", domain.PointsEarn, domain.PointsReverse).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&earned).Error
+	return earned, err
+}
+
+// Reconcile brings the cached balance on the user in line with the ledger
+// and returns the previously cached balance and the ledger balance. Members
+// whose balance predates the ledger get an opening entry instead, so their
+// points are not lost.
+func (r *pointsRepository) Reconcile(userID string) (int, int, error) {
+	var cached, ledger int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		cached = user.Points
+
+		var count int64
+		if err := tx.Model(&domain.PointsTransaction{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 && cached != 0 {
+			ledger = cached
+			opening := &domain.PointsTransaction{
+				UserID:       user.ID,
+				Type:         domain.PointsAdjust,
+				Points:       cached,
+				BalanceAfter: cached,
+				ReasonCode:   "migration",
+				Note:         "opening balance",
+				CreatedAt:    time.Now(),
+			}
+			if err := tx.Create(opening).Error; err != nil {
+				return err
+			}
+			if cached > 0 {
+				return openLot(tx, &user, opening, cached)
+			}
+			return nil
+		}
+
+		if err := tx.Model(&domain.PointsTransaction{}).
+			Where("user_id = ?", userID).
+			Select("COALESCE(SUM(points), 0)").
+			Scan(&ledger).Error; err != nil {
+			return err
+		}
+
+		if ledger == cached {
+			return nil
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", userID).
+			UpdateColumn("points", ledger).Error
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return cached, ledger, nil
+}
+
+// ExpireLots writes off every lot that lapsed before now, in member balances
+// and household pools, recording one expire entry per lot, and returns the
+// number of points expired. Each lot is keyed so rerunning the job never
+// expires it twice.
+func (r *pointsRepository) ExpireLots(now time.Time) (int, error) {
+	var userIDs []string
+	err := r.db.Model(&domain.PointsLot{}).
+		Where("remaining > 0 AND expires_at <= ?", now).
+		Distinct("user_id").
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, userID := range userIDs {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			var user domain.User
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", userID).First(&user).Error; err != nil {
+				return err
+			}
+
+			var lots []*domain.PointsLot
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ? AND remaining > 0 AND expires_at <= ?", userID, now).
+				Order("earned_at ASC, id ASC").
+				Find(&lots).Error; err != nil {
+				return err
+			}
+
+			balance := user.Points
+			for _, lot := range lots {
+				// Never expire more than the member still holds
+				points := lot.Remaining
+				if points > balance {
+					points = balance
+				}
+				if points > 0 {
+					balance -= points
+					key := "expire:lot:" + lot.ID.String()
+					if err := tx.Create(&domain.PointsTransaction{
+						UserID:         user.ID,
+						Type:           domain.PointsExpire,
+						Points:         -points,
+						BalanceAfter:   balance,
+						IdempotencyKey: &key,
+					}).Error; err != nil {
+						return err
+					}
+					expired += points
+				}
+				if err := tx.Model(lot).UpdateColumn("remaining", 0).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Model(&domain.User{}).Where("id = ?", user.ID).
+				UpdateColumn("points", balance).Error
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	pooled, err := expireHouseholdLots(r.db, now)
+	return expired + pooled, err
+}
+
+func (r *pointsRepository) ListExpiringLots(userID string, before time.Time) ([]*domain.PointsLot, error) {
+	var lots []*domain.PointsLot
+	err := r.db.Where("user_id = ? AND remaining > 0 AND expires_at <= ?", userID, before).
+		Order("expires_at ASC").
+		Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+func (r *pointsRepository) ListExpiryPolicies() ([]*domain.PointsExpiryPolicy, error) {
+	var policies []*domain.PointsExpiryPolicy
+	err := r.db.Order("club ASC").Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *pointsRepository) SaveExpiryPolicy(policy *domain.PointsExpiryPolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "club"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expiry_months", "updated_at"}),
+	}).Create(policy).Error
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDuplicateOrder = errors.New("order has already been recorded")
+
+type PurchaseRepository interface {
+	Create(purchase *domain.Purchase, entries []*domain.PointsTransaction, applications []*domain.CampaignApplication) (int, []uuid.UUID, error)
+	FindByOrderID(orderID string) (*domain.Purchase, error)
+	ListBetween(from, to time.Time, offset, limit int) ([]*domain.Purchase, error)
+}
+
+type purchaseRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseRepository(db *gorm.DB) PurchaseRepository {
+	return &purchaseRepository{db: db}
+}
+
+// Create stores the purchase, records the points it earned and the campaign
+// application facts in a single transaction and returns the member's new
+// balance. Campaign per-member caps are enforced under the member's lock:
+// capped points are cut down in the applications, entries and the purchase,
+// and the campaigns a cap withholds entirely are returned. It fails with
+// ErrDuplicateOrder if the order ID was already recorded.
+func (r *purchaseRepository) Create(purchase *domain.Purchase, entries []*domain.PointsTransaction, applications []*domain.CampaignApplication) (int, []uuid.UUID, error) {
+	var balance int
+	var withheld []uuid.UUID
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&domain.Purchase{}).Where("order_id = ?", purchase.OrderID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDuplicateOrder
+		}
+
+		// Lock the member so campaign caps are counted consistently
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", purchase.UserID).First(&user).Error; err != nil {
+			return err
+		}
+		balance = user.Points
+
+		applied := make(map[uuid.UUID]*domain.CampaignApplication)
+		var kept []*domain.CampaignApplication
+		for _, application := range applications {
+			if application.Holdout {
+				kept = append(kept, application)
+				continue
+			}
+
+			var campaign domain.Campaign
+			if err := tx.Unscoped().Where("id = ?", application.CampaignID).First(&campaign).Error; err != nil {
+				return err
+			}
+			points := application.Points
+			_, err := limitApplication(tx, &campaign, application)
+			if errors.Is(err, ErrCampaignCapReached) {
+				withheld = append(withheld, application.CampaignID)
+				purchase.PointsEarned -= points
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			purchase.PointsEarned -= points - application.Points
+			applied[application.CampaignID] = application
+			kept = append(kept, application)
+		}
+
+		if err := tx.Create(purchase).Error; err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.CampaignID != nil {
+				application, ok := applied[*entry.CampaignID]
+				if !ok || application.Points == 0 {
+					continue
+				}
+				entry.Points = application.Points
+			}
+
+			var err error
+			if _, balance, err = recordPoints(tx, entry); err != nil {
+				return err
+			}
+		}
+
+		for _, application := range kept {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(application).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return balance, withheld, nil
+}
+
+func (r *purchaseRepository) FindByOrderID(orderID string) (*domain.Purchase, error) {
+	var purchase domain.Purchase
+	err := r.db.Preload("Items").Where("order_id = ?", orderID).First(&purchase).Error
+	if err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// ListBetween returns a page of the purchases made in [from, to), oldest
+// first, without their items.
+func (r *purchaseRepository) ListBetween(from, to time.Time, offset, limit int) ([]*domain.Purchase, error) {
+	var purchases []*domain.Purchase
+	err := r.db.Where("purchased_at >= ? AND purchased_at < ?", from, to).
+		Order("purchased_at ASC, id ASC").
+		Offset(offset).Limit(limit).
+		Find(&purchases).Error
+	if err != nil {
+		return nil, err
+	}
+	return purchases, nil
+}
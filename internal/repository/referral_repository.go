@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+)
+
+var ErrReferralNotPending = errors.New("referral is no longer pending")
+
+type ReferralRepository interface {
+	Create(referral *domain.Referral) error
+	FindByReferee(userID string) (*domain.Referral, error)
+	ListByReferrer(userID string) ([]*domain.Referral, error)
+	CountBySignal(referrerID, ip, deviceID string) (int64, error)
+	Reward(referral *domain.Referral, entries []*domain.PointsTransaction, coupons []*domain.Coupon) error
+}
+
+type referralRepository struct {
+	db *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) ReferralRepository {
+	return &referralRepository{db: db}
+}
+
+func (r *referralRepository) Create(referral *domain.Referral) error {
+	return r.db.Create(referral).Error
+}
+
+// FindByReferee returns the referral that brought the member in, or nil if
+// they were not referred.
+func (r *referralRepository) FindByReferee(userID string) (*domain.Referral, error) {
+	var referral domain.Referral
+	err := r.db.Where("referee_id = ?", userID).First(&referral).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+func (r *referralRepository) ListByReferrer(userID string) ([]*domain.Referral, error) {
+	var referrals []*domain.Referral
+	err := r.db.Where("referrer_id = ?", userID).Order("created_at DESC").Find(&referrals).Error
+	if err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// CountBySignal returns how many of the referrer's earlier referrals signed
+// up from the same IP address or device.
+func (r *referralRepository) CountBySignal(referrerID, ip, deviceID string) (int64, error) {
+	query := r.db.Model(&domain.Referral{}).Where("referrer_id = ?", referrerID)
+	switch {
+	case ip != "" && deviceID != "":
+		query = query.Where("signup_ip = ? OR device_id = ?", ip, deviceID)
+	case ip != "":
+		query = query.Where("signup_ip = ?", ip)
+	case deviceID != "":
+		query = query.Where("device_id = ?", deviceID)
+	default:
+		return 0, nil
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// Reward marks a pending referral as rewarded and pays out the given points
+// and coupons in a single transaction, so each referral pays out only once.
+func (r *referralRepository) Reward(referral *domain.Referral, entries []*domain.PointsTransaction, coupons []*domain.Coupon) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&domain.Referral{}).
+			Where("id = ? AND status = ?", referral.ID, domain.ReferralPending).
+			Updates(map[string]interface{}{
+				"status":          domain.ReferralRewarded,
+				"referrer_points": referral.ReferrerPoints,
+				"rewarded_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReferralNotPending
+		}
+
+		for _, entry := range entries {
+			if _, _, err := recordPoints(tx, entry); err != nil {
+				return err
+			}
+		}
+		for _, coupon := range coupons {
+			if err := tx.Create(coupon).Error; err != nil {
+				return err
+			}
+		}
+
+		referral.Status = domain.ReferralRewarded
+		referral.RewardedAt = &now
+		return nil
+	})
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRefundExceedsPurchase = errors.New("refund exceeds the remaining purchase amount")
+	ErrConcurrentRefund      = errors.New("purchase was refunded concurrently")
+)
+
+type RefundRepository interface {
+	Create(refund *domain.Refund, entries []*domain.PointsTransaction, refundedBefore float64, policy string) (int, error)
+	FindByID(id string) (*domain.Refund, error)
+	FindByRef(refundRef string) (*domain.Refund, error)
+	ListByStatus(status string) ([]*domain.Refund, error)
+	Resolve(refund *domain.Refund, entry *domain.PointsTransaction) (int, error)
+}
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+// Create records the refund together with its point reversals and returns
+// the member's new balance. refundedBefore is the refunded amount the
+// reversals were computed from; if another refund landed in the meantime the
+// call fails with ErrConcurrentRefund. Points of the purchase swept into a
+// household pool are taken back from the pool before the member is debited.
+// Reversals the balance cannot cover are handled according to policy.
+func (r *refundRepository) Create(refund *domain.Refund, entries []*domain.PointsTransaction, refundedBefore float64, policy string) (int, error) {
+	var balance int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var purchase domain.Purchase
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", refund.PurchaseID).First(&purchase).Error; err != nil {
+			return err
+		}
+		if purchase.RefundedAmount != refundedBefore {
+			return ErrConcurrentRefund
+		}
+		if purchase.RefundedAmount+refund.Amount > purchase.Total+0.005 {
+			return ErrRefundExceedsPurchase
+		}
+
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", refund.UserID).First(&user).Error; err != nil {
+			return err
+		}
+
+		// Earnings swept into a household pool come back from the pool first
+		var err error
+		if balance, err = returnSweptPoints(tx, &user, entries); err != nil {
+			return err
+		}
+
+		refund.SettleReversals(entries, balance, policy)
+
+		for _, entry := range entries {
+			if entry.Points == 0 {
+				continue
+			}
+			if policy == domain.RefundPolicyAllowDebt {
+				_, balance, err = recordPointsAllowingDebt(tx, entry)
+			} else {
+				_, balance, err = recordPoints(tx, entry)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.Purchase{}).Where("id = ?", purchase.ID).
+			UpdateColumn("refunded_amount", purchase.RefundedAmount+refund.Amount).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (r *refundRepository) FindByID(id string) (*domain.Refund, error) {
+	var refund domain.Refund
+	err := r.db.Where("id = ?", id).First(&refund).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepository) FindByRef(refundRef string) (*domain.Refund, error) {
+	var refund domain.Refund
+	err := r.db.Where("refund_ref = ?", refundRef).First(&refund).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepository) ListByStatus(status string) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	err := r.db.Where("status = ?", status).Order("created_at ASC").Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// Resolve closes a refund that was flagged for review. If entry is set the
+// outstanding reversal is recorded as debt. It returns the member's balance.
+func (r *refundRepository) Resolve(refund *domain.Refund, entry *domain.PointsTransaction) (int, error) {
+	var balance int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, domain.RefundInReview).
+			Updates(map[string]interface{}{
+				"status":     domain.RefundResolved,
+				"resolution": refund.Resolution,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("refund is not awaiting review")
+		}
+
+		if entry == nil {
+			var user domain.User
+			if err := tx.Select("points").Where("id = ?", refund.UserID).First(&user).Error; err != nil {
+				return err
+			}
+			balance = user.Points
+			return nil
+		}
+
+		var err error
+		_, balance, err = recordPointsAllowingDebt(tx, entry)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	refund.Status = domain.RefundResolved
+	return balance, nil
+}
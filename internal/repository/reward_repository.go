@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRewardUnavailable   = errors.New("reward is not available")
+	ErrOutOfStock          = errors.New("reward is out of stock")
+	ErrRedemptionLimit     = errors.New("redemption limit reached for this reward")
+	ErrInvalidStatusChange = errors.New("invalid redemption status change")
+)
+
+type RewardRepository interface {
+	Create(reward *domain.Reward) error
+	FindByID(id string) (*domain.Reward, error)
+	Update(reward *domain.Reward) error
+	Delete(id string) error
+	ListAvailable() ([]*domain.Reward, error)
+	Redeem(redemption *domain.RewardRedemption) (int, error)
+	FindRedemption(id string) (*domain.RewardRedemption, error)
+	ListRedemptionsByUser(userID string) ([]*domain.RewardRedemption, error)
+	ListRedemptions(status string, offset, limit int) ([]*domain.RewardRedemption, int64, error)
+	ChangeRedemptionStatus(redemption *domain.RewardRedemption, status string) error
+}
+
+type rewardRepository struct {
+	db *gorm.DB
+}
+
+func NewRewardRepository(db *gorm.DB) RewardRepository {
+	return &rewardRepository{db: db}
+}
+
+func (r *rewardRepository) Create(reward *domain.Reward) error {
+	return r.db.Create(reward).Error
+}
+
+func (r *rewardRepository) FindByID(id string) (*domain.Reward, error) {
+	var reward domain.Reward
+	err := r.db.Where("id = ?", id).First(&reward).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reward, nil
+}
+
+func (r *rewardRepository) Update(reward *domain.Reward) error {
+	return r.db.Save(reward).Error
+}
+
+func (r *rewardRepository) Delete(id string) error {
+	return r.db.Delete(&domain.Reward{}, "id = ?", id).Error
+}
+
+func (r *rewardRepository) ListAvailable() ([]*domain.Reward, error) {
+	var rewards []*domain.Reward
+	err := r.db.Where("is_active = ? AND (stock IS NULL OR stock > 0)", true).
+		Order("points ASC").
+		Find(&rewards).Error
+	if err != nil {
+		return nil, err
+	}
+	return rewards, nil
+}
+
+// Redeem debits the reward's points from the member, reserves one unit of
+// stock and records the redemption in a single transaction. It returns the
+// member's new balance. The lots the points came from are kept with the
+// redemption so a cancellation or refund can restore them.
+func (r *rewardRepository) Redeem(redemption *domain.RewardRedemption) (int, error) {
+	var balance int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the member before the reward, the same order status changes use
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", redemption.UserID).First(&user).Error; err != nil {
+			return err
+		}
+
+		// Lock the reward so stock and member limits are checked consistently
+		var reward domain.Reward
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", redemption.RewardID).First(&reward).Error; err != nil {
+			return err
+		}
+		if !reward.IsActive {
+			return ErrRewardUnavailable
+		}
+		if reward.Stock != nil && *reward.Stock <= 0 {
+			return ErrOutOfStock
+		}
+
+		if reward.PerMemberLimit > 0 {
+			var count int64
+			if err := tx.Model(&domain.RewardRedemption{}).
+				Where("reward_id = ? AND user_id = ? AND status IN ?", reward.ID, redemption.UserID,
+					[]string{domain.RedemptionRequested, domain.RedemptionFulfilled}).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count >= int64(reward.PerMemberLimit) {
+				return ErrRedemptionLimit
+			}
+		}
+
+		redemption.Points = reward.Points
+		redemption.Status = domain.RedemptionRequested
+		if err := tx.Create(redemption).Error; err != nil {
+			return err
+		}
+
+		key := "reward:redeem:" + redemption.ID.String()
+		var slices []lotSlice
+		var err error
+		_, balance, slices, err = recordPointsWithLots(tx, &domain.PointsTransaction{
+			UserID:         redemption.UserID,
+			Type:           domain.PointsRedeem,
+			Points:         -reward.Points,
+			Note:           reward.Name,
+			IdempotencyKey: &key,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		for _, slice := range slices {
+			if err := tx.Create(&domain.RewardRedemptionLot{
+				RedemptionID: redemption.ID,
+				Points:       slice.Points,
+				EarnedAt:     slice.EarnedAt,
+				ExpiresAt:    slice.ExpiresAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if reward.Stock == nil {
+			return nil
+		}
+		return tx.Model(&domain.Reward{}).Where("id = ?", reward.ID).
+			UpdateColumn("stock", gorm.Expr("stock - 1")).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (r *rewardRepository) FindRedemption(id string) (*domain.RewardRedemption, error) {
+	var redemption domain.RewardRedemption
+	err := r.db.Preload("Reward").Where("id = ?", id).First(&redemption).Error
+	if err != nil {
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+func (r *rewardRepository) ListRedemptionsByUser(userID string) ([]*domain.RewardRedemption, error) {
+	var redemptions []*domain.RewardRedemption
+	err := r.db.Preload("Reward").Where("user_id = ?", userID).Order("created_at DESC").Find(&redemptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+func (r *rewardRepository) ListRedemptions(status string, offset, limit int) ([]*domain.RewardRedemption, int64, error) {
+	query := r.db.Model(&domain.RewardRedemption{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var redemptions []*domain.RewardRedemption
+	err := query.Preload("Reward").Order("created_at ASC").Offset(offset).Limit(limit).Find(&redemptions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return redemptions, total, nil
+}
+
+// ChangeRedemptionStatus moves a redemption through its fulfillment states.
+// Cancelling a requested redemption or refunding a fulfilled one returns the
+// points to the member, with the expiry dates of the lots they were spent
+// from, and the unit to stock.
+func (r *rewardRepository) ChangeRedemptionStatus(redemption *domain.RewardRedemption, status string) error {
+	allowedFrom := map[string]string{
+		domain.RedemptionFulfilled: domain.RedemptionRequested,
+		domain.RedemptionCancelled: domain.RedemptionRequested,
+		domain.RedemptionRefunded:  domain.RedemptionFulfilled,
+	}
+	from, ok := allowedFrom[status]
+	if !ok {
+		return ErrInvalidStatusChange
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the member first, the same order Redeem uses
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", redemption.UserID).First(&user).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": status, "note": redemption.Note}
+		if status == domain.RedemptionFulfilled {
+			now := time.Now()
+			updates["fulfilled_at"] = now
+			redemption.FulfilledAt = &now
+		}
+
+		result := tx.Model(&domain.RewardRedemption{}).
+			Where("id = ? AND status = ?", redemption.ID, from).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidStatusChange
+		}
+		redemption.Status = status
+
+		if status == domain.RedemptionFulfilled {
+			return nil
+		}
+
+		// Give the points back and release the unit
+		var lots []*domain.RewardRedemptionLot
+		if err := tx.Where("redemption_id = ?", redemption.ID).
+			Order("earned_at ASC, id ASC").Find(&lots).Error; err != nil {
+			return err
+		}
+		slices := make([]lotSlice, 0, len(lots))
+		for _, lot := range lots {
+			slices = append(slices, lotSlice{Points: lot.Points, EarnedAt: lot.EarnedAt, ExpiresAt: lot.ExpiresAt})
+		}
+
+		key := "reward:" + status + ":" + redemption.ID.String()
+		if _, _, _, err := recordPointsWithLots(tx, &domain.PointsTransaction{
+			UserID:         redemption.UserID,
+			Type:           domain.PointsReverse,
+			Points:         redemption.Points,
+			Note:           "reward redemption " + status,
+			IdempotencyKey: &key,
+		}, slices); err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.Reward{}).Where("id = ? AND stock IS NOT NULL", redemption.RewardID).
+			UpdateColumn("stock", gorm.Expr("stock + 1")).Error
+	})
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SegmentRepository interface {
+	Create(segment *domain.Segment) error
+	FindByID(id string) (*domain.Segment, error)
+	Update(segment *domain.Segment) error
+	Delete(id string) error
+	List() ([]*domain.Segment, error)
+	IsMember(rules *domain.SegmentRules, userID string, now time.Time) (bool, error)
+	Preview(rules *domain.SegmentRules, now time.Time, limit int) (int64, []*domain.User, error)
+	Materialize(segment *domain.Segment, rules *domain.SegmentRules, now time.Time) (int, error)
+	ListMembers(segmentID string, offset, limit int) ([]*domain.User, int64, error)
+	SetUserTags(userID string, tags []string) error
+	ListUserTags(userID string) ([]string, error)
+}
+
+type segmentRepository struct {
+	db *gorm.DB
+}
+
+func NewSegmentRepository(db *gorm.DB) SegmentRepository {
+	return &segmentRepository{db: db}
+}
+
+func (r *segmentRepository) Create(segment *domain.Segment) error {
+	return r.db.Create(segment).Error
+}
+
+func (r *segmentRepository) FindByID(id string) (*domain.Segment, error) {
+	var segment domain.Segment
+	err := r.db.Where("id = ?", id).First(&segment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *segmentRepository) Update(segment *domain.Segment) error {
+	return r.db.Save(segment).Error
+}
+
+func (r *segmentRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("segment_id = ?", id).Delete(&domain.SegmentMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Segment{}, "id = ?", id).Error
+	})
+}
+
+func (r *segmentRepository) List() ([]*domain.Segment, error) {
+	var segments []*domain.Segment
+	err := r.db.Order("name ASC").Find(&segments).Error
+	if err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// IsMember evaluates the rules for a single member against live data.
+func (r *segmentRepository) IsMember(rules *domain.SegmentRules, userID string, now time.Time) (bool, error) {
+	var count int64
+	err := segmentQuery(r.db, rules, now).Where("users.id = ?", userID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Preview evaluates the rules on demand, returning how many members match
+// and the first few of them.
+func (r *segmentRepository) Preview(rules *domain.SegmentRules, now time.Time, limit int) (int64, []*domain.User, error) {
+	var count int64
+	if err := segmentQuery(r.db, rules, now).Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var users []*domain.User
+	err := segmentQuery(r.db, rules, now).Order("users.created_at ASC").Limit(limit).Find(&users).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, users, nil
+}
+
+// Materialize replaces the stored members of the segment with the members
+// matching its rules now.
+func (r *segmentRepository) Materialize(segment *domain.Segment, rules *domain.SegmentRules, now time.Time) (int, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := segmentQuery(tx, rules, now).Pluck("users.id", &userIDs).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("segment_id = ?", segment.ID).Delete(&domain.SegmentMember{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) > 0 {
+			members := make([]*domain.SegmentMember, 0, len(userIDs))
+			for _, userID := range userIDs {
+				members = append(members, &domain.SegmentMember{SegmentID: segment.ID, UserID: userID, CreatedAt: now})
+			}
+			if err := tx.CreateInBatches(members, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		segment.MemberCount = len(userIDs)
+		segment.MaterializedAt = &now
+		return tx.Model(segment).Updates(map[string]interface{}{
+			"member_count":    segment.MemberCount,
+			"materialized_at": now,
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(userIDs), nil
+}
+
+func (r *segmentRepository) ListMembers(segmentID string, offset, limit int) ([]*domain.User, int64, error) {
+	query := r.db.Model(&domain.User{}).
+		Joins("JOIN segment_members ON segment_members.user_id = users.id").
+		Where("segment_members.segment_id = ?", segmentID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*domain.User
+	err := query.Order("users.created_at ASC").Offset(offset).Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// SetUserTags replaces the member's tags.
+func (r *segmentRepository) SetUserTags(userID string, tags []string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&domain.UserTag{}).Error; err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := tx.Create(&domain.UserTag{UserID: id, Tag: tag}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *segmentRepository) ListUserTags(userID string) ([]string, error) {
+	var tags []string
+	err := r.db.Model(&domain.UserTag{}).Where("user_id = ?", userID).Order("tag ASC").Pluck("tag", &tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// segmentQuery selects the members matching rules. On-demand checks,
+// previews and materialization all use it, so they always agree.
+func segmentQuery(db *gorm.DB, rules *domain.SegmentRules, now time.Time) *gorm.DB {
+	query := db.Model(&domain.User{})
+
+	if len(rules.Tiers) > 0 {
+		query = query.Where("users.tier IN ?", rules.Tiers)
+	}
+	if rules.MinTier != "" {
+		query = query.Where("users.tier IN (SELECT name FROM tiers WHERE deleted_at IS NULL AND rank >= (SELECT rank FROM tiers WHERE name = ? AND deleted_at IS NULL))", rules.MinTier)
+	}
+	if len(rules.Clubs) > 0 {
+		query = query.Where("users.club IN ?", rules.Clubs)
+	}
+	if len(rules.Tags) > 0 {
+		tags := make([]string, 0, len(rules.Tags))
+		for _, tag := range rules.Tags {
+			tags = append(tags, strings.ToLower(tag))
+		}
+		query = query.Where("EXISTS (SELECT 1 FROM user_tags WHERE user_tags.user_id = users.id AND user_tags.tag IN ?)", tags)
+	}
+	if rules.MinPoints != nil {
+		query = query.Where("users.points >= ?", *rules.MinPoints)
+	}
+	if rules.MaxPoints != nil {
+		query = query.Where("users.points <= ?", *rules.MaxPoints)
+	}
+	if rules.PurchasedWithinDays > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM purchases WHERE purchases.user_id = users.id AND purchases.purchased_at >= ?)",
+			now.AddDate(0, 0, -rules.PurchasedWithinDays))
+	}
+	if rules.NoPurchaseForDays > 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM purchases WHERE purchases.user_id = users.id AND purchases.purchased_at >= ?)",
+			now.AddDate(0, 0, -rules.NoPurchaseForDays))
+	}
+	if rules.MinSpend > 0 {
+		var since time.Time
+		if rules.SpendDays > 0 {
+			since = now.AddDate(0, 0, -rules.SpendDays)
+		}
+		query = query.Where("(SELECT COALESCE(SUM(purchases.total - purchases.refunded_amount), 0) FROM purchases WHERE purchases.user_id = users.id AND purchases.purchased_at >= ?) >= ?",
+			since, rules.MinSpend)
+	}
+	if rules.SignedUpAfter != nil {
+		query = query.Where("users.created_at >= ?", *rules.SignedUpAfter)
+	}
+	if rules.SignedUpBefore != nil {
+		query = query.Where("users.created_at < ?", *rules.SignedUpBefore)
+	}
+
+	return query
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TemplateRepository interface {
+	Create(template *domain.Template) error
+	FindByID(id string) (*domain.Template, error)
+	Update(template *domain.Template) error
+	Delete(id string) error
+	List(kind string) ([]*domain.Template, error)
+}
+
+type templateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepository{db: db}
+}
+
+func (r *templateRepository) Create(template *domain.Template) error {
+	return r.db.Create(template).Error
+}
+
+func (r *templateRepository) FindByID(id string) (*domain.Template, error) {
+	var template domain.Template
+	err := r.db.Where("id = ?", id).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *templateRepository) Update(template *domain.Template) error {
+	return r.db.Save(template).Error
+}
+
+func (r *templateRepository) Delete(id string) error {
+	return r.db.Delete(&domain.Template{}, "id = ?", id).Error
+}
+
+// List returns the templates of a kind, or of every kind if kind is empty,
+// by name.
+func (r *templateRepository) List(kind string) ([]*domain.Template, error) {
+	var templates []*domain.Template
+	query := r.db.Order("name ASC")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
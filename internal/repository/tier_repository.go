@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"github.com/gclub/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TierRepository interface {
+	Create(tier *domain.Tier) error
+	FindByName(name string) (*domain.Tier, error)
+	Update(tier *domain.Tier) error
+	Delete(id string) error
+	List() ([]*domain.Tier, error)
+	ChangeUserTier(user *domain.User, history *domain.TierHistory) error
+	ListHistory(userID string) ([]*domain.TierHistory, error)
+}
+
+type tierRepository struct {
+	db *gorm.DB
+}
+
+func NewTierRepository(db *gorm.DB) TierRepository {
+	return &tierRepository{db: db}
+}
+
+func (r *tierRepository) Create(tier *domain.Tier) error {
+	return r.db.Create(tier).Error
+}
+
+func (r *tierRepository) FindByName(name string) (*domain.Tier, error) {
+	var tier domain.Tier
+	err := r.db.Where("name = ?", name).First(&tier).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+func (r *tierRepository) Update(tier *domain.Tier) error {
+	return r.db.Save(tier).Error
+}
+
+func (r *tierRepository) Delete(id string) error {
+	return r.db.Delete(&domain.Tier{}, "id = ?", id).Error
+}
+
+// List returns all tiers from the lowest to the highest rank.
+func (r *tierRepository) List() ([]*domain.Tier, error) {
+	var tiers []*domain.Tier
+	err := r.db.Order("rank ASC").Find(&tiers).Error
+	if err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// ChangeUserTier stores the user's tier and grace period together with the
+// history entry explaining the change.
+func (r *tierRepository) ChangeUserTier(user *domain.User, history *domain.TierHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"tier":             user.Tier,
+			"tier_grace_until": user.TierGraceUntil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if history == nil {
+			return nil
+		}
+		return tx.Create(history).Error
+	})
+}
+
+func (r *tierRepository) ListHistory(userID string) ([]*domain.TierHistory, error) {
+	var history []*domain.TierHistory
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
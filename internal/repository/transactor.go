@@ -0,0 +1,28 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Tx is a database transaction. Repositories join it through WithTx.
+type Tx struct {
+	db *gorm.DB
+}
+
+// Transactor runs work that spans several repositories atomically.
+type Transactor interface {
+	WithinTransaction(fn func(tx Tx) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction commits if fn returns nil and rolls back otherwise.
+func (t *transactor) WithinTransaction(fn func(tx Tx) error) error {
+	return t.db.Transaction(func(db *gorm.DB) error {
+		return fn(Tx{db: db})
+	})
+}
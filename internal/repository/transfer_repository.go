@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferNotPending = errors.New("transfer is no longer pending")
+	ErrTransferLimit      = errors.New("daily transfer limit reached")
+)
+
+// TransferLimits caps what a member may give away in 24 hours, by transfer
+// and household contribution together.
+type TransferLimits struct {
+	DailyPoints int
+	MaxPerDay   int
+}
+
+type TransferRepository interface {
+	Create(transfer *domain.PointsTransfer) error
+	FindByID(id string) (*domain.PointsTransfer, error)
+	ListByUser(userID string, offset, limit int) ([]*domain.PointsTransfer, int64, error)
+	SumSentSince(senderID string, since time.Time) (int, int, error)
+	CountSendersSince(recipientID string, since time.Time) (int, error)
+	Complete(transfer *domain.PointsTransfer, limits TransferLimits) (int, error)
+	ChangeStatus(transfer *domain.PointsTransfer, status string) error
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) Create(transfer *domain.PointsTransfer) error {
+	return r.db.Create(transfer).Error
+}
+
+func (r *transferRepository) FindByID(id string) (*domain.PointsTransfer, error) {
+	var transfer domain.PointsTransfer
+	if err := r.db.Where("id = ?", id).First(&transfer).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ListByUser returns the transfers the member sent or received, newest first.
+func (r *transferRepository) ListByUser(userID string, offset, limit int) ([]*domain.PointsTransfer, int64, error) {
+	query := r.db.Model(&domain.PointsTransfer{}).Where("sender_id = ? OR recipient_id = ?", userID, userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transfers []*domain.PointsTransfer
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&transfers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transfers, total, nil
+}
+
+// SumSentSince returns the points and number of transfers the member has sent
+// since the given time. Pending transfers count, as they may still complete.
+func (r *transferRepository) SumSentSince(senderID string, since time.Time) (int, int, error) {
+	var result struct {
+		Points int
+		Count  int
+	}
+	err := r.db.Model(&domain.PointsTransfer{}).
+		Where("sender_id = ? AND created_at >= ?", senderID, since).
+		Where("status IN ?", []string{domain.TransferPending, domain.TransferCompleted}).
+		Select("COALESCE(SUM(points), 0) AS points, COUNT(*) AS count").
+		Scan(&result).Error
+	return result.Points, result.Count, err
+}
+
+// CountSendersSince returns how many distinct members sent the recipient
+// points since the given time.
+func (r *transferRepository) CountSendersSince(recipientID string, since time.Time) (int, error) {
+	var count int64
+	err := r.db.Model(&domain.PointsTransfer{}).
+		Where("recipient_id = ? AND created_at >= ?", recipientID, since).
+		Where("status IN ?", []string{domain.TransferPending, domain.TransferCompleted}).
+		Distinct("sender_id").
+		Count(&count).Error
+	return int(count), err
+}
+
+// Complete moves the points of a pending transfer and marks it completed in a
+// single transaction. The sender's limits are checked again under the lock,
+// as concurrent transfers may all have passed the check when sent. It
+// returns the sender's new balance.
+func (r *transferRepository) Complete(transfer *domain.PointsTransfer, limits TransferLimits) (int, error) {
+	var balance int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock both members in the order movePoints does
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{transfer.SenderID, transfer.RecipientID}).
+			Order("id ASC").
+			Find(&[]domain.User{}).Error; err != nil {
+			return err
+		}
+		if err := checkGivingLimits(tx, transfer.SenderID, transfer.Points, limits); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&domain.PointsTransfer{}).
+			Where("id = ? AND status = ?", transfer.ID, domain.TransferPending).
+			Updates(map[string]interface{}{"status": domain.TransferCompleted, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferNotPending
+		}
+
+		var err error
+		balance, err = movePoints(tx,
+			&domain.PointsTransaction{
+				UserID: transfer.SenderID,
+				Type:   domain.PointsTransferOut,
+				Points: -transfer.Points,
+				PairID: &transfer.ID,
+				Note:   transfer.Message,
+			},
+			&domain.PointsTransaction{
+				UserID: transfer.RecipientID,
+				Type:   domain.PointsTransferIn,
+				Points: transfer.Points,
+				PairID: &transfer.ID,
+				Note:   transfer.Message,
+			})
+		if err != nil {
+			return err
+		}
+
+		transfer.Status = domain.TransferCompleted
+		transfer.RespondedAt = &now
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// ChangeStatus closes a pending transfer without moving any points.
+func (r *transferRepository) ChangeStatus(transfer *domain.PointsTransfer, status string) error {
+	now := time.Now()
+	result := r.db.Model(&domain.PointsTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, domain.TransferPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTransferNotPending
+	}
+
+	transfer.Status = status
+	transfer.RespondedAt = &now
+	return nil
+}
+
+// checkGivingLimits checks that the member may give points away within tx.
+// Completed transfers and household contributions of the last 24 hours
+// count. The caller holds the lock on the member row, so concurrent
+// movements are counted.
+func checkGivingLimits(tx *gorm.DB, userID uuid.UUID, points int, limits TransferLimits) error {
+	since := time.Now().Add(-24 * time.Hour)
+
+	var transfers, contributions struct {
+		Points int
+		Count  int
+	}
+	if err := tx.Model(&domain.PointsTransfer{}).
+		Where("sender_id = ? AND status = ? AND created_at >= ?", userID, domain.TransferCompleted, since).
+		Select("COALESCE(SUM(points), 0) AS points, COUNT(*) AS count").
+		Scan(&transfers).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&domain.HouseholdPointsTransaction{}).
+		Where("user_id = ? AND type = ? AND created_at >= ?", userID, domain.PointsTransferIn, since).
+		Select("COALESCE(SUM(points), 0) AS points, COUNT(*) AS count").
+		Scan(&contributions).Error; err != nil {
+		return err
+	}
+
+	if transfers.Count+contributions.Count >= limits.MaxPerDay ||
+		transfers.Points+contributions.Points+points > limits.DailyPoints {
+		return ErrTransferLimit
+	}
+	return nil
+}
+
+// movePoints records both legs of a member-to-member movement within tx and
+// returns the sender's new balance. The recipient takes over the sender's
+// lots with their original expiry dates, so transferring points never
+// extends their life.
+func movePoints(tx *gorm.DB, out, in *domain.PointsTransaction) (int, error) {
+	// Lock both members in a stable order so opposite transfers cannot deadlock
+	var users []domain.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", []uuid.UUID{out.UserID, in.UserID}).
+		Order("id ASC").
+		Find(&users).Error; err != nil {
+		return 0, err
+	}
+	var sender, recipient *domain.User
+	for i := range users {
+		switch users[i].ID {
+		case out.UserID:
+			sender = &users[i]
+		case in.UserID:
+			recipient = &users[i]
+		}
+	}
+	if sender == nil || recipient == nil {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	if sender.Points+out.Points < 0 {
+		return 0, ErrInsufficientPoints
+	}
+
+	now := time.Now()
+	out.BalanceAfter = sender.Points + out.Points
+	out.CreatedAt = now
+	if err := tx.Create(out).Error; err != nil {
+		return 0, err
+	}
+	slices, err := consumeLots(tx, sender.ID.String(), -out.Points, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&domain.User{}).Where("id = ?", sender.ID).
+		UpdateColumn("points", out.BalanceAfter).Error; err != nil {
+		return 0, err
+	}
+
+	in.BalanceAfter = recipient.Points + in.Points
+	in.CreatedAt = now
+	if err := tx.Create(in).Error; err != nil {
+		return 0, err
+	}
+
+	if err := takeOverLots(tx, recipient, in, slices); err != nil {
+		return 0, err
+	}
+
+	err = tx.Model(&domain.User{}).Where("id = ?", recipient.ID).
+		UpdateColumn("points", in.BalanceAfter).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return out.BalanceAfter, nil
+}
+
+// takeOverLots opens lots for the member credited by in from the slices
+// taken off the other side, keeping their earn and expiry dates. Points that
+// pay off a debt are not backed by a lot, and points the slices do not cover
+// open a lot under the member's club policy.
+func takeOverLots(tx *gorm.DB, recipient *domain.User, in *domain.PointsTransaction, slices []lotSlice) error {
+	backed := in.Points
+	if backed > in.BalanceAfter {
+		backed = in.BalanceAfter
+	}
+	for _, slice := range slices {
+		if backed <= 0 {
+			break
+		}
+		points := slice.Points
+		if points > backed {
+			points = backed
+		}
+		if err := tx.Create(&domain.PointsLot{
+			UserID:        recipient.ID,
+			TransactionID: in.ID,
+			Points:        points,
+			Remaining:     points,
+			EarnedAt:      slice.EarnedAt,
+			ExpiresAt:     slice.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		backed -= points
+	}
+	if backed > 0 {
+		// The other side predates lot tracking
+		return openLot(tx, recipient, in, backed)
+	}
+	return nil
+}
@@ -9,8 +9,10 @@ type UserRepository interface {
 	Create(user *domain.User) error
 	FindByID(id string) (*domain.User, error)
 	FindByEmail(email string) (*domain.User, error)
+	FindByReferralCode(code string) (*domain.User, error)
 	Update(user *domain.User) error
 	Delete(id string) error
+	List(offset, limit int) ([]*domain.User, error)
 }
 
 type userRepository struct {
@@ -34,6 +36,15 @@ func (r *userRepository) FindByID(id string) (*domain.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) FindByReferralCode(code string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Where("referral_code = ?", code).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 	var user domain.User
 	err := r.db.Where("email = ?", email).First(&user).Error
@@ -43,10 +54,22 @@ func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 	return &user, nil
 }
 
+// Update saves the user's profile. The balance, role and tier are left out:
+// they change through the points ledger and their own flows, and writing a
+// stale copy back would undo those changes.
 func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit("points", "role", "tier", "tier_grace_until").Save(user).Error
 }
 
 func (r *userRepository) Delete(id string) error {
 	return r.db.Delete(&domain.User{}, "id = ?", id).Error
 }
+
+func (r *userRepository) List(offset, limit int) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.db.Order("created_at ASC, id ASC").Offset(offset).Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
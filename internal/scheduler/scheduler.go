@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// JobFunc is run on every tick with the time of the tick.
+type JobFunc func(now time.Time) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs background jobs at fixed intervals. Each job runs in its
+// own goroutine, so a slow job never delays the others.
+type Scheduler struct {
+	jobs []job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every registers fn to run every interval. Jobs must be registered before
+// Start is called.
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: fn})
+}
+
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop signals all jobs to exit and waits for running jobs to finish.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := j.run(now); err != nil {
+				log.Printf("Job %s failed: %v", j.name, err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
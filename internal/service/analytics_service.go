@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+const (
+	// analyticsRestateDays is how far back each refresh recomputes the
+	// activity rollup, so late refunds are reflected.
+	analyticsRestateDays = 35
+	// liabilityExpiringWindow is the window for points reported as expiring.
+	liabilityExpiringWindow = 30 * 24 * time.Hour
+)
+
+// RFM segment labels
+const (
+	RFMChampions      = "champions"
+	RFMLoyal          = "loyal"
+	RFMPromising      = "promising"
+	RFMAtRisk         = "at_risk"
+	RFMHibernating    = "hibernating"
+	RFMNeedsAttention = "needs_attention"
+)
+
+type AnalyticsService interface {
+	GetRFMSummary(from, to time.Time) (*RFMSummary, error)
+	ListRFMScores(from, to time.Time, page, pageSize int) ([]*repository.RFMScore, int64, error)
+	GetCohortRetention(from, to time.Time) ([]*CohortCurve, error)
+	GetPointsLiability(from, to time.Time) (*LiabilityReport, error)
+	RefreshRollups(now time.Time) error
+}
+
+// RFMSummary is how members with purchases in a date range split across
+// RFM segments.
+type RFMSummary struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Members  int64            `json:"members"`
+	Segments map[string]int64 `json:"segments"`
+	Cells    []*RFMCellCount  `json:"cells"`
+}
+
+// RFMCellCount is how many members have a combination of RFM scores, e.g.
+// "545".
+type RFMCellCount struct {
+	Score   string `json:"score"`
+	Segment string `json:"segment"`
+	Members int64  `json:"members"`
+}
+
+// CohortCurve is the share of a signup month cohort that purchased in each
+// following month.
+type CohortCurve struct {
+	Cohort    string            `json:"cohort"`
+	Size      int               `json:"size"`
+	Retention []*RetentionPoint `json:"retention"`
+}
+
+type RetentionPoint struct {
+	MonthOffset   int     `json:"month_offset"`
+	Month         string  `json:"month"`
+	ActiveMembers int     `json:"active_members"`
+	Rate          float64 `json:"rate"`
+}
+
+// LiabilityReport is the daily points liability over a date range.
+type LiabilityReport struct {
+	Current *domain.PointsLiability   `json:"current,omitempty"`
+	Series  []*domain.PointsLiability `json:"series"`
+}
+
+type analyticsService struct {
+	analyticsRepo repository.AnalyticsRepository
+	loyalty       config.LoyaltyConfig
+}
+
+func NewAnalyticsService(analyticsRepo repository.AnalyticsRepository, loyalty config.LoyaltyConfig) AnalyticsService {
+	return &analyticsService{
+		analyticsRepo: analyticsRepo,
+		loyalty:       loyalty,
+	}
+}
+
+func validateRange(from, to time.Time) error {
+	if from.After(to) {
+		return errors.New("from must not be after to")
+	}
+	return nil
+}
+
+// rfmSegment labels a combination of RFM scores.
+func rfmSegment(recency, frequency, monetary int) string {
+	switch {
+	case recency >= 4 && frequency >= 4 && monetary >= 4:
+		return RFMChampions
+	case recency >= 3 && frequency >= 3:
+		return RFMLoyal
+	case recency >= 4:
+		return RFMPromising
+	case recency <= 2 && frequency >= 3:
+		return RFMAtRisk
+	case recency <= 2:
+		return RFMHibernating
+	default:
+		return RFMNeedsAttention
+	}
+}
+
+func (s *analyticsService) GetRFMSummary(from, to time.Time) (*RFMSummary, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	cells, err := s.analyticsRepo.RFMDistribution(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &RFMSummary{
+		From:     from,
+		To:       to,
+		Segments: map[string]int64{},
+		Cells:    make([]*RFMCellCount, 0, len(cells)),
+	}
+	for _, cell := range cells {
+		segment := rfmSegment(cell.RecencyScore, cell.FrequencyScore, cell.MonetaryScore)
+		summary.Members += cell.Members
+		summary.Segments[segment] += cell.Members
+		summary.Cells = append(summary.Cells, &RFMCellCount{
+			Score:   fmt.Sprintf("%d%d%d", cell.RecencyScore, cell.FrequencyScore, cell.MonetaryScore),
+			Segment: segment,
+			Members: cell.Members,
+		})
+	}
+	return summary, nil
+}
+
+func (s *analyticsService) ListRFMScores(from, to time.Time, page, pageSize int) ([]*repository.RFMScore, int64, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.analyticsRepo.RFMScores(from, to, (page-1)*pageSize, pageSize)
+}
+
+func (s *analyticsService) GetCohortRetention(from, to time.Time) ([]*CohortCurve, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.analyticsRepo.ListCohorts(from.Format("2006-01"), to.Format("2006-01"))
+	if err != nil {
+		return nil, err
+	}
+	return buildCohortCurves(rows, time.Now()), nil
+}
+
+// buildCohortCurves turns cohort rollup rows, sorted by cohort and offset,
+// into retention curves running up to the month of now. Months in which
+// nobody from the cohort bought are filled in with zero.
+func buildCohortCurves(rows []*domain.CohortRetention, now time.Time) []*CohortCurve {
+	var curves []*CohortCurve
+	byCohort := map[string]*CohortCurve{}
+	active := map[string]map[int]int{}
+
+	for _, row := range rows {
+		curve, ok := byCohort[row.Cohort]
+		if !ok {
+			curve = &CohortCurve{Cohort: row.Cohort}
+			byCohort[row.Cohort] = curve
+			active[row.Cohort] = map[int]int{}
+			curves = append(curves, curve)
+		}
+		if row.CohortSize > curve.Size {
+			curve.Size = row.CohortSize
+		}
+		active[row.Cohort][row.MonthOffset] = row.ActiveMembers
+	}
+
+	for _, curve := range curves {
+		start, err := time.Parse("2006-01", curve.Cohort)
+		if err != nil {
+			continue
+		}
+		months := (now.Year()-start.Year())*12 + int(now.Month()-start.Month())
+		curve.Retention = make([]*RetentionPoint, 0, months+1)
+		for offset := 0; offset <= months; offset++ {
+			point := &RetentionPoint{
+				MonthOffset:   offset,
+				Month:         start.AddDate(0, offset, 0).Format("2006-01"),
+				ActiveMembers: active[curve.Cohort][offset],
+			}
+			if curve.Size > 0 {
+				point.Rate = math.Round(float64(point.ActiveMembers)/float64(curve.Size)*10000) / 10000
+			}
+			curve.Retention = append(curve.Retention, point)
+		}
+	}
+	return curves
+}
+
+func (s *analyticsService) GetPointsLiability(from, to time.Time) (*LiabilityReport, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	series, err := s.analyticsRepo.ListLiability(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LiabilityReport{Series: series}
+	if len(series) > 0 {
+		report.Current = series[len(series)-1]
+	}
+	return report, nil
+}
+
+// RefreshRollups recomputes the analytics rollups. The activity rollup is
+// restated for the last few weeks before the latest day it holds, or built
+// from scratch when empty. The liability is recorded for the day of now.
+func (s *analyticsService) RefreshRollups(now time.Time) error {
+	latest, err := s.analyticsRepo.LatestActivityDay()
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if latest != nil {
+		since = latest.AddDate(0, 0, -analyticsRestateDays)
+	}
+	if err := s.analyticsRepo.RebuildActivity(since); err != nil {
+		return err
+	}
+
+	if err := s.analyticsRepo.RebuildCohorts(); err != nil {
+		return err
+	}
+
+	outstanding, err := s.analyticsRepo.Outstanding(now, liabilityExpiringWindow)
+	if err != nil {
+		return err
+	}
+	now = now.UTC()
+	return s.analyticsRepo.RecordLiability(&domain.PointsLiability{
+		Day:               time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		OutstandingPoints: outstanding.Points,
+		Members:           outstanding.Members,
+		ExpiringPoints:    outstanding.Expiring,
+		PointValue:        s.loyalty.PointValue,
+		Liability:         math.Round(float64(outstanding.Points)*s.loyalty.PointValue*100) / 100,
+		CreatedAt:         now,
+	})
+}
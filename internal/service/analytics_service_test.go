@@ -0,0 +1,183 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsRepository) RFMScores(from, to time.Time, offset, limit int) ([]*repository.RFMScore, int64, error) {
+	args := m.Called(from, to, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*repository.RFMScore), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAnalyticsRepository) RFMDistribution(from, to time.Time) ([]*repository.RFMCell, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.RFMCell), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) ListCohorts(fromCohort, toCohort string) ([]*domain.CohortRetention, error) {
+	args := m.Called(fromCohort, toCohort)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CohortRetention), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) ListLiability(from, to time.Time) ([]*domain.PointsLiability, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PointsLiability), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) LatestActivityDay() (*time.Time, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) RebuildActivity(since time.Time) error {
+	args := m.Called(since)
+	return args.Error(0)
+}
+
+func (m *MockAnalyticsRepository) RebuildCohorts() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockAnalyticsRepository) Outstanding(now time.Time, expiringWithin time.Duration) (*repository.PointsOutstanding, error) {
+	args := m.Called(now, expiringWithin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PointsOutstanding), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) RecordLiability(liability *domain.PointsLiability) error {
+	args := m.Called(liability)
+	return args.Error(0)
+}
+
+func TestRFMSegment(t *testing.T) {
+	tests := []struct {
+		recency, frequency, monetary int
+		want                         string
+	}{
+		{5, 5, 5, RFMChampions},
+		{4, 4, 4, RFMChampions},
+		{3, 4, 2, RFMLoyal},
+		{5, 1, 1, RFMPromising},
+		{1, 4, 5, RFMAtRisk},
+		{1, 1, 1, RFMHibernating},
+		{3, 2, 5, RFMNeedsAttention},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rfmSegment(tt.recency, tt.frequency, tt.monetary), "%d%d%d", tt.recency, tt.frequency, tt.monetary)
+	}
+}
+
+func TestAnalyticsService_GetRFMSummary(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	analyticsRepo := new(MockAnalyticsRepository)
+	analyticsRepo.On("RFMDistribution", from, to).Return([]*repository.RFMCell{
+		{RecencyScore: 5, FrequencyScore: 5, MonetaryScore: 4, Members: 10},
+		{RecencyScore: 4, FrequencyScore: 4, MonetaryScore: 5, Members: 5},
+		{RecencyScore: 1, FrequencyScore: 1, MonetaryScore: 2, Members: 7},
+	}, nil)
+
+	service := NewAnalyticsService(analyticsRepo, config.LoyaltyConfig{})
+	summary, err := service.GetRFMSummary(from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(22), summary.Members)
+	assert.Equal(t, int64(15), summary.Segments[RFMChampions])
+	assert.Equal(t, int64(7), summary.Segments[RFMHibernating])
+	assert.Equal(t, "554", summary.Cells[0].Score)
+
+	_, err = service.GetRFMSummary(to, from)
+	assert.Error(t, err)
+}
+
+func TestBuildCohortCurves(t *testing.T) {
+	now := time.Date(2024, time.April, 10, 0, 0, 0, 0, time.UTC)
+	rows := []*domain.CohortRetention{
+		{Cohort: "2024-01", Month: "2024-01", MonthOffset: 0, CohortSize: 100, ActiveMembers: 60},
+		{Cohort: "2024-01", Month: "2024-03", MonthOffset: 2, CohortSize: 100, ActiveMembers: 25},
+		{Cohort: "2024-03", Month: "2024-03", MonthOffset: 0, CohortSize: 0},
+	}
+
+	curves := buildCohortCurves(rows, now)
+
+	assert.Len(t, curves, 2)
+	january := curves[0]
+	assert.Equal(t, 100, january.Size)
+	assert.Len(t, january.Retention, 4)
+	assert.Equal(t, 0.6, january.Retention[0].Rate)
+	assert.Equal(t, "2024-02", january.Retention[1].Month)
+	assert.Equal(t, 0, january.Retention[1].ActiveMembers)
+	assert.Equal(t, 0.25, january.Retention[2].Rate)
+
+	march := curves[1]
+	assert.Len(t, march.Retention, 2)
+	assert.Equal(t, 0.0, march.Retention[0].Rate)
+}
+
+func TestAnalyticsService_RefreshRollups(t *testing.T) {
+	now := time.Date(2024, time.June, 12, 3, 0, 0, 0, time.UTC)
+	latest := time.Date(2024, time.June, 11, 0, 0, 0, 0, time.UTC)
+
+	t.Run("restates recent activity and values the liability", func(t *testing.T) {
+		analyticsRepo := new(MockAnalyticsRepository)
+		analyticsRepo.On("LatestActivityDay").Return(&latest, nil)
+		analyticsRepo.On("RebuildActivity", latest.AddDate(0, 0, -analyticsRestateDays)).Return(nil)
+		analyticsRepo.On("RebuildCohorts").Return(nil)
+		analyticsRepo.On("Outstanding", now, liabilityExpiringWindow).Return(&repository.PointsOutstanding{Points: 123456, Members: 80, Expiring: 500}, nil)
+		analyticsRepo.On("RecordLiability", mock.MatchedBy(func(l *domain.PointsLiability) bool {
+			return l.Day.Equal(latest.AddDate(0, 0, 1)) && l.OutstandingPoints == 123456 && l.Liability == 1234.56 && l.ExpiringPoints == 500
+		})).Return(nil)
+
+		service := NewAnalyticsService(analyticsRepo, config.LoyaltyConfig{PointValue: 0.01})
+		err := service.RefreshRollups(now)
+
+		assert.NoError(t, err)
+		analyticsRepo.AssertExpectations(t)
+	})
+
+	t.Run("builds activity from scratch when empty", func(t *testing.T) {
+		analyticsRepo := new(MockAnalyticsRepository)
+		analyticsRepo.On("LatestActivityDay").Return(nil, nil)
+		analyticsRepo.On("RebuildActivity", time.Time{}).Return(nil)
+		analyticsRepo.On("RebuildCohorts").Return(nil)
+		analyticsRepo.On("Outstanding", now, liabilityExpiringWindow).Return(&repository.PointsOutstanding{}, nil)
+		analyticsRepo.On("RecordLiability", mock.Anything).Return(nil)
+
+		service := NewAnalyticsService(analyticsRepo, config.LoyaltyConfig{PointValue: 0.01})
+		err := service.RefreshRollups(now)
+
+		assert.NoError(t, err)
+		analyticsRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ApprovalService interface {
+	Submit(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error)
+	Approve(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error)
+	Reject(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error)
+	History(entityType, entityID string) ([]*domain.ApprovalEvent, error)
+	ListPending() (*PendingApprovals, error)
+}
+
+// PendingApprovals are the items waiting for a reviewer, oldest first.
+type PendingApprovals struct {
+	Campaigns []*domain.Campaign `json:"campaigns"`
+	Coupons   []*domain.Coupon   `json:"coupons"`
+}
+
+type approvalService struct {
+	approvalRepo repository.ApprovalRepository
+	campaignRepo repository.CampaignRepository
+	couponRepo   repository.CouponRepository
+}
+
+func NewApprovalService(approvalRepo repository.ApprovalRepository, campaignRepo repository.CampaignRepository, couponRepo repository.CouponRepository) ApprovalService {
+	return &approvalService{
+		approvalRepo: approvalRepo,
+		campaignRepo: campaignRepo,
+		couponRepo:   couponRepo,
+	}
+}
+
+// Submit sends a draft or rejected item for review.
+func (s *approvalService) Submit(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error) {
+	if _, _, err := s.makers(entityType, entityID); err != nil {
+		return nil, err
+	}
+	return s.transition(entityType, entityID, actorID, comment, domain.ApprovalPending, domain.ApprovalDraft, domain.ApprovalRejected)
+}
+
+// Approve publishes an item in review. The approver must be someone other
+// than the item's creator and whoever submitted it.
+func (s *approvalService) Approve(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error) {
+	if err := s.checkReviewer(entityType, entityID, actorID); err != nil {
+		return nil, err
+	}
+	return s.transition(entityType, entityID, actorID, comment, domain.ApprovalApproved, domain.ApprovalPending)
+}
+
+// Reject sends an item in review back to its maker, who can edit and
+// resubmit it. A comment explaining why is required.
+func (s *approvalService) Reject(entityType, entityID, actorID, comment string) (*domain.ApprovalEvent, error) {
+	if comment == "" {
+		return nil, errors.New("a comment is required to reject")
+	}
+	if err := s.checkReviewer(entityType, entityID, actorID); err != nil {
+		return nil, err
+	}
+	return s.transition(entityType, entityID, actorID, comment, domain.ApprovalRejected, domain.ApprovalPending)
+}
+
+func (s *approvalService) History(entityType, entityID string) ([]*domain.ApprovalEvent, error) {
+	if _, _, err := s.makers(entityType, entityID); err != nil {
+		return nil, err
+	}
+	return s.approvalRepo.History(entityType, entityID)
+}
+
+func (s *approvalService) ListPending() (*PendingApprovals, error) {
+	campaigns, err := s.approvalRepo.ListPendingCampaigns()
+	if err != nil {
+		return nil, err
+	}
+	coupons, err := s.approvalRepo.ListPendingCoupons()
+	if err != nil {
+		return nil, err
+	}
+	return &PendingApprovals{Campaigns: campaigns, Coupons: coupons}, nil
+}
+
+func (s *approvalService) transition(entityType, entityID, actorID, comment, to string, from ...string) (*domain.ApprovalEvent, error) {
+	id, err := uuid.Parse(entityID)
+	if err != nil {
+		return nil, errors.New("invalid item id")
+	}
+	actor, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, errors.New("invalid user id")
+	}
+
+	event := &domain.ApprovalEvent{
+		EntityType: entityType,
+		EntityID:   id,
+		ToStatus:   to,
+		ActorID:    &actor,
+		Comment:    comment,
+	}
+	if err := s.approvalRepo.Transition(event, from...); err != nil {
+		if errors.Is(err, repository.ErrInvalidApprovalTransition) {
+			return nil, errors.New("item is not in a state that allows this")
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// makers looks up the item and returns who created it and who last edited
+// it, if known.
+func (s *approvalService) makers(entityType, entityID string) (createdBy, updatedBy *uuid.UUID, err error) {
+	switch entityType {
+	case domain.ApprovalEntityCampaign:
+		campaign, err := s.campaignRepo.FindByID(entityID)
+		if err != nil {
+			return nil, nil, errors.New("campaign not found")
+		}
+		return campaign.CreatedBy, campaign.UpdatedBy, nil
+	case domain.ApprovalEntityCoupon:
+		coupon, err := s.couponRepo.FindByID(entityID)
+		if err != nil {
+			return nil, nil, errors.New("coupon not found")
+		}
+		return coupon.CreatedBy, nil, nil
+	}
+	return nil, nil, errors.New("unknown approval item type")
+}
+
+// checkReviewer enforces the maker-checker rule: nobody reviews an item
+// they created, last edited or submitted themselves.
+func (s *approvalService) checkReviewer(entityType, entityID, actorID string) error {
+	createdBy, updatedBy, err := s.makers(entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if createdBy != nil && createdBy.String() == actorID {
+		return errors.New("items cannot be reviewed by their creator")
+	}
+	if updatedBy != nil && updatedBy.String() == actorID {
+		return errors.New("items cannot be reviewed by their last editor")
+	}
+
+	history, err := s.approvalRepo.History(entityType, entityID)
+	if err != nil {
+		return err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].ToStatus != domain.ApprovalPending {
+			continue
+		}
+		if history[i].ActorID != nil && history[i].ActorID.String() == actorID {
+			return errors.New("items cannot be reviewed by their submitter")
+		}
+		break
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApprovalRepository struct {
+	mock.Mock
+}
+
+func (m *MockApprovalRepository) Transition(event *domain.ApprovalEvent, from ...string) error {
+	args := m.Called(event, from)
+	return args.Error(0)
+}
+
+func (m *MockApprovalRepository) History(entityType, entityID string) ([]*domain.ApprovalEvent, error) {
+	args := m.Called(entityType, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ApprovalEvent), args.Error(1)
+}
+
+func (m *MockApprovalRepository) ListPendingCampaigns() ([]*domain.Campaign, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Campaign), args.Error(1)
+}
+
+func (m *MockApprovalRepository) ListPendingCoupons() ([]*domain.Coupon, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Coupon), args.Error(1)
+}
+
+func TestApprovalService_Approve(t *testing.T) {
+	maker := uuid.New()
+	editor := uuid.New()
+	submitter := uuid.New()
+	reviewer := uuid.New()
+	campaignID := uuid.New()
+
+	tests := []struct {
+		name          string
+		actor         uuid.UUID
+		transitionErr error
+		expectedError string
+	}{
+		{
+			name:  "different reviewer approves",
+			actor: reviewer,
+		},
+		{
+			name:          "creator cannot approve",
+			actor:         maker,
+			expectedError: "items cannot be reviewed by their creator",
+		},
+		{
+			name:          "last editor cannot approve",
+			actor:         editor,
+			expectedError: "items cannot be reviewed by their last editor",
+		},
+		{
+			name:          "submitter cannot approve",
+			actor:         submitter,
+			expectedError: "items cannot be reviewed by their submitter",
+		},
+		{
+			name:          "item not in review",
+			actor:         reviewer,
+			transitionErr: repository.ErrInvalidApprovalTransition,
+			expectedError: "item is not in a state that allows this",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approvalRepo := new(MockApprovalRepository)
+			campaignRepo := new(MockCampaignRepository)
+			service := NewApprovalService(approvalRepo, campaignRepo, new(MockCouponRepository))
+
+			campaignRepo.On("FindByID", campaignID.String()).Return(&domain.Campaign{ID: campaignID, CreatedBy: &maker, UpdatedBy: &editor}, nil)
+			approvalRepo.On("History", domain.ApprovalEntityCampaign, campaignID.String()).Return([]*domain.ApprovalEvent{
+				{ToStatus: domain.ApprovalDraft, ActorID: &maker},
+				{ToStatus: domain.ApprovalPending, ActorID: &submitter},
+			}, nil)
+			approvalRepo.On("Transition", mock.MatchedBy(func(event *domain.ApprovalEvent) bool {
+				return event.ToStatus == domain.ApprovalApproved && *event.ActorID == tt.actor
+			}), []string{domain.ApprovalPending}).Return(tt.transitionErr)
+
+			event, err := service.Approve(domain.ApprovalEntityCampaign, campaignID.String(), tt.actor.String(), "looks good")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, campaignID, event.EntityID)
+			assert.Equal(t, "looks good", event.Comment)
+		})
+	}
+}
+
+func TestApprovalService_Reject_RequiresComment(t *testing.T) {
+	service := NewApprovalService(new(MockApprovalRepository), new(MockCampaignRepository), new(MockCouponRepository))
+
+	_, err := service.Reject(domain.ApprovalEntityCoupon, uuid.New().String(), uuid.New().String(), "")
+
+	assert.EqualError(t, err, "a comment is required to reject")
+}
+
+func TestApprovalService_Submit(t *testing.T) {
+	approvalRepo := new(MockApprovalRepository)
+	couponRepo := new(MockCouponRepository)
+	service := NewApprovalService(approvalRepo, new(MockCampaignRepository), couponRepo)
+
+	couponID := uuid.New()
+	actor := uuid.New()
+	couponRepo.On("FindByID", couponID.String()).Return(&domain.Coupon{ID: couponID}, nil)
+	approvalRepo.On("Transition", mock.AnythingOfType("*domain.ApprovalEvent"),
+		[]string{domain.ApprovalDraft, domain.ApprovalRejected}).Return(nil)
+
+	event, err := service.Submit(domain.ApprovalEntityCoupon, couponID.String(), actor.String(), "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalPending, event.ToStatus)
+	assert.Equal(t, actor, *event.ActorID)
+}
+
+func TestHighValueCoupon(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupon   domain.Coupon
+		expected bool
+	}{
+		{"small fixed discount", domain.Coupon{Type: "fixed", Discount: 10}, false},
+		{"large fixed discount", domain.Coupon{Type: "fixed", Discount: 50}, true},
+		{"capped percentage", domain.Coupon{Type: "percentage", Discount: 20, MaxDiscount: 25}, false},
+		{"uncapped percentage", domain.Coupon{Type: "percentage", Discount: 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, highValueCoupon(&tt.coupon, 50))
+		})
+	}
+}
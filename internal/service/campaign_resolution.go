@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+// OfferResolution is the set of campaigns that apply to a basket, and why
+// every other active campaign does not.
+type OfferResolution struct {
+	PurchaseAmount float64          `json:"purchase_amount"`
+	Points         int              `json:"points"`
+	Discount       float64          `json:"discount"`
+	Chosen         []*ResolvedOffer `json:"chosen"`
+	Skipped        []*SkippedOffer  `json:"skipped"`
+}
+
+// ResolvedOffer is a campaign that applies. Value is what it gives the
+// member in currency, with points valued at the configured point value.
+type ResolvedOffer struct {
+	CampaignID uuid.UUID  `json:"campaign_id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Group      string     `json:"group,omitempty"`
+	Result     float64    `json:"result"`
+	Points     int        `json:"points"`
+	Discount   float64    `json:"discount"`
+	Value      float64    `json:"value"`
+	VariantID  *uuid.UUID `json:"variant_id,omitempty"`
+}
+
+// SkippedOffer is a campaign that does not apply. Status is the campaign
+// rejection status, or outranked when another campaign in its group won.
+type SkippedOffer struct {
+	CampaignID uuid.UUID `json:"campaign_id"`
+	Name       string    `json:"name"`
+	Group      string    `json:"group,omitempty"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason"`
+}
+
+// campaignOutranked is the status of campaigns that lost to another
+// campaign in their group.
+const campaignOutranked = "outranked"
+
+// campaignCandidate is a campaign that applies to the basket on its own.
+type campaignCandidate struct {
+	Campaign *domain.Campaign
+	Outcome  *campaignOutcome
+	Value    float64
+}
+
+// validCampaignPolicy reports whether policy is a known group policy.
+func validCampaignPolicy(policy string) bool {
+	switch policy {
+	case domain.CampaignPolicyHighest, domain.CampaignPolicyAdditive, domain.CampaignPolicyPriority:
+		return true
+	}
+	return false
+}
+
+// evaluateOffers evaluates every campaign against the basket and resolves
+// overlapping campaigns by their group policies. Campaigns outside a group
+// always combine.
+func evaluateOffers(campaigns []*domain.Campaign, ctx *campaignContext, policies map[string]string, pointValue float64) ([]*campaignCandidate, []*SkippedOffer) {
+	var candidates []*campaignCandidate
+	var skipped []*SkippedOffer
+	for _, campaign := range campaigns {
+		outcome, err := evaluateCampaign(campaign, ctx)
+		if err != nil {
+			skip := &SkippedOffer{CampaignID: campaign.ID, Name: campaign.Name, Group: campaign.Group, Status: "error", Reason: err.Error()}
+			var rejection *campaignRejection
+			if errors.As(err, &rejection) {
+				skip.Status = rejection.Status
+			}
+			skipped = append(skipped, skip)
+			continue
+		}
+		candidates = append(candidates, &campaignCandidate{
+			Campaign: campaign,
+			Outcome:  outcome,
+			Value:    float64(outcome.Points)*pointValue + outcome.Discount,
+		})
+	}
+
+	chosen, outranked := resolveOffers(candidates, policies)
+	return chosen, append(skipped, outranked...)
+}
+
+// resolveOffers picks the candidates that apply. In a highest group the
+// candidate with the most value wins, ties going to the lower priority
+// number; in a priority group the lowest priority number wins, ties going
+// to the most value; in an additive group every candidate applies. Chosen
+// candidates keep their original order.
+func resolveOffers(candidates []*campaignCandidate, policies map[string]string) ([]*campaignCandidate, []*SkippedOffer) {
+	winners := make(map[string]*campaignCandidate)
+	for _, candidate := range candidates {
+		group := candidate.Campaign.Group
+		if group == "" || groupPolicy(policies, group) == domain.CampaignPolicyAdditive {
+			continue
+		}
+		if best, ok := winners[group]; !ok || beats(candidate, best, groupPolicy(policies, group)) {
+			winners[group] = candidate
+		}
+	}
+
+	var chosen []*campaignCandidate
+	var skipped []*SkippedOffer
+	for _, candidate := range candidates {
+		group := candidate.Campaign.Group
+		winner, contested := winners[group]
+		if !contested || winner == candidate {
+			chosen = append(chosen, candidate)
+			continue
+		}
+
+		reason := fmt.Sprintf("%s gives more in group %s", winner.Campaign.Name, group)
+		if groupPolicy(policies, group) == domain.CampaignPolicyPriority {
+			reason = fmt.Sprintf("%s has a higher priority in group %s", winner.Campaign.Name, group)
+		}
+		skipped = append(skipped, &SkippedOffer{
+			CampaignID: candidate.Campaign.ID,
+			Name:       candidate.Campaign.Name,
+			Group:      group,
+			Status:     campaignOutranked,
+			Reason:     reason,
+		})
+	}
+	return chosen, skipped
+}
+
+// campaignPolicies loads the policy of every campaign group.
+func campaignPolicies(campaignRepo repository.CampaignRepository) (map[string]string, error) {
+	groups, err := campaignRepo.ListGroups()
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]string, len(groups))
+	for _, group := range groups {
+		policies[group.Name] = group.Policy
+	}
+	return policies, nil
+}
+
+func groupPolicy(policies map[string]string, group string) string {
+	if policy, ok := policies[group]; ok {
+		return policy
+	}
+	return domain.CampaignPolicyHighest
+}
+
+// beats reports whether a wins over b under policy. Earlier candidates win
+// full ties.
+func beats(a, b *campaignCandidate, policy string) bool {
+	if policy == domain.CampaignPolicyPriority {
+		if a.Campaign.Priority != b.Campaign.Priority {
+			return a.Campaign.Priority < b.Campaign.Priority
+		}
+		return a.Value > b.Value
+	}
+	if a.Value != b.Value {
+		return a.Value > b.Value
+	}
+	return a.Campaign.Priority < b.Campaign.Priority
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+// campaignContext is what campaign rules are evaluated against.
+type campaignContext struct {
+	User           *domain.User
+	Tiers          []*domain.Tier
+	PurchaseAmount float64
+	Now            time.Time
+	Segments       map[uuid.UUID]bool         // the member's membership of targeted segments
+	Arms           map[uuid.UUID]*campaignArm // the member's experiment arm per campaign
+	Enrolled       map[uuid.UUID]bool         // campaigns requiring enrollment the member enrolled in
+	Types          *CampaignTypeRegistry
+}
+
+// campaignArm is the experiment arm a member is in for a campaign: the
+// control group, a variant, or neither for the campaign as defined.
+type campaignArm struct {
+	Control bool
+	Variant *domain.CampaignVariant
+}
+
+// campaignOutcome is the result of a campaign that applies to a purchase.
+// Points is what the member earns after tier benefits, Discount what is
+// taken off the order and Result the raw campaign value.
+type campaignOutcome struct {
+	Result    float64
+	Points    int
+	Discount  float64
+	VariantID *uuid.UUID
+}
+
+// campaignRejection explains why a campaign does not apply. Status is used
+// as the campaign usage metric label.
+type campaignRejection struct {
+	Status string
+	Reason string
+}
+
+func (e *campaignRejection) Error() string {
+	return e.Reason
+}
+
+// evaluateCampaign checks campaign against the purchase and computes what it
+// yields. It has no side effects, so it is shared by every path that earns
+// campaign points.
+func evaluateCampaign(campaign *domain.Campaign, ctx *campaignContext) (*campaignOutcome, error) {
+	arm := ctx.Arms[campaign.ID]
+	if arm != nil && arm.Variant != nil {
+		campaign = withVariant(campaign, arm.Variant)
+	}
+
+	// Validate campaign status
+	if !campaign.IsActive {
+		return nil, &campaignRejection{Status: "inactive", Reason: "campaign is not active"}
+	}
+	if campaign.ApprovalStatus != domain.ApprovalApproved {
+		return nil, &campaignRejection{Status: "not_approved", Reason: "campaign has not been approved"}
+	}
+
+	// Validate dates
+	if ctx.Now.Before(campaign.StartDate) || ctx.Now.After(campaign.EndDate) {
+		return nil, &campaignRejection{Status: "expired", Reason: "campaign is not valid for current date"}
+	}
+
+	// Validate the recurring window, in the campaign's zone
+	schedule, err := parseCampaignSchedule(campaign)
+	if err != nil {
+		return nil, &campaignRejection{Status: "invalid_schedule", Reason: "invalid campaign schedule"}
+	}
+	if schedule != nil && !schedule.activeAt(ctx.Now) {
+		return nil, &campaignRejection{Status: "outside_schedule", Reason: "campaign is not running at this time"}
+	}
+
+	// Validate the member opted in
+	if campaign.EnrollmentRequired && !ctx.Enrolled[campaign.ID] {
+		return nil, &campaignRejection{Status: "not_enrolled", Reason: "member is not enrolled in this campaign"}
+	}
+
+	// Validate the target segment
+	if campaign.SegmentID != nil && !ctx.Segments[*campaign.SegmentID] {
+		return nil, &campaignRejection{Status: "segment_not_eligible", Reason: "member is not in the campaign's target segment"}
+	}
+
+	// Parse and validate conditions
+	var conditions map[string]interface{}
+	if campaign.Conditions != "" {
+		if err := json.Unmarshal([]byte(campaign.Conditions), &conditions); err != nil {
+			return nil, &campaignRejection{Status: "invalid_conditions", Reason: "invalid campaign conditions"}
+		}
+
+		// Check minimum purchase amount if specified
+		if minAmount, ok := conditions["min_purchase"].(float64); ok {
+			if ctx.PurchaseAmount < minAmount {
+				return nil, &campaignRejection{Status: "min_purchase_not_met", Reason: "purchase amount does not meet campaign requirements"}
+			}
+		}
+
+		if !meetsTierRequirement(ctx.Tiers, ctx.User.Tier, conditions) {
+			return nil, &campaignRejection{Status: "tier_not_eligible", Reason: "membership tier is not eligible for this campaign"}
+		}
+	}
+
+	// Calculate points or discount with the campaign's type
+	campaignType, ok := ctx.Types.Lookup(campaign.Type)
+	if !ok {
+		return nil, &campaignRejection{Status: "invalid_type", Reason: "invalid campaign type"}
+	}
+	yield, err := campaignType.Evaluate(campaign, &CampaignInput{
+		User:           ctx.User,
+		PurchaseAmount: ctx.PurchaseAmount,
+		TierMultiplier: tierMultiplier(ctx.Tiers, ctx.User.Tier),
+		Conditions:     conditions,
+	})
+	if err != nil {
+		return nil, &campaignRejection{Status: "type_not_eligible", Reason: err.Error()}
+	}
+	outcome := &campaignOutcome{Result: yield.Result, Points: yield.Points, Discount: yield.Discount}
+
+	// Withhold the campaign from the holdout group last, so the group only
+	// holds members who would otherwise have qualified. A stored experiment
+	// arm takes precedence over the member's current bucket.
+	control := campaign.HoldoutPercent > 0 && inHoldout(campaign.ID, ctx.User.ID, campaign.HoldoutPercent)
+	if arm != nil {
+		control = arm.Control
+	}
+	if control {
+		return nil, &campaignRejection{Status: campaignHoldout, Reason: "member is in the campaign's holdout group"}
+	}
+
+	if arm != nil && arm.Variant != nil {
+		outcome.VariantID = &arm.Variant.ID
+	}
+	return outcome, nil
+}
+
+// campaignHoldout is the rejection status of members in a holdout group.
+const campaignHoldout = "holdout"
+
+// experimentBucket puts members into one of 100 buckets per campaign by
+// hashing their ids, so a member always lands in the same bucket.
+func experimentBucket(campaignID, userID uuid.UUID) int {
+	h := fnv.New32a()
+	h.Write(campaignID[:])
+	h.Write(userID[:])
+	return int(h.Sum32() % 100)
+}
+
+// inHoldout reports whether the member falls in a holdout of percent.
+func inHoldout(campaignID, userID uuid.UUID, percent int) bool {
+	return experimentBucket(campaignID, userID) < percent
+}
+
+// assignArm picks the member's arm from their bucket. The lowest buckets
+// form the holdout, the rest are split between the variants by weight.
+func assignArm(campaign *domain.Campaign, variants []*domain.CampaignVariant, userID uuid.UUID) *campaignArm {
+	bucket := experimentBucket(campaign.ID, userID)
+	if bucket < campaign.HoldoutPercent {
+		return &campaignArm{Control: true}
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total == 0 {
+		return &campaignArm{}
+	}
+
+	position := (bucket - campaign.HoldoutPercent) * total / (100 - campaign.HoldoutPercent)
+	for _, variant := range variants {
+		if position < variant.Weight {
+			return &campaignArm{Variant: variant}
+		}
+		position -= variant.Weight
+	}
+	return &campaignArm{Variant: variants[len(variants)-1]}
+}
+
+// withVariant returns a copy of the campaign with the variant's overrides.
+func withVariant(campaign *domain.Campaign, variant *domain.CampaignVariant) *domain.Campaign {
+	effective := *campaign
+	if variant.Value != nil {
+		effective.Value = *variant.Value
+	}
+	if variant.Conditions != "" {
+		effective.Conditions = variant.Conditions
+	}
+	return &effective
+}
+
+// campaignArms resolves the member's experiment arm for every campaign with
+// a holdout or variants. The first assignment is stored and reused, so
+// changing the split later does not move members between arms.
+func campaignArms(experimentRepo repository.ExperimentRepository, campaigns []*domain.Campaign, userID uuid.UUID) (map[uuid.UUID]*campaignArm, error) {
+	byCampaign, err := experimentVariants(experimentRepo, campaigns)
+	if err != nil {
+		return nil, err
+	}
+
+	arms := make(map[uuid.UUID]*campaignArm)
+	for _, campaign := range campaigns {
+		campaignVariants, ok := byCampaign[campaign.ID]
+		if !ok {
+			continue
+		}
+
+		arm := assignArm(campaign, campaignVariants, userID)
+		assignment := &domain.ExperimentAssignment{CampaignID: campaign.ID, UserID: userID, Control: arm.Control}
+		if arm.Variant != nil {
+			assignment.VariantID = &arm.Variant.ID
+		}
+		stored, err := experimentRepo.Assign(assignment)
+		if err != nil {
+			return nil, err
+		}
+		arms[campaign.ID] = storedArm(stored, campaignVariants)
+	}
+	return arms, nil
+}
+
+// previewArms resolves the member's experiment arms like campaignArms but
+// stores nothing: members not yet assigned get the arm they would be
+// assigned, so looking at offers does not count as exposure.
+func previewArms(experimentRepo repository.ExperimentRepository, campaigns []*domain.Campaign, userID uuid.UUID) (map[uuid.UUID]*campaignArm, error) {
+	byCampaign, err := experimentVariants(experimentRepo, campaigns)
+	if err != nil {
+		return nil, err
+	}
+	arms := make(map[uuid.UUID]*campaignArm)
+	if len(byCampaign) == 0 {
+		return arms, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(byCampaign))
+	for _, campaign := range campaigns {
+		if _, ok := byCampaign[campaign.ID]; ok {
+			ids = append(ids, campaign.ID)
+		}
+	}
+	assignments, err := experimentRepo.ListAssignments(userID, ids...)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[uuid.UUID]*domain.ExperimentAssignment)
+	for _, assignment := range assignments {
+		stored[assignment.CampaignID] = assignment
+	}
+
+	for _, campaign := range campaigns {
+		campaignVariants, ok := byCampaign[campaign.ID]
+		if !ok {
+			continue
+		}
+		if assignment, ok := stored[campaign.ID]; ok {
+			arms[campaign.ID] = storedArm(assignment, campaignVariants)
+		} else {
+			arms[campaign.ID] = assignArm(campaign, campaignVariants, userID)
+		}
+	}
+	return arms, nil
+}
+
+// experimentVariants loads the variants of the campaigns running an
+// experiment, keyed by campaign. Campaigns with a holdout but no variants
+// map to an empty list; campaigns without an experiment are left out.
+func experimentVariants(experimentRepo repository.ExperimentRepository, campaigns []*domain.Campaign) (map[uuid.UUID][]*domain.CampaignVariant, error) {
+	ids := make([]uuid.UUID, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		ids = append(ids, campaign.ID)
+	}
+	variants, err := experimentRepo.ListVariants(ids...)
+	if err != nil {
+		return nil, err
+	}
+	byCampaign := make(map[uuid.UUID][]*domain.CampaignVariant)
+	for _, variant := range variants {
+		byCampaign[variant.CampaignID] = append(byCampaign[variant.CampaignID], variant)
+	}
+	for _, campaign := range campaigns {
+		if _, ok := byCampaign[campaign.ID]; !ok && campaign.HoldoutPercent > 0 {
+			byCampaign[campaign.ID] = nil
+		}
+	}
+	return byCampaign, nil
+}
+
+// storedArm is the arm an assignment puts the member in.
+func storedArm(assignment *domain.ExperimentAssignment, variants []*domain.CampaignVariant) *campaignArm {
+	arm := &campaignArm{Control: assignment.Control}
+	if assignment.VariantID != nil {
+		for _, variant := range variants {
+			if variant.ID == *assignment.VariantID {
+				arm.Variant = variant
+			}
+		}
+	}
+	return arm
+}
+
+// campaignEnrollments looks up which of the campaigns requiring enrollment
+// the member is enrolled in.
+func campaignEnrollments(campaignRepo repository.CampaignRepository, campaigns []*domain.Campaign, userID string) (map[uuid.UUID]bool, error) {
+	enrolled := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, campaign := range campaigns {
+		if campaign.EnrollmentRequired {
+			ids = append(ids, campaign.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return enrolled, nil
+	}
+
+	found, err := campaignRepo.EnrolledIn(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		enrolled[id] = true
+	}
+	return enrolled, nil
+}
+
+// campaignEarnKey identifies the points a campaign earned for an order, so an
+// order never earns the same campaign twice, whichever path applied it.
+func campaignEarnKey(campaignID uuid.UUID, orderRef string) string {
+	return fmt.Sprintf("campaign:%s:order:%s", campaignID, orderRef)
+}
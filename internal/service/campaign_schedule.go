@@ -0,0 +1,291 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/domain"
+)
+
+// CampaignOccurrence is a window in which a campaign runs, in the
+// campaign's zone.
+type CampaignOccurrence struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// maxScheduleDays bounds how far ahead occurrences are searched, so a rule
+// that never matches cannot loop forever.
+const maxScheduleDays = 3 * 366
+
+// campaignSchedule is a campaign's recurrence rule. It supports the RRULE
+// parts FREQ (DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY, BYMONTHDAY,
+// BYHOUR and BYMINUTE. Intervals count from the start date, and parts that
+// are left out default to the start date's weekday, day, hour and minute,
+// as in iCalendar.
+type campaignSchedule struct {
+	freq       string
+	interval   int
+	byDay      map[time.Weekday]bool
+	byMonthDay map[int]bool
+	byHour     []int
+	byMinute   []int
+	window     time.Duration
+	location   *time.Location
+	start      time.Time // in location
+	end        time.Time
+}
+
+var ruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// campaignLocation is the zone the campaign's windows are in. Campaigns
+// without a zone predate zones and run in UTC.
+func campaignLocation(campaign *domain.Campaign) (*time.Location, error) {
+	if campaign.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(campaign.Timezone)
+	if err != nil {
+		return nil, errors.New("invalid timezone")
+	}
+	return loc, nil
+}
+
+// parseCampaignSchedule parses the campaign's recurrence rule. Campaigns
+// without one run for their whole date range and have no schedule.
+func parseCampaignSchedule(campaign *domain.Campaign) (*campaignSchedule, error) {
+	if campaign.Recurrence == "" {
+		return nil, nil
+	}
+	loc, err := campaignLocation(campaign)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.WindowMinutes <= 0 {
+		return nil, errors.New("recurring campaigns need window minutes")
+	}
+
+	s := &campaignSchedule{
+		interval: 1,
+		window:   time.Duration(campaign.WindowMinutes) * time.Minute,
+		location: loc,
+		start:    campaign.StartDate.In(loc),
+		end:      campaign.EndDate,
+	}
+
+	rule := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(campaign.Recurrence)), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return nil, errors.New("recurrence FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			s.freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, errors.New("recurrence INTERVAL must be a positive number")
+			}
+			s.interval = n
+		case "BYDAY":
+			s.byDay = make(map[time.Weekday]bool)
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := ruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("invalid recurrence BYDAY %q", day)
+				}
+				s.byDay[weekday] = true
+			}
+		case "BYMONTHDAY":
+			days, err := ruleNumbers(value, 1, 31)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recurrence BYMONTHDAY: %w", err)
+			}
+			s.byMonthDay = make(map[int]bool)
+			for _, day := range days {
+				s.byMonthDay[day] = true
+			}
+		case "BYHOUR":
+			if s.byHour, err = ruleNumbers(value, 0, 23); err != nil {
+				return nil, fmt.Errorf("invalid recurrence BYHOUR: %w", err)
+			}
+		case "BYMINUTE":
+			if s.byMinute, err = ruleNumbers(value, 0, 59); err != nil {
+				return nil, fmt.Errorf("invalid recurrence BYMINUTE: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence part %s", key)
+		}
+	}
+	if s.freq == "" {
+		return nil, errors.New("recurrence needs a FREQ")
+	}
+
+	if s.freq == "WEEKLY" && s.byDay == nil {
+		s.byDay = map[time.Weekday]bool{s.start.Weekday(): true}
+	}
+	if s.freq == "MONTHLY" && s.byDay == nil && s.byMonthDay == nil {
+		s.byMonthDay = map[int]bool{s.start.Day(): true}
+	}
+	if s.byHour == nil {
+		s.byHour = []int{s.start.Hour()}
+	}
+	if s.byMinute == nil {
+		s.byMinute = []int{s.start.Minute()}
+	}
+	return s, nil
+}
+
+// ruleNumbers parses a comma separated list of numbers in [min, max], sorted.
+func ruleNumbers(value string, min, max int) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < min || n > max {
+			return nil, fmt.Errorf("%q is not between %d and %d", field, min, max)
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// matchesDay reports whether the rule has occurrences on day, a local
+// midnight on or after the start date.
+func (s *campaignSchedule) matchesDay(day time.Time) bool {
+	switch s.freq {
+	case "DAILY":
+		if civilDays(s.start, day)%s.interval != 0 {
+			return false
+		}
+	case "WEEKLY":
+		monday := s.start.AddDate(0, 0, -((int(s.start.Weekday()) + 6) % 7))
+		if civilDays(monday, day)/7%s.interval != 0 {
+			return false
+		}
+	case "MONTHLY":
+		months := (day.Year()-s.start.Year())*12 + int(day.Month()-s.start.Month())
+		if months%s.interval != 0 {
+			return false
+		}
+	}
+	if s.byDay != nil && !s.byDay[day.Weekday()] {
+		return false
+	}
+	if s.byMonthDay != nil && !s.byMonthDay[day.Day()] {
+		return false
+	}
+	return true
+}
+
+// occurrences lists the windows overlapping [from, to), at most limit of
+// them when limit is positive. Windows are cut off at the campaign's end
+// date.
+func (s *campaignSchedule) occurrences(from, to time.Time, limit int) []*CampaignOccurrence {
+	if to.After(s.end) {
+		to = s.end
+	}
+	scan := from.Add(-s.window)
+	if scan.Before(s.start) {
+		scan = s.start
+	}
+	scan = scan.In(s.location)
+
+	var occurrences []*CampaignOccurrence
+	day := time.Date(scan.Year(), scan.Month(), scan.Day(), 0, 0, 0, 0, s.location)
+	for i := 0; i < maxScheduleDays && day.Before(to); i++ {
+		if s.matchesDay(day) {
+			for _, hour := range s.byHour {
+				for _, minute := range s.byMinute {
+					start := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, s.location)
+					if start.Before(s.start) || !start.Before(to) {
+						continue
+					}
+					end := start.Add(s.window)
+					if end.After(s.end) {
+						end = s.end.In(s.location)
+					}
+					if !end.After(from) {
+						continue
+					}
+					occurrences = append(occurrences, &CampaignOccurrence{Start: start, End: end})
+					if limit > 0 && len(occurrences) == limit {
+						return occurrences
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return occurrences
+}
+
+// activeAt reports whether t falls in one of the windows.
+func (s *campaignSchedule) activeAt(t time.Time) bool {
+	return len(s.occurrences(t, t.Add(time.Nanosecond), 1)) > 0
+}
+
+// civilDays counts the calendar days from a to b, ignoring the time of day
+// and daylight saving changes.
+func civilDays(a, b time.Time) int {
+	from := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// campaignRunning reports whether the campaign is within its date range
+// and, if it recurs, inside one of its windows at now.
+func campaignRunning(campaign *domain.Campaign, now time.Time) (bool, error) {
+	if now.Before(campaign.StartDate) || now.After(campaign.EndDate) {
+		return false, nil
+	}
+	schedule, err := parseCampaignSchedule(campaign)
+	if err != nil {
+		return false, err
+	}
+	return schedule == nil || schedule.activeAt(now), nil
+}
+
+// campaignOccurrences lists the campaign's next windows from now. A campaign
+// without a recurrence rule has a single window, its date range.
+func campaignOccurrences(campaign *domain.Campaign, now time.Time, limit int) ([]*CampaignOccurrence, error) {
+	schedule, err := parseCampaignSchedule(campaign)
+	if err != nil {
+		return nil, err
+	}
+	if schedule != nil {
+		occurrences := schedule.occurrences(now, campaign.EndDate, limit)
+		if occurrences == nil {
+			occurrences = []*CampaignOccurrence{}
+		}
+		return occurrences, nil
+	}
+
+	loc, err := campaignLocation(campaign)
+	if err != nil {
+		return nil, err
+	}
+	if !campaign.EndDate.After(now) {
+		return []*CampaignOccurrence{}, nil
+	}
+	return []*CampaignOccurrence{{Start: campaign.StartDate.In(loc), End: campaign.EndDate.In(loc)}}, nil
+}
@@ -3,46 +3,137 @@ package service
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"time"
 
+	"github.com/gclub/internal/config"
 	"github.com/gclub/internal/domain"
 	"github.com/gclub/internal/middleware"
 	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
 )
 
 type CampaignService interface {
 	CreateCampaign(campaign *domain.Campaign) error
 	GetCampaignByID(id string) (*domain.Campaign, error)
-	UpdateCampaign(campaign *domain.Campaign) error
+	UpdateCampaign(campaign *domain.Campaign, isActive *bool) error
 	DeleteCampaign(id string) error
+	CloneCampaign(id string, params TemplateParams, createdBy *uuid.UUID) (*domain.Campaign, error)
 	ListActiveCampaigns() ([]*domain.Campaign, error)
+	ListOccurrences(id string, limit int) ([]*CampaignOccurrence, error)
+	Participate(campaignID, userID string) (*domain.CampaignEnrollment, error)
+	ParticipationHistory(userID string, page, pageSize int) ([]*repository.CampaignParticipation, int64, error)
+	GetAllowance(campaignID, userID string) (*CampaignAllowance, error)
 	GetCampaignsByType(campaignType string) ([]*domain.Campaign, error)
-	ApplyCampaign(campaignID string, userID string, purchaseAmount float64) (float64, error)
+	ListCampaignTypes() []CampaignTypeInfo
+	ApplyCampaign(campaignID string, userID string, orderRef string, purchaseAmount float64) (*CampaignResult, error)
+	GetCampaignReport(id string) (*CampaignReport, error)
+	ResolveOffers(userID string, purchaseAmount float64) (*OfferResolution, error)
+	ListCampaignGroups() ([]*domain.CampaignGroup, error)
+	SaveCampaignGroup(group *domain.CampaignGroup) error
+}
+
+// CampaignResult describes the outcome of applying a campaign to a purchase.
+type CampaignResult struct {
+	Result       float64            `json:"result"`
+	PointsEarned int                `json:"points_earned"`
+	Balance      int                `json:"balance"`
+	Allowance    *CampaignAllowance `json:"allowance"`
+	Duplicate    bool               `json:"duplicate"`
+}
+
+// CampaignAllowance is what a member has left of a campaign's per-member
+// caps. Uncapped limits are nil.
+type CampaignAllowance struct {
+	ApplicationsToday    *int `json:"applications_today"`
+	ApplicationsThisWeek *int `json:"applications_this_week"`
+	Applications         *int `json:"applications"`
+	Points               *int `json:"points"`
+}
+
+// CampaignReport is the performance of a campaign. Cost values the points
+// issued at the configured point value and adds the discounts given.
+// Incremental revenue compares revenue per member with the holdout group and
+// is only reported for campaigns with a holdout.
+type CampaignReport struct {
+	CampaignID         uuid.UUID                             `json:"campaign_id"`
+	Name               string                                `json:"name"`
+	Type               string                                `json:"type"`
+	StartDate          time.Time                             `json:"start_date"`
+	EndDate            time.Time                             `json:"end_date"`
+	Applications       int64                                 `json:"applications"`
+	Members            int64                                 `json:"members"`
+	PointsIssued       int64                                 `json:"points_issued"`
+	DiscountIssued     float64                               `json:"discount_issued"`
+	Revenue            float64                               `json:"revenue"`
+	RevenuePerMember   float64                               `json:"revenue_per_member"`
+	Cost               float64                               `json:"cost"`
+	CostPerAcquisition float64                               `json:"cost_per_acquisition"`
+	Holdout            *repository.CampaignApplicationTotals `json:"holdout,omitempty"`
+	IncrementalRevenue *float64                              `json:"incremental_revenue,omitempty"`
+	ROI                *float64                              `json:"roi,omitempty"`
+	Days               []*repository.CampaignApplicationDay  `json:"days"`
+}
+
+// CampaignObserver is told about every campaign successfully applied to an
+// order through ApplyCampaign.
+type CampaignObserver interface {
+	CampaignApplied(campaign *domain.Campaign, user *domain.User, orderRef string)
 }
 
 type campaignService struct {
-	campaignRepo repository.CampaignRepository
+	campaignRepo   repository.CampaignRepository
+	userRepo       repository.UserRepository
+	pointsRepo     repository.PointsRepository
+	tierRepo       repository.TierRepository
+	segmentRepo    repository.SegmentRepository
+	experimentRepo repository.ExperimentRepository
+	types          *CampaignTypeRegistry
+	loyalty        config.LoyaltyConfig
+	observers      []CampaignObserver
 }
 
-func NewCampaignService(campaignRepo repository.CampaignRepository) CampaignService {
-	return &campaignService{campaignRepo: campaignRepo}
+func NewCampaignService(campaignRepo repository.CampaignRepository, userRepo repository.UserRepository, pointsRepo repository.PointsRepository, tierRepo repository.TierRepository, segmentRepo repository.SegmentRepository, experimentRepo repository.ExperimentRepository, types *CampaignTypeRegistry, loyalty config.LoyaltyConfig, observers ...CampaignObserver) CampaignService {
+	return &campaignService{
+		campaignRepo:   campaignRepo,
+		userRepo:       userRepo,
+		pointsRepo:     pointsRepo,
+		tierRepo:       tierRepo,
+		segmentRepo:    segmentRepo,
+		experimentRepo: experimentRepo,
+		types:          types,
+		loyalty:        loyalty,
+		observers:      observers,
+	}
 }
 
 func (s *campaignService) CreateCampaign(campaign *domain.Campaign) error {
+	if err := validateCampaign(campaign, s.types, s.loyalty, s.segmentRepo); err != nil {
+		return err
+	}
+	campaign.EnrolledCount = 0
+
+	// New campaigns only go live once approved
+	campaign.ApprovalStatus = domain.ApprovalDraft
+
+	return s.campaignRepo.Create(campaign)
+}
+
+// validateCampaign checks a campaign definition and fills in its defaults.
+func validateCampaign(campaign *domain.Campaign, types *CampaignTypeRegistry, loyalty config.LoyaltyConfig, segmentRepo repository.SegmentRepository) error {
 	// Validate campaign dates
 	if campaign.StartDate.After(campaign.EndDate) {
 		return errors.New("start date must be before end date")
 	}
 
-	// Validate campaign type
-	validTypes := map[string]bool{
-		"points_multiplier": true,
-		"special_offer":     true,
-		"bonus_points":      true,
-	}
-	if !validTypes[campaign.Type] {
+	// Validate campaign type and its configuration
+	campaignType, ok := types.Lookup(campaign.Type)
+	if !ok {
 		return errors.New("invalid campaign type")
 	}
+	if err := campaignType.ValidateConfig(campaign); err != nil {
+		return err
+	}
 
 	// Validate conditions JSON
 	if campaign.Conditions != "" {
@@ -52,14 +143,81 @@ func (s *campaignService) CreateCampaign(campaign *domain.Campaign) error {
 		}
 	}
 
-	return s.campaignRepo.Create(campaign)
+	// Validate the zone and recurrence rule
+	if campaign.Timezone == "" {
+		campaign.Timezone = loyalty.DefaultTimezone
+	}
+	if _, err := campaignLocation(campaign); err != nil {
+		return err
+	}
+	if _, err := parseCampaignSchedule(campaign); err != nil {
+		return err
+	}
+
+	if campaign.HoldoutPercent < 0 || campaign.HoldoutPercent > 99 {
+		return errors.New("holdout percent must be between 0 and 99")
+	}
+
+	// Validate per-member caps
+	if campaign.MaxApplicationsPerDay < 0 || campaign.MaxApplicationsPerWeek < 0 ||
+		campaign.MaxApplicationsPerMember < 0 || campaign.MaxPointsPerMember < 0 {
+		return errors.New("campaign caps must not be negative")
+	}
+
+	// Validate enrollment settings
+	if campaign.EnrollmentCap < 0 {
+		return errors.New("enrollment cap must not be negative")
+	}
+	if campaign.EnrollmentOpensAt != nil && campaign.EnrollmentClosesAt != nil &&
+		!campaign.EnrollmentOpensAt.Before(*campaign.EnrollmentClosesAt) {
+		return errors.New("enrollment must open before it closes")
+	}
+
+	// Validate the target segment
+	if campaign.SegmentID != nil {
+		if _, err := segmentRepo.FindByID(campaign.SegmentID.String()); err != nil {
+			return errors.New("target segment not found")
+		}
+	}
+	return nil
 }
 
 func (s *campaignService) GetCampaignByID(id string) (*domain.Campaign, error) {
 	return s.campaignRepo.FindByID(id)
 }
 
-func (s *campaignService) UpdateCampaign(campaign *domain.Campaign) error {
+// UpdateCampaign saves changes to a draft or rejected campaign. Campaigns in
+// review cannot change, and approved campaigns can only be paused or resumed,
+// so what goes live is always what was approved. isActive is nil when the
+// request leaves the campaign's active flag as it is.
+func (s *campaignService) UpdateCampaign(campaign *domain.Campaign, isActive *bool) error {
+	existing, err := s.campaignRepo.FindByID(campaign.ID.String())
+	if err != nil {
+		return errors.New("campaign not found")
+	}
+
+	switch existing.ApprovalStatus {
+	case domain.ApprovalPending:
+		return errors.New("campaign is in review and cannot be changed")
+	case domain.ApprovalApproved:
+		if isActive == nil {
+			return nil
+		}
+		existing.IsActive = *isActive
+		return s.campaignRepo.Update(existing)
+	}
+
+	if err := validateCampaign(campaign, s.types, s.loyalty, s.segmentRepo); err != nil {
+		return err
+	}
+	campaign.IsActive = existing.IsActive
+	if isActive != nil {
+		campaign.IsActive = *isActive
+	}
+	campaign.ApprovalStatus = existing.ApprovalStatus
+	campaign.CreatedBy = existing.CreatedBy
+	campaign.EnrolledCount = existing.EnrolledCount
+	campaign.CreatedAt = existing.CreatedAt
 	return s.campaignRepo.Update(campaign)
 }
 
@@ -67,65 +225,455 @@ func (s *campaignService) DeleteCampaign(id string) error {
 	return s.campaignRepo.Delete(id)
 }
 
+// CloneCampaign copies a campaign into a new draft starting at
+// params.StartDate. The end date defaults to keep the campaign's length, and
+// the enrollment window moves with it. Experiment variants are not copied.
+func (s *campaignService) CloneCampaign(id string, params TemplateParams, createdBy *uuid.UUID) (*domain.Campaign, error) {
+	original, err := s.campaignRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	if params.StartDate == nil {
+		return nil, errors.New("start date is required")
+	}
+
+	clone := *original
+	clone.ID = uuid.Nil
+	clone.CreatedBy = createdBy
+	clone.UpdatedBy = nil
+	clone.CreatedAt = time.Time{}
+	clone.UpdatedAt = time.Time{}
+	if params.NameSuffix != "" {
+		clone.Name = original.Name + " " + params.NameSuffix
+	}
+	if params.Value != nil {
+		clone.Value = *params.Value
+	}
+
+	shift := params.StartDate.Sub(original.StartDate)
+	clone.StartDate = *params.StartDate
+	clone.EndDate = original.EndDate.Add(shift)
+	if params.EndDate != nil {
+		clone.EndDate = *params.EndDate
+	}
+	clone.EnrollmentOpensAt = shiftTime(original.EnrollmentOpensAt, shift)
+	clone.EnrollmentClosesAt = shiftTime(original.EnrollmentClosesAt, shift)
+
+	if err := s.CreateCampaign(&clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+func shiftTime(t *time.Time, by time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+	shifted := t.Add(by)
+	return &shifted
+}
+
+// ListActiveCampaigns lists the campaigns running now. Recurring campaigns
+// are only listed inside one of their windows.
 func (s *campaignService) ListActiveCampaigns() ([]*domain.Campaign, error) {
-	return s.campaignRepo.ListActive()
+	campaigns, err := s.campaignRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	running := make([]*domain.Campaign, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		if ok, err := campaignRunning(campaign, now); err == nil && ok {
+			running = append(running, campaign)
+		}
+	}
+	return running, nil
+}
+
+// ListOccurrences lists the next windows of a campaign, at most limit.
+func (s *campaignService) ListOccurrences(id string, limit int) ([]*CampaignOccurrence, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	campaign, err := s.campaignRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	return campaignOccurrences(campaign, time.Now(), limit)
 }
 
 func (s *campaignService) GetCampaignsByType(campaignType string) ([]*domain.Campaign, error) {
 	return s.campaignRepo.FindByType(campaignType)
 }
 
-func (s *campaignService) ApplyCampaign(campaignID string, userID string, purchaseAmount float64) (float64, error) {
+// Participate enrolls the member in the campaign while its enrollment window
+// is open and it has room.
+func (s *campaignService) Participate(campaignID, userID string) (*domain.CampaignEnrollment, error) {
+	campaign, err := s.campaignRepo.FindByID(campaignID)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	now := time.Now()
+	if !campaign.IsActive || !now.Before(campaign.EndDate) {
+		return nil, errors.New("campaign is not active")
+	}
+	if campaign.EnrollmentOpensAt != nil && now.Before(*campaign.EnrollmentOpensAt) {
+		return nil, errors.New("enrollment has not opened yet")
+	}
+	if campaign.EnrollmentClosesAt != nil && !now.Before(*campaign.EnrollmentClosesAt) {
+		return nil, errors.New("enrollment has closed")
+	}
+
+	enrollment := &domain.CampaignEnrollment{CampaignID: campaign.ID, UserID: user.ID, EnrolledAt: now}
+	if err := s.campaignRepo.Enroll(enrollment); err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (s *campaignService) ParticipationHistory(userID string, page, pageSize int) ([]*repository.CampaignParticipation, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.campaignRepo.ListParticipation(userID, (page-1)*pageSize, pageSize)
+}
+
+func (s *campaignService) ApplyCampaign(campaignID string, userID string, orderRef string, purchaseAmount float64) (*CampaignResult, error) {
 	campaign, err := s.campaignRepo.FindByID(campaignID)
 	if err != nil {
 		middleware.RecordCampaignUsage("unknown", "not_found")
-		return 0, errors.New("campaign not found")
+		return nil, errors.New("campaign not found")
 	}
 
-	// Validate campaign status
-	if !campaign.IsActive {
-		middleware.RecordCampaignUsage(campaign.Type, "inactive")
-		return 0, errors.New("campaign is not active")
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		middleware.RecordCampaignUsage(campaign.Type, "user_not_found")
+		return nil, errors.New("user not found")
 	}
 
-	// Validate dates
-	now := time.Now()
-	if now.Before(campaign.StartDate) || now.After(campaign.EndDate) {
-		middleware.RecordCampaignUsage(campaign.Type, "expired")
-		return 0, errors.New("campaign is not valid for current date")
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		middleware.RecordCampaignUsage(campaign.Type, "error")
+		return nil, err
 	}
 
-	// Parse and validate conditions
-	var conditions map[string]interface{}
-	if campaign.Conditions != "" {
-		if err := json.Unmarshal([]byte(campaign.Conditions), &conditions); err != nil {
-			middleware.RecordCampaignUsage(campaign.Type, "invalid_conditions")
-			return 0, errors.New("invalid campaign conditions")
+	now := time.Now()
+	var segments map[uuid.UUID]bool
+	if campaign.SegmentID != nil {
+		segments, err = segmentMemberships(s.segmentRepo, []uuid.UUID{*campaign.SegmentID}, userID, now)
+		if err != nil {
+			middleware.RecordCampaignUsage(campaign.Type, "error")
+			return nil, err
 		}
+	}
+
+	// Resolve the member's experiment arm
+	arms, err := campaignArms(s.experimentRepo, []*domain.Campaign{campaign}, user.ID)
+	if err != nil {
+		middleware.RecordCampaignUsage(campaign.Type, "error")
+		return nil, err
+	}
+	enrolled, err := campaignEnrollments(s.campaignRepo, []*domain.Campaign{campaign}, userID)
+	if err != nil {
+		middleware.RecordCampaignUsage(campaign.Type, "error")
+		return nil, err
+	}
 
-		// Check minimum purchase amount if specified
-		if minAmount, ok := conditions["min_purchase"].(float64); ok {
-			if purchaseAmount < minAmount {
-				middleware.RecordCampaignUsage(campaign.Type, "min_purchase_not_met")
-				return 0, errors.New("purchase amount does not meet campaign requirements")
+	outcome, err := evaluateCampaign(campaign, &campaignContext{
+		User:           user,
+		Tiers:          tiers,
+		PurchaseAmount: purchaseAmount,
+		Now:            now,
+		Segments:       segments,
+		Arms:           arms,
+		Enrolled:       enrolled,
+		Types:          s.types,
+	})
+	if err != nil {
+		var rejection *campaignRejection
+		if errors.As(err, &rejection) {
+			middleware.RecordCampaignUsage(campaign.Type, rejection.Status)
+			if rejection.Status == campaignHoldout {
+				if recordErr := s.campaignRepo.RecordApplication(&domain.CampaignApplication{
+					CampaignID:     campaign.ID,
+					OrderRef:       orderRef,
+					UserID:         user.ID,
+					Holdout:        true,
+					PurchaseAmount: purchaseAmount,
+					AppliedAt:      now,
+				}); recordErr != nil {
+					return nil, recordErr
+				}
 			}
 		}
+		return nil, err
 	}
 
-	// Calculate points or discount based on campaign type
-	var result float64
-	switch campaign.Type {
-	case "points_multiplier":
-		result = purchaseAmount * campaign.Value
-	case "special_offer":
-		result = campaign.Value
-	case "bonus_points":
-		result = campaign.Value
-	default:
-		middleware.RecordCampaignUsage(campaign.Type, "invalid_type")
-		return 0, errors.New("invalid campaign type")
+	application := &domain.CampaignApplication{
+		CampaignID:     campaign.ID,
+		OrderRef:       orderRef,
+		UserID:         user.ID,
+		VariantID:      outcome.VariantID,
+		PurchaseAmount: purchaseAmount,
+		Points:         outcome.Points,
+		Discount:       outcome.Discount,
+		AppliedAt:      now,
 	}
 
-	middleware.RecordCampaignUsage(campaign.Type, "success")
-	return result, nil
+	// Credit the points to the member within the campaign's caps; repeated
+	// submissions of the same order return the stored application
+	var entry *domain.PointsTransaction
+	if outcome.Points > 0 {
+		key := campaignEarnKey(campaign.ID, orderRef)
+		entry = &domain.PointsTransaction{
+			UserID:         user.ID,
+			Type:           domain.PointsEarn,
+			Points:         outcome.Points,
+			CampaignID:     &campaign.ID,
+			OrderRef:       orderRef,
+			IdempotencyKey: &key,
+		}
+	}
+	usage, balance, replayed, err := s.campaignRepo.Apply(campaign, application, entry)
+	if errors.Is(err, repository.ErrCampaignCapReached) {
+		middleware.RecordCampaignUsage(campaign.Type, "cap_reached")
+		return nil, err
+	}
+	if errors.Is(err, repository.ErrGroupAlreadyApplied) {
+		middleware.RecordCampaignUsage(campaign.Type, campaignOutranked)
+		return nil, err
+	}
+	if err != nil {
+		middleware.RecordCampaignUsage(campaign.Type, "error")
+		return nil, err
+	}
+
+	// A replayed order was already counted and announced the first time
+	if !replayed {
+		middleware.RecordCampaignUsage(campaign.Type, "success")
+		s.notifyApplied(campaign, user, orderRef)
+	}
+	return &CampaignResult{
+		Result:       outcome.Result,
+		PointsEarned: application.Points,
+		Balance:      balance,
+		Allowance:    campaignAllowance(campaign, usage),
+		Duplicate:    replayed,
+	}, nil
+}
+
+// GetAllowance returns what the member has left of the campaign's caps.
+func (s *campaignService) GetAllowance(campaignID, userID string) (*CampaignAllowance, error) {
+	campaign, err := s.campaignRepo.FindByID(campaignID)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	usage, err := s.campaignRepo.Usage(campaign, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return campaignAllowance(campaign, usage), nil
+}
+
+// campaignAllowance subtracts the member's usage from the campaign's caps.
+func campaignAllowance(campaign *domain.Campaign, usage *repository.CampaignUsage) *CampaignAllowance {
+	left := func(max int, used int64) *int {
+		if max <= 0 {
+			return nil
+		}
+		n := max - int(used)
+		if n < 0 {
+			n = 0
+		}
+		return &n
+	}
+	return &CampaignAllowance{
+		ApplicationsToday:    left(campaign.MaxApplicationsPerDay, usage.Day),
+		ApplicationsThisWeek: left(campaign.MaxApplicationsPerWeek, usage.Week),
+		Applications:         left(campaign.MaxApplicationsPerMember, usage.Total),
+		Points:               left(campaign.MaxPointsPerMember, usage.Points),
+	}
+}
+
+// ResolveOffers works out which active campaigns would apply to the member's
+// basket. Nothing is credited or recorded, experiment assignments included.
+func (s *campaignService) ResolveOffers(userID string, purchaseAmount float64) (*OfferResolution, error) {
+	if purchaseAmount < 0 {
+		return nil, errors.New("purchase amount must not be negative")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	campaigns, err := s.campaignRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+	policies, err := campaignPolicies(s.campaignRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var segmentIDs []uuid.UUID
+	for _, campaign := range campaigns {
+		if campaign.SegmentID != nil {
+			segmentIDs = append(segmentIDs, *campaign.SegmentID)
+		}
+	}
+	segments, err := segmentMemberships(s.segmentRepo, segmentIDs, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	arms, err := previewArms(s.experimentRepo, campaigns, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	enrolled, err := campaignEnrollments(s.campaignRepo, campaigns, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	chosen, skipped := evaluateOffers(campaigns, &campaignContext{
+		User:           user,
+		Tiers:          tiers,
+		PurchaseAmount: purchaseAmount,
+		Now:            now,
+		Segments:       segments,
+		Arms:           arms,
+		Enrolled:       enrolled,
+		Types:          s.types,
+	}, policies, s.loyalty.PointValue)
+
+	resolution := &OfferResolution{
+		PurchaseAmount: purchaseAmount,
+		Chosen:         make([]*ResolvedOffer, 0, len(chosen)),
+		Skipped:        skipped,
+	}
+	if resolution.Skipped == nil {
+		resolution.Skipped = []*SkippedOffer{}
+	}
+	for _, candidate := range chosen {
+		discount := candidate.Outcome.Discount
+		resolution.Points += candidate.Outcome.Points
+		resolution.Discount += discount
+		resolution.Chosen = append(resolution.Chosen, &ResolvedOffer{
+			CampaignID: candidate.Campaign.ID,
+			Name:       candidate.Campaign.Name,
+			Type:       candidate.Campaign.Type,
+			Group:      candidate.Campaign.Group,
+			Result:     candidate.Outcome.Result,
+			Points:     candidate.Outcome.Points,
+			Discount:   discount,
+			Value:      roundCurrency(candidate.Value),
+			VariantID:  candidate.Outcome.VariantID,
+		})
+	}
+	return resolution, nil
+}
+
+// ListCampaignTypes describes the registered campaign types.
+func (s *campaignService) ListCampaignTypes() []CampaignTypeInfo {
+	return s.types.Describe()
+}
+
+func (s *campaignService) ListCampaignGroups() ([]*domain.CampaignGroup, error) {
+	return s.campaignRepo.ListGroups()
+}
+
+func (s *campaignService) SaveCampaignGroup(group *domain.CampaignGroup) error {
+	if group.Name == "" {
+		return errors.New("group name is required")
+	}
+	if !validCampaignPolicy(group.Policy) {
+		return errors.New("policy must be highest, additive or priority")
+	}
+	return s.campaignRepo.SaveGroup(group)
+}
+
+func (s *campaignService) GetCampaignReport(id string) (*CampaignReport, error) {
+	campaign, err := s.campaignRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+
+	treated, err := s.campaignRepo.ApplicationTotals(id, false)
+	if err != nil {
+		return nil, err
+	}
+	holdout, err := s.campaignRepo.ApplicationTotals(id, true)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.campaignRepo.ApplicationsByDay(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCampaignReport(campaign, treated, holdout, days, s.loyalty.PointValue), nil
+}
+
+// buildCampaignReport derives the report figures from the application
+// totals of the members who received the campaign and of its holdout group.
+func buildCampaignReport(campaign *domain.Campaign, treated, holdout *repository.CampaignApplicationTotals, days []*repository.CampaignApplicationDay, pointValue float64) *CampaignReport {
+	report := &CampaignReport{
+		CampaignID:     campaign.ID,
+		Name:           campaign.Name,
+		Type:           campaign.Type,
+		StartDate:      campaign.StartDate,
+		EndDate:        campaign.EndDate,
+		Applications:   treated.Applications,
+		Members:        treated.Members,
+		PointsIssued:   treated.Points,
+		DiscountIssued: roundCurrency(treated.Discount),
+		Revenue:        roundCurrency(treated.Revenue),
+		Cost:           roundCurrency(float64(treated.Points)*pointValue + treated.Discount),
+		Days:           days,
+	}
+	if report.Days == nil {
+		report.Days = []*repository.CampaignApplicationDay{}
+	}
+	if treated.Members > 0 {
+		report.RevenuePerMember = roundCurrency(treated.Revenue / float64(treated.Members))
+		report.CostPerAcquisition = roundCurrency(report.Cost / float64(treated.Members))
+	}
+
+	if holdout.Members > 0 {
+		report.Holdout = holdout
+		holdoutPerMember := holdout.Revenue / float64(holdout.Members)
+		incremental := 0.0
+		if treated.Members > 0 {
+			incremental = roundCurrency((treated.Revenue/float64(treated.Members) - holdoutPerMember) * float64(treated.Members))
+		}
+		report.IncrementalRevenue = &incremental
+		if report.Cost > 0 {
+			roi := math.Round((incremental-report.Cost)/report.Cost*10000) / 10000
+			report.ROI = &roi
+		}
+	}
+	return report
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func (s *campaignService) notifyApplied(campaign *domain.Campaign, user *domain.User, orderRef string) {
+	for _, observer := range s.observers {
+		observer.CampaignApplied(campaign, user, orderRef)
+	}
 }
@@ -0,0 +1,886 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCampaignRepository struct {
+	mock.Mock
+}
+
+func (m *MockCampaignRepository) Create(campaign *domain.Campaign) error {
+	args := m.Called(campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) FindByID(id string) (*domain.Campaign, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) Update(campaign *domain.Campaign) error {
+	args := m.Called(campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) ListActive() ([]*domain.Campaign, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) FindByType(campaignType string) ([]*domain.Campaign, error) {
+	args := m.Called(campaignType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) RecordApplication(application *domain.CampaignApplication) error {
+	args := m.Called(application)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) ApplicationTotals(campaignID string, holdout bool) (*repository.CampaignApplicationTotals, error) {
+	args := m.Called(campaignID, holdout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.CampaignApplicationTotals), args.Error(1)
+}
+
+func (m *MockCampaignRepository) ApplicationsByDay(campaignID string) ([]*repository.CampaignApplicationDay, error) {
+	args := m.Called(campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.CampaignApplicationDay), args.Error(1)
+}
+
+func (m *MockCampaignRepository) ListGroups() ([]*domain.CampaignGroup, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CampaignGroup), args.Error(1)
+}
+
+func (m *MockCampaignRepository) SaveGroup(group *domain.CampaignGroup) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) Apply(campaign *domain.Campaign, application *domain.CampaignApplication, entry *domain.PointsTransaction) (*repository.CampaignUsage, int, bool, error) {
+	args := m.Called(campaign, application, entry)
+	if args.Get(0) == nil {
+		return nil, 0, false, args.Error(3)
+	}
+	return args.Get(0).(*repository.CampaignUsage), args.Int(1), args.Bool(2), args.Error(3)
+}
+
+func (m *MockCampaignRepository) Usage(campaign *domain.Campaign, userID string, at time.Time) (*repository.CampaignUsage, error) {
+	args := m.Called(campaign, userID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.CampaignUsage), args.Error(1)
+}
+
+func (m *MockCampaignRepository) Enroll(enrollment *domain.CampaignEnrollment) error {
+	args := m.Called(enrollment)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) EnrolledIn(userID string, campaignIDs []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(userID, campaignIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockCampaignRepository) ListParticipation(userID string, offset, limit int) ([]*repository.CampaignParticipation, int64, error) {
+	args := m.Called(userID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.CampaignParticipation), args.Get(1).(int64), args.Error(2)
+}
+
+func TestCampaignService_ApplyCampaign(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 100}
+	multiplier := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "points_multiplier",
+		Value:          2,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+	}
+	goldOnly := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "bonus_points",
+		Value:          100,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+		Conditions:     `{"min_tier": "gold"}`,
+	}
+	tiers := []*domain.Tier{
+		{Name: "silver", Rank: 1, PointsMultiplier: 1.5},
+		{Name: "gold", Rank: 2, PointsMultiplier: 2},
+	}
+	silverUser := &domain.User{ID: user.ID, Points: 100, Tier: "silver"}
+	offer := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "special_offer",
+		Value:          10,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name        string
+		campaign    *domain.Campaign
+		amount      float64
+		mock        func(campaignRepo *MockCampaignRepository, userRepo *MockUserRepository, pointsRepo *MockPointsRepository, tierRepo *MockTierRepository)
+		wantErr     bool
+		wantPoints  int
+		wantBalance int
+	}{
+		{
+			name:     "points multiplier credits the member",
+			campaign: multiplier,
+			amount:   75.5,
+			mock: func(campaignRepo *MockCampaignRepository, userRepo *MockUserRepository, pointsRepo *MockPointsRepository, tierRepo *MockTierRepository) {
+				campaignRepo.On("FindByID", multiplier.ID.String()).Return(multiplier, nil)
+				userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+				tierRepo.On("List").Return([]*domain.Tier{}, nil)
+				campaignRepo.On("Apply", multiplier, mock.MatchedBy(func(application *domain.CampaignApplication) bool {
+					return application.CampaignID == multiplier.ID && application.OrderRef == "order-1" && application.Points == 151 && !application.Holdout
+				}), mock.MatchedBy(func(entry *domain.PointsTransaction) bool {
+					return entry.UserID == user.ID && entry.Points == 151 && *entry.CampaignID == multiplier.ID &&
+						entry.OrderRef == "order-1" && entry.IdempotencyKey != nil
+				})).Return(&repository.CampaignUsage{Day: 1, Week: 1, Total: 1, Points: 151}, 251, false, nil)
+			},
+			wantPoints:  151,
+			wantBalance: 251,
+		},
+		{
+			name:     "tier multiplier is applied to earned points",
+			campaign: multiplier,
+			amount:   10,
+			mock: func(campaignRepo *MockCampaignRepository, userRepo *MockUserRepository, pointsRepo *MockPointsRepository, tierRepo *MockTierRepository) {
+				campaignRepo.On("FindByID", multiplier.ID.String()).Return(multiplier, nil)
+				userRepo.On("FindByID", user.ID.String()).Return(silverUser, nil)
+				tierRepo.On("List").Return(tiers, nil)
+				campaignRepo.On("Apply", multiplier, mock.Anything, mock.MatchedBy(func(entry *domain.PointsTransaction) bool {
+					return entry.Points == 30
+				})).Return(&repository.CampaignUsage{Day: 1, Week: 1, Total: 1, Points: 30}, 130, false, nil)
+			},
+			wantPoints:  30,
+			wantBalance: 130,
+		},
+		{
+			name:     "member below the required tier",
+			campaign: goldOnly,
+			amount:   10,
+			mock: func(campaignRepo *MockCampaignRepository, userRepo *MockUserRepository, pointsRepo *MockPointsRepository, tierRepo *MockTierRepository) {
+				campaignRepo.On("FindByID", goldOnly.ID.String()).Return(goldOnly, nil)
+				userRepo.On("FindByID", user.ID.String()).Return(silverUser, nil)
+				tierRepo.On("List").Return(tiers, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:     "special offer does not credit points",
+			campaign: offer,
+			amount:   50,
+			mock: func(campaignRepo *MockCampaignRepository, userRepo *MockUserRepository, pointsRepo *MockPointsRepository, tierRepo *MockTierRepository) {
+				campaignRepo.On("FindByID", offer.ID.String()).Return(offer, nil)
+				userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+				tierRepo.On("List").Return([]*domain.Tier{}, nil)
+				campaignRepo.On("Apply", offer, mock.MatchedBy(func(application *domain.CampaignApplication) bool {
+					return application.Discount == offer.Value
+				}), (*domain.PointsTransaction)(nil)).Return(&repository.CampaignUsage{Day: 1, Week: 1, Total: 1}, 100, false, nil)
+			},
+			wantPoints:  0,
+			wantBalance: 100,
+		},
+		{
+			name:     "unknown member",
+			campaign: multiplier,
+			amount:   50,
+			mock: func(campaignRepo *MockCampaignRepository, userRepo *MockUserRepository, pointsRepo *MockPointsRepository, tierRepo *MockTierRepository) {
+				campaignRepo.On("FindByID", multiplier.ID.String()).Return(multiplier, nil)
+				userRepo.On("FindByID", user.ID.String()).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaignRepo := new(MockCampaignRepository)
+			userRepo := new(MockUserRepository)
+			pointsRepo := new(MockPointsRepository)
+			tierRepo := new(MockTierRepository)
+			tt.mock(campaignRepo, userRepo, pointsRepo, tierRepo)
+
+			service := NewCampaignService(campaignRepo, userRepo, pointsRepo, tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+			result, err := service.ApplyCampaign(tt.campaign.ID.String(), user.ID.String(), "order-1", tt.amount)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPoints, result.PointsEarned)
+			assert.Equal(t, tt.wantBalance, result.Balance)
+			pointsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInHoldout(t *testing.T) {
+	campaignID := uuid.New()
+
+	held := 0
+	for i := 0; i < 2000; i++ {
+		userID := uuid.New()
+		first := inHoldout(campaignID, userID, 10)
+		assert.Equal(t, first, inHoldout(campaignID, userID, 10), "assignment must be stable")
+		if first {
+			held++
+		}
+	}
+	assert.InDelta(t, 200, held, 60)
+}
+
+func TestCampaignService_ApplyCampaign_Holdout(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 100}
+	campaign := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "bonus_points",
+		Value:          50,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+		HoldoutPercent: 99,
+	}
+	for !inHoldout(campaign.ID, user.ID, campaign.HoldoutPercent) {
+		user.ID = uuid.New()
+	}
+
+	campaignRepo := new(MockCampaignRepository)
+	userRepo := new(MockUserRepository)
+	tierRepo := new(MockTierRepository)
+	campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+	userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+	tierRepo.On("List").Return([]*domain.Tier{}, nil)
+	campaignRepo.On("RecordApplication", mock.MatchedBy(func(application *domain.CampaignApplication) bool {
+		return application.Holdout && application.Points == 0 && application.PurchaseAmount == 80
+	})).Return(nil)
+
+	experimentRepo := noExperiments()
+	experimentRepo.On("Assign", mock.MatchedBy(func(assignment *domain.ExperimentAssignment) bool {
+		return assignment.Control
+	})).Return(&domain.ExperimentAssignment{CampaignID: campaign.ID, UserID: user.ID, Control: true}, nil)
+
+	service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), tierRepo, new(MockSegmentRepository), experimentRepo, builtinTypes(), config.LoyaltyConfig{})
+	result, err := service.ApplyCampaign(campaign.ID.String(), user.ID.String(), "order-1", 80)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	campaignRepo.AssertExpectations(t)
+}
+
+func TestBuildCampaignReport(t *testing.T) {
+	campaign := &domain.Campaign{ID: uuid.New(), Name: "Double points", Type: "points_multiplier"}
+
+	t.Run("with a holdout group", func(t *testing.T) {
+		treated := &repository.CampaignApplicationTotals{Applications: 120, Members: 100, Points: 20000, Revenue: 6000}
+		holdout := &repository.CampaignApplicationTotals{Applications: 12, Members: 10, Revenue: 450}
+
+		report := buildCampaignReport(campaign, treated, holdout, nil, 0.01)
+
+		assert.Equal(t, 200.0, report.Cost)
+		assert.Equal(t, 2.0, report.CostPerAcquisition)
+		assert.Equal(t, 60.0, report.RevenuePerMember)
+		assert.Equal(t, 1500.0, *report.IncrementalRevenue)
+		assert.Equal(t, 6.5, *report.ROI)
+		assert.NotNil(t, report.Days)
+	})
+
+	t.Run("without a holdout group", func(t *testing.T) {
+		treated := &repository.CampaignApplicationTotals{Applications: 4, Members: 2, Discount: 30, Revenue: 300}
+
+		report := buildCampaignReport(campaign, treated, &repository.CampaignApplicationTotals{}, nil, 0.01)
+
+		assert.Equal(t, 30.0, report.Cost)
+		assert.Equal(t, 15.0, report.CostPerAcquisition)
+		assert.Nil(t, report.Holdout)
+		assert.Nil(t, report.IncrementalRevenue)
+		assert.Nil(t, report.ROI)
+	})
+}
+
+func TestResolveOffers(t *testing.T) {
+	candidate := func(name, group string, priority int, value float64) *campaignCandidate {
+		return &campaignCandidate{
+			Campaign: &domain.Campaign{ID: uuid.New(), Name: name, Group: group, Priority: priority},
+			Outcome:  &campaignOutcome{},
+			Value:    value,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      string
+		wantChosen  []string
+		wantSkipped []string
+		wantReason  string
+	}{
+		{
+			name:        "highest value wins",
+			policy:      domain.CampaignPolicyHighest,
+			wantChosen:  []string{"triple", "welcome"},
+			wantSkipped: []string{"double"},
+			wantReason:  "triple gives more in group weekend",
+		},
+		{
+			name:        "lowest priority number wins",
+			policy:      domain.CampaignPolicyPriority,
+			wantChosen:  []string{"double", "welcome"},
+			wantSkipped: []string{"triple"},
+			wantReason:  "double has a higher priority in group weekend",
+		},
+		{
+			name:       "additive keeps every campaign",
+			policy:     domain.CampaignPolicyAdditive,
+			wantChosen: []string{"double", "triple", "welcome"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []*campaignCandidate{
+				candidate("double", "weekend", 1, 2),
+				candidate("triple", "weekend", 5, 3),
+				candidate("welcome", "", 0, 1),
+			}
+
+			chosen, skipped := resolveOffers(candidates, map[string]string{"weekend": tt.policy})
+
+			var chosenNames []string
+			for _, c := range chosen {
+				chosenNames = append(chosenNames, c.Campaign.Name)
+			}
+			var skippedNames []string
+			for _, s := range skipped {
+				skippedNames = append(skippedNames, s.Name)
+				assert.Equal(t, campaignOutranked, s.Status)
+				assert.Equal(t, tt.wantReason, s.Reason)
+			}
+			assert.Equal(t, tt.wantChosen, chosenNames)
+			assert.Equal(t, tt.wantSkipped, skippedNames)
+		})
+	}
+}
+
+func TestCampaignService_ResolveOffers(t *testing.T) {
+	user := &domain.User{ID: uuid.New()}
+	active := func(name, campaignType string, value float64, conditions string) *domain.Campaign {
+		return &domain.Campaign{
+			ID:             uuid.New(),
+			Name:           name,
+			Type:           campaignType,
+			Value:          value,
+			Group:          "weekend",
+			IsActive:       true,
+			ApprovalStatus: domain.ApprovalApproved,
+			StartDate:      time.Now().Add(-time.Hour),
+			EndDate:        time.Now().Add(time.Hour),
+			Conditions:     conditions,
+		}
+	}
+	double := active("double", "points_multiplier", 2, "")
+	bonus := active("bonus", "bonus_points", 500, "")
+	bigSpender := active("big spender", "bonus_points", 1000, `{"min_purchase": 500}`)
+
+	campaignRepo := new(MockCampaignRepository)
+	userRepo := new(MockUserRepository)
+	tierRepo := new(MockTierRepository)
+	userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+	tierRepo.On("List").Return([]*domain.Tier{}, nil)
+	campaignRepo.On("ListActive").Return([]*domain.Campaign{double, bonus, bigSpender}, nil)
+	campaignRepo.On("ListGroups").Return([]*domain.CampaignGroup{{Name: "weekend", Policy: domain.CampaignPolicyHighest}}, nil)
+
+	service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{PointValue: 0.01})
+	resolution, err := service.ResolveOffers(user.ID.String(), 100)
+
+	assert.NoError(t, err)
+	assert.Len(t, resolution.Chosen, 1)
+	assert.Equal(t, "bonus", resolution.Chosen[0].Name)
+	assert.Equal(t, 500, resolution.Points)
+	assert.Equal(t, 5.0, resolution.Chosen[0].Value)
+	assert.Len(t, resolution.Skipped, 2)
+	statuses := map[string]string{}
+	for _, skipped := range resolution.Skipped {
+		statuses[skipped.Name] = skipped.Status
+	}
+	assert.Equal(t, campaignOutranked, statuses["double"])
+	assert.NotEqual(t, campaignOutranked, statuses["big spender"])
+}
+
+func TestCampaignSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// Double points every Tuesday 17:00-19:00 Berlin time, across the switch
+	// to summer time on 30 March 2025
+	tuesdays := &domain.Campaign{
+		StartDate:     time.Date(2025, time.March, 1, 0, 0, 0, 0, berlin),
+		EndDate:       time.Date(2025, time.May, 1, 0, 0, 0, 0, berlin),
+		Timezone:      "Europe/Berlin",
+		Recurrence:    "FREQ=WEEKLY;BYDAY=TU;BYHOUR=17;BYMINUTE=0",
+		WindowMinutes: 120,
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		running bool
+	}{
+		{"inside the window", time.Date(2025, time.March, 25, 18, 0, 0, 0, berlin), true},
+		{"inside the window after the clock change", time.Date(2025, time.April, 1, 17, 0, 0, 0, berlin), true},
+		{"after the window", time.Date(2025, time.March, 25, 19, 0, 0, 0, berlin), false},
+		{"on another day", time.Date(2025, time.March, 26, 18, 0, 0, 0, berlin), false},
+		{"same instant in UTC is not local time", time.Date(2025, time.March, 25, 18, 30, 0, 0, time.UTC), false},
+		{"before the start date", time.Date(2025, time.February, 25, 18, 0, 0, 0, berlin), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			running, err := campaignRunning(tuesdays, tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.running, running)
+		})
+	}
+
+	t.Run("occurrences keep local time", func(t *testing.T) {
+		occurrences, err := campaignOccurrences(tuesdays, time.Date(2025, time.March, 20, 0, 0, 0, 0, time.UTC), 3)
+		assert.NoError(t, err)
+		assert.Len(t, occurrences, 3)
+		for i, day := range []int{25, 1, 8} {
+			assert.Equal(t, day, occurrences[i].Start.Day())
+			assert.Equal(t, 17, occurrences[i].Start.Hour())
+			assert.Equal(t, 2*time.Hour, occurrences[i].End.Sub(occurrences[i].Start))
+		}
+	})
+
+	t.Run("interval counts from the start date", func(t *testing.T) {
+		fortnightly := *tuesdays
+		fortnightly.Recurrence = "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU"
+		occurrences, err := campaignOccurrences(&fortnightly, fortnightly.StartDate, 3)
+		assert.NoError(t, err)
+		assert.Len(t, occurrences, 3)
+		assert.Equal(t, 14*24*time.Hour, occurrences[1].Start.Sub(occurrences[0].Start))
+		assert.Equal(t, 0, occurrences[0].Start.Hour())
+	})
+
+	t.Run("campaigns without a rule have one window", func(t *testing.T) {
+		plain := &domain.Campaign{StartDate: tuesdays.StartDate, EndDate: tuesdays.EndDate}
+		occurrences, err := campaignOccurrences(plain, tuesdays.StartDate, 10)
+		assert.NoError(t, err)
+		assert.Len(t, occurrences, 1)
+	})
+}
+
+func TestParseCampaignSchedule_Invalid(t *testing.T) {
+	for _, rule := range []string{
+		"BYDAY=TU",
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;BYHOUR=24",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=DAILY;INTERVAL=0",
+	} {
+		_, err := parseCampaignSchedule(&domain.Campaign{Recurrence: rule, WindowMinutes: 60})
+		assert.Error(t, err, rule)
+	}
+
+	_, err := parseCampaignSchedule(&domain.Campaign{Recurrence: "FREQ=DAILY"})
+	assert.Error(t, err, "window minutes are required")
+	_, err = parseCampaignSchedule(&domain.Campaign{Recurrence: "FREQ=DAILY", WindowMinutes: 60, Timezone: "Mars/Olympus"})
+	assert.Error(t, err, "timezone must exist")
+}
+
+func TestCampaignService_ApplyCampaign_Enrollment(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 100}
+	campaign := &domain.Campaign{
+		ID:                 uuid.New(),
+		Type:               "bonus_points",
+		Value:              50,
+		IsActive:           true,
+		ApprovalStatus:     domain.ApprovalApproved,
+		StartDate:          time.Now().Add(-time.Hour),
+		EndDate:            time.Now().Add(time.Hour),
+		EnrollmentRequired: true,
+	}
+
+	campaignRepo := new(MockCampaignRepository)
+	userRepo := new(MockUserRepository)
+	tierRepo := new(MockTierRepository)
+	campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+	campaignRepo.On("EnrolledIn", user.ID.String(), []uuid.UUID{campaign.ID}).Return([]uuid.UUID{}, nil)
+	userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+	tierRepo.On("List").Return([]*domain.Tier{}, nil)
+
+	service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+	result, err := service.ApplyCampaign(campaign.ID.String(), user.ID.String(), "order-1", 80)
+
+	assert.EqualError(t, err, "member is not enrolled in this campaign")
+	assert.Nil(t, result)
+	campaignRepo.AssertExpectations(t)
+}
+
+func TestCampaignService_Participate(t *testing.T) {
+	user := &domain.User{ID: uuid.New()}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	open := func() *domain.Campaign {
+		return &domain.Campaign{
+			ID:                 uuid.New(),
+			IsActive:           true,
+			ApprovalStatus:     domain.ApprovalApproved,
+			StartDate:          past,
+			EndDate:            time.Now().Add(24 * time.Hour),
+			EnrollmentRequired: true,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		campaign func() *domain.Campaign
+		enroll   error
+		wantErr  string
+	}{
+		{
+			name:     "enrolls the member",
+			campaign: open,
+		},
+		{
+			name: "enrollment not open yet",
+			campaign: func() *domain.Campaign {
+				c := open()
+				c.EnrollmentOpensAt = &future
+				return c
+			},
+			wantErr: "enrollment has not opened yet",
+		},
+		{
+			name: "enrollment closed",
+			campaign: func() *domain.Campaign {
+				c := open()
+				c.EnrollmentClosesAt = &past
+				return c
+			},
+			wantErr: "enrollment has closed",
+		},
+		{
+			name: "campaign ended",
+			campaign: func() *domain.Campaign {
+				c := open()
+				c.EndDate = past
+				return c
+			},
+			wantErr: "campaign is not active",
+		},
+		{
+			name:     "enrollment full",
+			campaign: open,
+			enroll:   repository.ErrEnrollmentFull,
+			wantErr:  repository.ErrEnrollmentFull.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := tt.campaign()
+			campaignRepo := new(MockCampaignRepository)
+			userRepo := new(MockUserRepository)
+			campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+			userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+			campaignRepo.On("Enroll", mock.MatchedBy(func(enrollment *domain.CampaignEnrollment) bool {
+				return enrollment.CampaignID == campaign.ID && enrollment.UserID == user.ID
+			})).Return(tt.enroll)
+
+			service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), new(MockTierRepository), new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+			enrollment, err := service.Participate(campaign.ID.String(), user.ID.String())
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, enrollment)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, campaign.ID, enrollment.CampaignID)
+		})
+	}
+}
+
+func TestCampaignService_ApplyCampaign_CapReached(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 100}
+	campaign := &domain.Campaign{
+		ID:                    uuid.New(),
+		Type:                  "bonus_points",
+		Value:                 50,
+		IsActive:              true,
+		ApprovalStatus:        domain.ApprovalApproved,
+		StartDate:             time.Now().Add(-time.Hour),
+		EndDate:               time.Now().Add(time.Hour),
+		MaxApplicationsPerDay: 1,
+	}
+
+	campaignRepo := new(MockCampaignRepository)
+	userRepo := new(MockUserRepository)
+	tierRepo := new(MockTierRepository)
+	campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+	userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+	tierRepo.On("List").Return([]*domain.Tier{}, nil)
+	campaignRepo.On("Apply", campaign, mock.Anything, mock.Anything).Return(nil, 0, false, repository.ErrCampaignCapReached)
+
+	service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+	result, err := service.ApplyCampaign(campaign.ID.String(), user.ID.String(), "order-2", 80)
+
+	assert.ErrorIs(t, err, repository.ErrCampaignCapReached)
+	assert.Nil(t, result)
+}
+
+func TestCampaignService_ApplyCampaign_GroupStacking(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 100}
+	campaign := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "bonus_points",
+		Value:          50,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+		Group:          "spring",
+	}
+
+	campaignRepo := new(MockCampaignRepository)
+	userRepo := new(MockUserRepository)
+	tierRepo := new(MockTierRepository)
+	campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+	userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+	tierRepo.On("List").Return([]*domain.Tier{}, nil)
+	campaignRepo.On("Apply", campaign, mock.Anything, mock.Anything).Return(nil, 0, false, repository.ErrGroupAlreadyApplied)
+
+	observer := &appliedObserver{}
+	service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{}, observer)
+	result, err := service.ApplyCampaign(campaign.ID.String(), user.ID.String(), "order-1", 80)
+
+	assert.ErrorIs(t, err, repository.ErrGroupAlreadyApplied)
+	assert.Nil(t, result)
+	assert.Equal(t, 0, observer.applied)
+}
+
+// appliedObserver counts the campaigns it is told were applied.
+type appliedObserver struct {
+	applied int
+}
+
+func (o *appliedObserver) CampaignApplied(campaign *domain.Campaign, user *domain.User, orderRef string) {
+	o.applied++
+}
+
+func TestCampaignService_ApplyCampaign_Replay(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 150}
+	campaign := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "bonus_points",
+		Value:          50,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name          string
+		replayed      bool
+		applyErr      error
+		wantDuplicate bool
+		wantNotified  int
+		wantErr       error
+	}{
+		{name: "first submission notifies observers", wantNotified: 1},
+		{name: "replayed order is not announced again", replayed: true, wantDuplicate: true},
+		{name: "order applied for another member", applyErr: repository.ErrOrderRefConflict, wantErr: repository.ErrOrderRefConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaignRepo := new(MockCampaignRepository)
+			userRepo := new(MockUserRepository)
+			tierRepo := new(MockTierRepository)
+			campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+			userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+			tierRepo.On("List").Return([]*domain.Tier{}, nil)
+			if tt.applyErr != nil {
+				campaignRepo.On("Apply", campaign, mock.Anything, mock.Anything).Return(nil, 0, false, tt.applyErr)
+			} else {
+				campaignRepo.On("Apply", campaign, mock.Anything, mock.Anything).Return(&repository.CampaignUsage{Total: 1, Points: 50}, 150, tt.replayed, nil)
+			}
+
+			observer := &appliedObserver{}
+			service := NewCampaignService(campaignRepo, userRepo, new(MockPointsRepository), tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{}, observer)
+			result, err := service.ApplyCampaign(campaign.ID.String(), user.ID.String(), "order-1", 80)
+
+			assert.Equal(t, tt.wantNotified, observer.applied)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, result)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDuplicate, result.Duplicate)
+			assert.Equal(t, 150, result.Balance)
+		})
+	}
+}
+
+func TestCampaignAllowance(t *testing.T) {
+	campaign := &domain.Campaign{
+		MaxApplicationsPerDay:  2,
+		MaxApplicationsPerWeek: 5,
+		MaxPointsPerMember:     1000,
+	}
+
+	allowance := campaignAllowance(campaign, &repository.CampaignUsage{Day: 2, Week: 3, Total: 7, Points: 1200})
+
+	assert.Equal(t, 0, *allowance.ApplicationsToday)
+	assert.Equal(t, 2, *allowance.ApplicationsThisWeek)
+	assert.Nil(t, allowance.Applications)
+	assert.Equal(t, 0, *allowance.Points)
+}
+
+func TestCampaignService_CloneCampaign(t *testing.T) {
+	maker := uuid.New()
+	opens := time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC)
+	original := &domain.Campaign{
+		ID:                uuid.New(),
+		Name:              "Black Friday",
+		Type:              "points_multiplier",
+		Value:             2,
+		Timezone:          "UTC",
+		StartDate:         time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC),
+		EndDate:           time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC),
+		EnrollmentOpensAt: &opens,
+		ApprovalStatus:    domain.ApprovalApproved,
+		EnrolledCount:     40,
+	}
+
+	campaignRepo := new(MockCampaignRepository)
+	campaignRepo.On("FindByID", original.ID.String()).Return(original, nil)
+	campaignRepo.On("Create", mock.AnythingOfType("*domain.Campaign")).Return(nil)
+
+	service := NewCampaignService(campaignRepo, new(MockUserRepository), new(MockPointsRepository), new(MockTierRepository), new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+	start := time.Date(2025, 11, 28, 0, 0, 0, 0, time.UTC)
+	value := 3.0
+	clone, err := service.CloneCampaign(original.ID.String(), TemplateParams{StartDate: &start, Value: &value, NameSuffix: "2025"}, &maker)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Black Friday 2025", clone.Name)
+	assert.Equal(t, 3.0, clone.Value)
+	assert.Equal(t, start, clone.StartDate)
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), clone.EndDate)
+	assert.Equal(t, time.Date(2025, 11, 19, 0, 0, 0, 0, time.UTC), *clone.EnrollmentOpensAt)
+	assert.Equal(t, domain.ApprovalDraft, clone.ApprovalStatus)
+	assert.Equal(t, 0, clone.EnrolledCount)
+	assert.Equal(t, &maker, clone.CreatedBy)
+	assert.NotEqual(t, original.ID, clone.ID)
+	assert.Equal(t, "Black Friday", original.Name)
+}
+
+func TestCampaignService_UpdateCampaign_Validates(t *testing.T) {
+	existing := &domain.Campaign{ID: uuid.New(), ApprovalStatus: domain.ApprovalRejected}
+	campaignRepo := new(MockCampaignRepository)
+	campaignRepo.On("FindByID", existing.ID.String()).Return(existing, nil)
+	campaignRepo.On("Update", mock.AnythingOfType("*domain.Campaign")).Return(nil)
+	service := NewCampaignService(campaignRepo, new(MockUserRepository), new(MockPointsRepository), new(MockTierRepository), new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+
+	err := service.UpdateCampaign(&domain.Campaign{ID: existing.ID, Type: "tier_boost", Value: 1}, nil)
+	assert.EqualError(t, err, "invalid campaign type")
+
+	err = service.UpdateCampaign(&domain.Campaign{ID: existing.ID, Type: "bonus_points", Value: 50, Recurrence: "FREQ=HOURLY"}, nil)
+	assert.Error(t, err)
+	campaignRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	assert.NoError(t, service.UpdateCampaign(&domain.Campaign{ID: existing.ID, Type: "bonus_points", Value: 50}, nil))
+	campaignRepo.AssertCalled(t, "Update", mock.MatchedBy(func(c *domain.Campaign) bool {
+		return c.ApprovalStatus == domain.ApprovalRejected
+	}))
+}
+
+func TestCampaignService_UpdateCampaign_Approved(t *testing.T) {
+	paused := false
+
+	tests := []struct {
+		name       string
+		isActive   *bool
+		wantUpdate bool
+	}{
+		{name: "pauses the campaign", isActive: &paused, wantUpdate: true},
+		{name: "leaves the campaign running without is_active", isActive: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &domain.Campaign{ID: uuid.New(), Type: "bonus_points", Value: 50, IsActive: true, ApprovalStatus: domain.ApprovalApproved}
+			campaignRepo := new(MockCampaignRepository)
+			campaignRepo.On("FindByID", existing.ID.String()).Return(existing, nil)
+			campaignRepo.On("Update", existing).Return(nil)
+			service := NewCampaignService(campaignRepo, new(MockUserRepository), new(MockPointsRepository), new(MockTierRepository), new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{})
+
+			err := service.UpdateCampaign(&domain.Campaign{ID: existing.ID, Type: "bonus_points", Value: 500}, tt.isActive)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 50.0, existing.Value)
+			if tt.wantUpdate {
+				assert.False(t, existing.IsActive)
+				campaignRepo.AssertCalled(t, "Update", existing)
+			} else {
+				assert.True(t, existing.IsActive)
+				campaignRepo.AssertNotCalled(t, "Update", mock.Anything)
+			}
+		})
+	}
+}
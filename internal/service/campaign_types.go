@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gclub/internal/domain"
+)
+
+// CampaignType is a kind of campaign. ValidateConfig checks a campaign of the
+// type when it is saved. Evaluate computes what the campaign yields on a
+// purchase once the rules shared by every type (dates, schedule,
+// enrollment, segment and conditions) have passed; an error means the
+// campaign does not apply. Types are registered at startup.
+type CampaignType interface {
+	Describe() CampaignTypeInfo
+	ValidateConfig(campaign *domain.Campaign) error
+	Evaluate(campaign *domain.Campaign, input *CampaignInput) (*CampaignYield, error)
+}
+
+// CampaignTypeInfo describes a campaign type for the admin UI. Schema is the
+// JSON Schema of the campaign fields the type reads.
+type CampaignTypeInfo struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// CampaignInput is the purchase a campaign type evaluates. TierMultiplier is
+// the member's tier points benefit, and Conditions the campaign's parsed
+// conditions, nil when it has none.
+type CampaignInput struct {
+	User           *domain.User
+	PurchaseAmount float64
+	TierMultiplier float64
+	Conditions     map[string]interface{}
+}
+
+// CampaignYield is what a campaign gives on a purchase. Result is the raw
+// campaign value, Points what the member earns after tier benefits and
+// Discount what is taken off the order.
+type CampaignYield struct {
+	Result   float64
+	Points   int
+	Discount float64
+}
+
+// CampaignTypeRegistry holds the campaign types campaigns can use.
+type CampaignTypeRegistry struct {
+	types map[string]CampaignType
+	names []string
+}
+
+// NewCampaignTypeRegistry registers the types, in order. Names must be
+// unique.
+func NewCampaignTypeRegistry(types ...CampaignType) (*CampaignTypeRegistry, error) {
+	registry := &CampaignTypeRegistry{types: make(map[string]CampaignType)}
+	for _, campaignType := range types {
+		name := campaignType.Describe().Name
+		if name == "" {
+			return nil, errors.New("campaign type name is required")
+		}
+		if _, exists := registry.types[name]; exists {
+			return nil, fmt.Errorf("campaign type %s is registered twice", name)
+		}
+		registry.types[name] = campaignType
+		registry.names = append(registry.names, name)
+	}
+	return registry, nil
+}
+
+func (r *CampaignTypeRegistry) Lookup(name string) (CampaignType, bool) {
+	campaignType, ok := r.types[name]
+	return campaignType, ok
+}
+
+// Describe lists the registered types in registration order.
+func (r *CampaignTypeRegistry) Describe() []CampaignTypeInfo {
+	infos := make([]CampaignTypeInfo, 0, len(r.names))
+	for _, name := range r.names {
+		infos = append(infos, r.types[name].Describe())
+	}
+	return infos
+}
+
+// BuiltinCampaignTypes are the campaign types every deployment has.
+func BuiltinCampaignTypes() []CampaignType {
+	return []CampaignType{
+		pointsMultiplierType{},
+		specialOfferType{},
+		bonusPointsType{},
+	}
+}
+
+// campaignConditionsSchema describes the conditions every campaign type
+// supports, stored as a JSON string.
+var campaignConditionsSchema = map[string]interface{}{
+	"type":             "string",
+	"contentMediaType": "application/json",
+	"contentSchema": map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"min_purchase": map[string]interface{}{"type": "number", "minimum": 0, "description": "Smallest purchase amount the campaign applies to"},
+			"min_tier":     map[string]interface{}{"type": "string", "description": "Lowest membership tier eligible"},
+			"tiers":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Only these membership tiers are eligible"},
+		},
+	},
+}
+
+// valueSchema is the schema of a type configured by a positive value alone.
+func valueSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"value"},
+		"properties": map[string]interface{}{
+			"value":      map[string]interface{}{"type": "number", "exclusiveMinimum": 0, "description": description},
+			"conditions": campaignConditionsSchema,
+		},
+	}
+}
+
+// pointsMultiplierType earns value points per currency unit spent.
+type pointsMultiplierType struct{}
+
+func (pointsMultiplierType) Describe() CampaignTypeInfo {
+	return CampaignTypeInfo{
+		Name:        "points_multiplier",
+		Description: "Earns points in proportion to the purchase amount",
+		Schema:      valueSchema("Points per currency unit spent, before tier benefits"),
+	}
+}
+
+func (pointsMultiplierType) ValidateConfig(campaign *domain.Campaign) error {
+	if campaign.Value <= 0 {
+		return errors.New("multiplier must be positive")
+	}
+	return nil
+}
+
+func (pointsMultiplierType) Evaluate(campaign *domain.Campaign, input *CampaignInput) (*CampaignYield, error) {
+	result := input.PurchaseAmount * campaign.Value
+	return &CampaignYield{
+		Result: result,
+		Points: int(math.Floor(result * input.TierMultiplier)),
+	}, nil
+}
+
+// specialOfferType takes value off the order, never more than the order
+// itself.
+type specialOfferType struct{}
+
+func (specialOfferType) Describe() CampaignTypeInfo {
+	return CampaignTypeInfo{
+		Name:        "special_offer",
+		Description: "Takes a fixed amount off the order",
+		Schema:      valueSchema("Discount on the order"),
+	}
+}
+
+func (specialOfferType) ValidateConfig(campaign *domain.Campaign) error {
+	if campaign.Value <= 0 {
+		return errors.New("discount must be positive")
+	}
+	return nil
+}
+
+func (specialOfferType) Evaluate(campaign *domain.Campaign, input *CampaignInput) (*CampaignYield, error) {
+	discount := math.Min(campaign.Value, input.PurchaseAmount)
+	return &CampaignYield{Result: discount, Discount: discount}, nil
+}
+
+// bonusPointsType earns a fixed number of points.
+type bonusPointsType struct{}
+
+func (bonusPointsType) Describe() CampaignTypeInfo {
+	return CampaignTypeInfo{
+		Name:        "bonus_points",
+		Description: "Earns a fixed number of points",
+		Schema:      valueSchema("Points earned, before tier benefits"),
+	}
+}
+
+func (bonusPointsType) ValidateConfig(campaign *domain.Campaign) error {
+	if campaign.Value <= 0 {
+		return errors.New("bonus points must be positive")
+	}
+	return nil
+}
+
+func (bonusPointsType) Evaluate(campaign *domain.Campaign, input *CampaignInput) (*CampaignYield, error) {
+	return &CampaignYield{
+		Result: campaign.Value,
+		Points: int(math.Floor(campaign.Value * input.TierMultiplier)),
+	}, nil
+}
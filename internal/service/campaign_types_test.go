@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// builtinTypes is a registry of the built-in campaign types.
+func builtinTypes() *CampaignTypeRegistry {
+	registry, err := NewCampaignTypeRegistry(BuiltinCampaignTypes()...)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// cashbackType is a campaign type registered outside the built-ins, giving
+// value percent of the purchase back as a discount above a minimum spend.
+type cashbackType struct{}
+
+func (cashbackType) Describe() CampaignTypeInfo {
+	return CampaignTypeInfo{Name: "cashback", Schema: valueSchema("Percent of the purchase given back")}
+}
+
+func (cashbackType) ValidateConfig(campaign *domain.Campaign) error {
+	if campaign.Value <= 0 || campaign.Value > 100 {
+		return errors.New("cashback must be between 0 and 100 percent")
+	}
+	return nil
+}
+
+func (cashbackType) Evaluate(campaign *domain.Campaign, input *CampaignInput) (*CampaignYield, error) {
+	if input.PurchaseAmount < 10 {
+		return nil, errors.New("purchase is too small for cashback")
+	}
+	cashback := input.PurchaseAmount * campaign.Value / 100
+	return &CampaignYield{Result: cashback, Discount: cashback}, nil
+}
+
+func TestCampaignTypeRegistry(t *testing.T) {
+	registry, err := NewCampaignTypeRegistry(append(BuiltinCampaignTypes(), cashbackType{})...)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, info := range registry.Describe() {
+		names = append(names, info.Name)
+		assert.Contains(t, info.Schema["properties"], "value")
+	}
+	assert.Equal(t, []string{"points_multiplier", "special_offer", "bonus_points", "cashback"}, names)
+
+	_, err = NewCampaignTypeRegistry(cashbackType{}, cashbackType{})
+	assert.EqualError(t, err, "campaign type cashback is registered twice")
+}
+
+func TestEvaluateCampaign_Types(t *testing.T) {
+	registry, err := NewCampaignTypeRegistry(append(BuiltinCampaignTypes(), cashbackType{})...)
+	assert.NoError(t, err)
+	tiers := []*domain.Tier{{Name: "gold", Rank: 1, PointsMultiplier: 2}}
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		campaign   domain.Campaign
+		amount     float64
+		expected   *campaignOutcome
+		wantStatus string
+	}{
+		{
+			name:     "points multiplier",
+			campaign: domain.Campaign{Type: "points_multiplier", Value: 1.5},
+			amount:   100,
+			expected: &campaignOutcome{Result: 150, Points: 300},
+		},
+		{
+			name:     "special offer",
+			campaign: domain.Campaign{Type: "special_offer", Value: 5},
+			amount:   100,
+			expected: &campaignOutcome{Result: 5, Discount: 5},
+		},
+		{
+			name:     "special offer is capped at the order amount",
+			campaign: domain.Campaign{Type: "special_offer", Value: 25},
+			amount:   18,
+			expected: &campaignOutcome{Result: 18, Discount: 18},
+		},
+		{
+			name:     "bonus points",
+			campaign: domain.Campaign{Type: "bonus_points", Value: 50},
+			amount:   100,
+			expected: &campaignOutcome{Result: 50, Points: 100},
+		},
+		{
+			name:     "registered type",
+			campaign: domain.Campaign{Type: "cashback", Value: 10},
+			amount:   80,
+			expected: &campaignOutcome{Result: 8, Discount: 8},
+		},
+		{
+			name:       "registered type rejects",
+			campaign:   domain.Campaign{Type: "cashback", Value: 10},
+			amount:     5,
+			wantStatus: "type_not_eligible",
+		},
+		{
+			name:       "unregistered type",
+			campaign:   domain.Campaign{Type: "tier_boost", Value: 1},
+			amount:     100,
+			wantStatus: "invalid_type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := tt.campaign
+			campaign.ID = uuid.New()
+			campaign.IsActive = true
+			campaign.ApprovalStatus = domain.ApprovalApproved
+			campaign.StartDate = now.AddDate(0, 0, -1)
+			campaign.EndDate = now.AddDate(0, 0, 1)
+
+			outcome, err := evaluateCampaign(&campaign, &campaignContext{
+				User:           &domain.User{ID: uuid.New(), Tier: "gold"},
+				Tiers:          tiers,
+				PurchaseAmount: tt.amount,
+				Now:            now,
+				Types:          registry,
+			})
+
+			if tt.wantStatus != "" {
+				var rejection *campaignRejection
+				assert.True(t, errors.As(err, &rejection))
+				assert.Equal(t, tt.wantStatus, rejection.Status)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, outcome)
+		})
+	}
+}
+
+func TestValidateCampaign_TypeConfig(t *testing.T) {
+	registry := builtinTypes()
+
+	err := validateCampaign(&domain.Campaign{Type: "bonus_points", Value: 0}, registry, config.LoyaltyConfig{}, new(MockSegmentRepository))
+	assert.EqualError(t, err, "bonus points must be positive")
+
+	err = validateCampaign(&domain.Campaign{Type: "cashback", Value: 5}, registry, config.LoyaltyConfig{}, new(MockSegmentRepository))
+	assert.EqualError(t, err, "invalid campaign type")
+
+	assert.NoError(t, validateCampaign(&domain.Campaign{Type: "special_offer", Value: 5}, registry, config.LoyaltyConfig{}, new(MockSegmentRepository)))
+}
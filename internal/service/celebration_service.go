@@ -0,0 +1,138 @@
+package service
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type CelebrationService interface {
+	IssueRewards(now time.Time) (int, error)
+}
+
+type celebrationService struct {
+	celebrationRepo repository.CelebrationRepository
+	userRepo        repository.UserRepository
+	loyalty         config.LoyaltyConfig
+}
+
+func NewCelebrationService(celebrationRepo repository.CelebrationRepository, userRepo repository.UserRepository, loyalty config.LoyaltyConfig) CelebrationService {
+	return &celebrationService{
+		celebrationRepo: celebrationRepo,
+		userRepo:        userRepo,
+		loyalty:         loyalty,
+	}
+}
+
+// IssueRewards rewards every member whose birthday or membership anniversary
+// falls on the current day in their own time zone, and returns the number of
+// rewards issued. Rewards are recorded per year, so running it several times
+// a day never rewards a member twice.
+func (s *celebrationService) IssueRewards(now time.Time) (int, error) {
+	const batchSize = 500
+	issued := 0
+	for offset := 0; ; offset += batchSize {
+		users, err := s.userRepo.List(offset, batchSize)
+		if err != nil {
+			return issued, err
+		}
+
+		for _, user := range users {
+			local := now.In(s.location(user))
+
+			if user.Birthdate != nil && s.loyalty.BirthdayReward > 0 {
+				birthdate := user.Birthdate.UTC()
+				if isCelebrationDay(birthdate.Month(), birthdate.Day(), local) {
+					ok, err := s.issue(user, domain.CelebrationBirthday, s.loyalty.BirthdayRewardType, s.loyalty.BirthdayReward, local)
+					if err != nil {
+						return issued, err
+					}
+					if ok {
+						issued++
+					}
+				}
+			}
+
+			joined := user.CreatedAt.In(local.Location())
+			if s.loyalty.AnniversaryReward > 0 && local.Year() > joined.Year() &&
+				isCelebrationDay(joined.Month(), joined.Day(), local) {
+				ok, err := s.issue(user, domain.CelebrationAnniversary, s.loyalty.AnniversaryRewardType, s.loyalty.AnniversaryReward, local)
+				if err != nil {
+					return issued, err
+				}
+				if ok {
+					issued++
+				}
+			}
+		}
+
+		if len(users) < batchSize {
+			return issued, nil
+		}
+	}
+}
+
+func (s *celebrationService) location(user *domain.User) *time.Location {
+	for _, name := range []string{user.Timezone, s.loyalty.DefaultTimezone} {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// isCelebrationDay reports whether the month and day fall on the given local
+// day. Members born on 29 February celebrate on the 28th in common years.
+func isCelebrationDay(month time.Month, day int, local time.Time) bool {
+	if month == time.February && day == 29 && !isLeapYear(local.Year()) {
+		day = 28
+	}
+	return local.Month() == month && local.Day() == day
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func (s *celebrationService) issue(user *domain.User, kind, rewardType string, value float64, local time.Time) (bool, error) {
+	reward := &domain.CelebrationReward{
+		UserID: user.ID,
+		Kind:   kind,
+		Year:   local.Year(),
+	}
+
+	if rewardType == "coupon" {
+		code, err := generateCouponCode("GIFT")
+		if err != nil {
+			return false, err
+		}
+		return s.celebrationRepo.Issue(reward, nil, &domain.Coupon{
+			Code:        code,
+			Description: kind + " reward",
+			Discount:    value,
+			Type:        "fixed",
+			UserID:      &user.ID,
+			Source:      kind,
+			StartDate:   local,
+			EndDate:     local.AddDate(0, 0, s.loyalty.CelebrationCouponValidityDays),
+			UsageLimit:  1,
+			IsActive:    true,
+		})
+	}
+
+	key := kind + ":" + user.ID.String() + ":" + strconv.Itoa(local.Year())
+	return s.celebrationRepo.Issue(reward, &domain.PointsTransaction{
+		UserID:         user.ID,
+		Type:           domain.PointsEarn,
+		Points:         int(math.Floor(value)),
+		Note:           kind + " reward",
+		IdempotencyKey: &key,
+	}, nil)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCelebrationRepository struct {
+	mock.Mock
+}
+
+func (m *MockCelebrationRepository) Issue(reward *domain.CelebrationReward, entry *domain.PointsTransaction, coupon *domain.Coupon) (bool, error) {
+	args := m.Called(reward, entry, coupon)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestCelebrationService_IssueRewards(t *testing.T) {
+	loyalty := config.LoyaltyConfig{
+		DefaultTimezone:    "UTC",
+		BirthdayRewardType: "points",
+		BirthdayReward:     100,
+		AnniversaryReward:  0,
+	}
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+
+	tests := []struct {
+		name    string
+		user    *domain.User
+		now     time.Time
+		loyalty config.LoyaltyConfig
+		want    []string
+	}{
+		{
+			name: "birthday today",
+			user: &domain.User{Birthdate: date(1990, time.May, 17)},
+			now:  time.Date(2025, time.May, 17, 9, 0, 0, 0, time.UTC),
+			want: []string{domain.CelebrationBirthday},
+		},
+		{
+			name: "not the birthday",
+			user: &domain.User{Birthdate: date(1990, time.May, 17)},
+			now:  time.Date(2025, time.May, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "birthday already started in the member's time zone",
+			user: &domain.User{Birthdate: date(1990, time.May, 17), Timezone: "Asia/Tokyo"},
+			now:  time.Date(2025, time.May, 16, 20, 0, 0, 0, time.UTC),
+			want: []string{domain.CelebrationBirthday},
+		},
+		{
+			name: "leap day birthday in a common year",
+			user: &domain.User{Birthdate: date(1992, time.February, 29)},
+			now:  time.Date(2025, time.February, 28, 9, 0, 0, 0, time.UTC),
+			want: []string{domain.CelebrationBirthday},
+		},
+		{
+			name:    "membership anniversary",
+			user:    &domain.User{CreatedAt: time.Date(2023, time.March, 3, 15, 0, 0, 0, time.UTC)},
+			now:     time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC),
+			loyalty: config.LoyaltyConfig{AnniversaryRewardType: "coupon", AnniversaryReward: 10, CelebrationCouponValidityDays: 30},
+			want:    []string{domain.CelebrationAnniversary},
+		},
+		{
+			name:    "no anniversary on the day of joining",
+			user:    &domain.User{CreatedAt: time.Date(2025, time.March, 3, 8, 0, 0, 0, time.UTC)},
+			now:     time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC),
+			loyalty: config.LoyaltyConfig{AnniversaryRewardType: "points", AnniversaryReward: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			celebrationRepo := new(MockCelebrationRepository)
+			userRepo := new(MockUserRepository)
+
+			tt.user.ID = uuid.New()
+			userRepo.On("List", 0, 500).Return([]*domain.User{tt.user}, nil)
+			var kinds []string
+			celebrationRepo.On("Issue", mock.AnythingOfType("*domain.CelebrationReward"), mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					kinds = append(kinds, args.Get(0).(*domain.CelebrationReward).Kind)
+				}).Return(true, nil)
+
+			cfg := tt.loyalty
+			if cfg == (config.LoyaltyConfig{}) {
+				cfg = loyalty
+			}
+			service := NewCelebrationService(celebrationRepo, userRepo, cfg)
+			issued, err := service.IssueRewards(tt.now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.want), issued)
+			assert.Equal(t, tt.want, kinds)
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type ChallengeService interface {
+	CreateChallenge(challenge *domain.Challenge) error
+	UpdateChallenge(challenge *domain.Challenge) error
+	DeleteChallenge(id string) error
+	ListChallenges() ([]*domain.Challenge, error)
+	CreateBadge(badge *domain.Badge) error
+	ListBadges() ([]*domain.Badge, error)
+	GetMemberChallenges(userID string) ([]*MemberChallenge, error)
+	GetMemberBadges(userID string) ([]*domain.MemberBadge, error)
+	PurchaseObserver
+	CampaignObserver
+}
+
+// MemberChallenge is an active challenge with the member's progress on it.
+type MemberChallenge struct {
+	Challenge   *domain.Challenge `json:"challenge"`
+	Progress    int               `json:"progress"`
+	Completed   bool              `json:"completed"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+type challengeService struct {
+	challengeRepo repository.ChallengeRepository
+	tierRepo      repository.TierRepository
+}
+
+func NewChallengeService(challengeRepo repository.ChallengeRepository, tierRepo repository.TierRepository) ChallengeService {
+	return &challengeService{
+		challengeRepo: challengeRepo,
+		tierRepo:      tierRepo,
+	}
+}
+
+func validateChallenge(challenge *domain.Challenge) error {
+	if challenge.Name == "" {
+		return errors.New("challenge name is required")
+	}
+
+	validTypes := map[string]bool{
+		domain.ChallengeVisits:     true,
+		domain.ChallengeStreak:     true,
+		domain.ChallengeCategories: true,
+		domain.ChallengeSpend:      true,
+		domain.ChallengeCampaigns:  true,
+	}
+	if !validTypes[challenge.Type] {
+		return errors.New("invalid challenge type")
+	}
+	if challenge.Type == domain.ChallengeStreak && challenge.Period != "day" && challenge.Period != "week" {
+		return errors.New("streak period must be day or week")
+	}
+
+	if challenge.Goal <= 0 {
+		return errors.New("goal must be positive")
+	}
+	if challenge.RewardPoints < 0 {
+		return errors.New("reward points must not be negative")
+	}
+	if challenge.StartDate.After(challenge.EndDate) {
+		return errors.New("start date must be before end date")
+	}
+
+	if challenge.Conditions != "" {
+		var conditions map[string]interface{}
+		if err := json.Unmarshal([]byte(challenge.Conditions), &conditions); err != nil {
+			return errors.New("invalid conditions JSON")
+		}
+	}
+	return nil
+}
+
+func (s *challengeService) CreateChallenge(challenge *domain.Challenge) error {
+	if err := validateChallenge(challenge); err != nil {
+		return err
+	}
+	return s.challengeRepo.Create(challenge)
+}
+
+func (s *challengeService) UpdateChallenge(challenge *domain.Challenge) error {
+	if err := validateChallenge(challenge); err != nil {
+		return err
+	}
+	return s.challengeRepo.Update(challenge)
+}
+
+func (s *challengeService) DeleteChallenge(id string) error {
+	return s.challengeRepo.Delete(id)
+}
+
+func (s *challengeService) ListChallenges() ([]*domain.Challenge, error) {
+	return s.challengeRepo.List()
+}
+
+func (s *challengeService) CreateBadge(badge *domain.Badge) error {
+	if badge.Name == "" {
+		return errors.New("badge name is required")
+	}
+	return s.challengeRepo.CreateBadge(badge)
+}
+
+func (s *challengeService) ListBadges() ([]*domain.Badge, error) {
+	return s.challengeRepo.ListBadges()
+}
+
+func (s *challengeService) GetMemberChallenges(userID string) ([]*MemberChallenge, error) {
+	challenges, err := s.challengeRepo.ListActive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.challengeRepo.ListProgressByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byChallenge := make(map[string]*domain.ChallengeProgress, len(progress))
+	for _, p := range progress {
+		byChallenge[p.ChallengeID.String()] = p
+	}
+
+	result := make([]*MemberChallenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		item := &MemberChallenge{Challenge: challenge}
+		if p, ok := byChallenge[challenge.ID.String()]; ok {
+			item.Progress = p.Progress
+			item.Completed = p.CompletedAt != nil
+			item.CompletedAt = p.CompletedAt
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (s *challengeService) GetMemberBadges(userID string) ([]*domain.MemberBadge, error) {
+	return s.challengeRepo.ListMemberBadges(userID)
+}
+
+// PurchaseRecorded advances the member's challenges with a purchase and the
+// campaigns it earned.
+func (s *challengeService) PurchaseRecorded(purchase *domain.Purchase, user *domain.User, campaigns []AppliedCampaign) {
+	if err := s.recordPurchase(purchase, user, campaigns); err != nil {
+		log.Printf("Failed to update challenges of member %s: %v", user.ID, err)
+	}
+}
+
+// CampaignApplied advances the member's campaign challenges.
+func (s *challengeService) CampaignApplied(campaign *domain.Campaign, user *domain.User, orderRef string) {
+	if err := s.recordCampaign(campaign.ID.String(), user, orderRef, time.Now()); err != nil {
+		log.Printf("Failed to update challenges of member %s: %v", user.ID, err)
+	}
+}
+
+func (s *challengeService) recordPurchase(purchase *domain.Purchase, user *domain.User, campaigns []AppliedCampaign) error {
+	challenges, err := s.challengeRepo.ListActive(purchase.PurchasedAt)
+	if err != nil {
+		return err
+	}
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		return err
+	}
+
+	for _, challenge := range challenges {
+		if challenge.Type == domain.ChallengeCampaigns {
+			for _, applied := range campaigns {
+				if err := s.advance(challenge, user, tiers, campaignEventKey(applied.CampaignID.String(), purchase.OrderID), func(progress *domain.ChallengeProgress) {
+					advanceChallenge(challenge, progress, purchase.PurchasedAt, 0, nil)
+				}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		conditions, err := parseChallengeConditions(challenge)
+		if err != nil {
+			continue
+		}
+		amount, categories, ok := countedPurchase(conditions, purchase)
+		if !ok {
+			continue
+		}
+		if err := s.advance(challenge, user, tiers, "purchase:"+purchase.OrderID, func(progress *domain.ChallengeProgress) {
+			advanceChallenge(challenge, progress, purchase.PurchasedAt, amount, categories)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *challengeService) recordCampaign(campaignID string, user *domain.User, orderRef string, now time.Time) error {
+	challenges, err := s.challengeRepo.ListActive(now)
+	if err != nil {
+		return err
+	}
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		return err
+	}
+
+	for _, challenge := range challenges {
+		if challenge.Type != domain.ChallengeCampaigns {
+			continue
+		}
+		if err := s.advance(challenge, user, tiers, campaignEventKey(campaignID, orderRef), func(progress *domain.ChallengeProgress) {
+			advanceChallenge(challenge, progress, now, 0, nil)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance applies an event to the member's progress if the member is
+// eligible for the challenge.
+func (s *challengeService) advance(challenge *domain.Challenge, user *domain.User, tiers []*domain.Tier, eventKey string, fn func(progress *domain.ChallengeProgress)) error {
+	conditions, err := parseChallengeConditions(challenge)
+	if err != nil || !meetsTierRequirement(tiers, user.Tier, conditions) {
+		return nil
+	}
+
+	_, err = s.challengeRepo.Advance(challenge, user.ID, eventKey, fn)
+	return err
+}
+
+// campaignEventKey identifies a campaign taking part in an order, whichever
+// path reported it.
+func campaignEventKey(campaignID, orderRef string) string {
+	return "campaign:" + campaignID + ":order:" + orderRef
+}
+
+func parseChallengeConditions(challenge *domain.Challenge) (map[string]interface{}, error) {
+	conditions := map[string]interface{}{}
+	if challenge.Conditions == "" {
+		return conditions, nil
+	}
+	if err := json.Unmarshal([]byte(challenge.Conditions), &conditions); err != nil {
+		return nil, err
+	}
+	return conditions, nil
+}
+
+// countedPurchase applies the challenge conditions to a purchase. It returns
+// the amount and the item categories that count, and false if the purchase
+// does not count at all.
+func countedPurchase(conditions map[string]interface{}, purchase *domain.Purchase) (float64, []string, bool) {
+	if minAmount, ok := conditions["min_purchase"].(float64); ok && purchase.Total < minAmount {
+		return 0, nil, false
+	}
+	if stores, ok := conditions["stores"].([]interface{}); ok && !containsValue(stores, purchase.Store) {
+		return 0, nil, false
+	}
+
+	allowed, filtered := conditions["categories"].([]interface{})
+	if !filtered {
+		var categories []string
+		for _, item := range purchase.Items {
+			if item.Category != "" {
+				categories = append(categories, item.Category)
+			}
+		}
+		return purchase.Total, categories, true
+	}
+
+	amount := 0.0
+	var categories []string
+	for _, item := range purchase.Items {
+		if containsValue(allowed, item.Category) {
+			amount += item.Total
+			categories = append(categories, item.Category)
+		}
+	}
+	if len(categories) == 0 {
+		return 0, nil, false
+	}
+	return amount, categories, true
+}
+
+func containsValue(values []interface{}, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// advanceChallenge applies one counted event at the given time to the
+// member's progress, and marks the challenge completed once the goal is met.
+func advanceChallenge(challenge *domain.Challenge, progress *domain.ChallengeProgress, at time.Time, amount float64, categories []string) {
+	switch challenge.Type {
+	case domain.ChallengeVisits:
+		day := at.UTC().Format("2006-01-02")
+		if progress.LastPeriod != day {
+			progress.Progress++
+			progress.LastPeriod = day
+		}
+	case domain.ChallengeStreak:
+		current, previous := streakPeriods(challenge.Period, at)
+		switch progress.LastPeriod {
+		case current:
+		case previous:
+			progress.Progress++
+		default:
+			progress.Progress = 1
+		}
+		progress.LastPeriod = current
+	case domain.ChallengeCategories:
+		var seen []string
+		if progress.Categories != "" {
+			_ = json.Unmarshal([]byte(progress.Categories), &seen)
+		}
+		set := make(map[string]bool, len(seen))
+		for _, category := range seen {
+			set[category] = true
+		}
+		for _, category := range categories {
+			if !set[category] {
+				set[category] = true
+				seen = append(seen, category)
+			}
+		}
+		sort.Strings(seen)
+		encoded, _ := json.Marshal(seen)
+		progress.Categories = string(encoded)
+		progress.Progress = len(seen)
+	case domain.ChallengeSpend:
+		progress.Progress += int(math.Floor(amount))
+	case domain.ChallengeCampaigns:
+		progress.Progress++
+	}
+
+	if progress.Progress >= challenge.Goal && progress.CompletedAt == nil {
+		completedAt := at
+		progress.CompletedAt = &completedAt
+	}
+}
+
+// streakPeriods returns the day or ISO week of t and the one before it.
+func streakPeriods(period string, t time.Time) (string, string) {
+	t = t.UTC()
+	if period == "week" {
+		year, week := t.ISOWeek()
+		prevYear, prevWeek := t.AddDate(0, 0, -7).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), fmt.Sprintf("%d-W%02d", prevYear, prevWeek)
+	}
+	return t.Format("2006-01-02"), t.AddDate(0, 0, -1).Format("2006-01-02")
+}
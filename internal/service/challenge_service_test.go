@@ -0,0 +1,222 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdvanceChallenge(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, time.June, d, 12, 0, 0, 0, time.UTC)
+	}
+	type event struct {
+		at         time.Time
+		amount     float64
+		categories []string
+	}
+
+	tests := []struct {
+		name          string
+		challenge     *domain.Challenge
+		events        []event
+		wantProgress  int
+		wantCompleted bool
+	}{
+		{
+			name:         "visits count distinct days",
+			challenge:    &domain.Challenge{Type: domain.ChallengeVisits, Goal: 3},
+			events:       []event{{at: day(2)}, {at: day(2)}, {at: day(5)}},
+			wantProgress: 2,
+		},
+		{
+			name:          "visits complete at the goal",
+			challenge:     &domain.Challenge{Type: domain.ChallengeVisits, Goal: 3},
+			events:        []event{{at: day(2)}, {at: day(4)}, {at: day(9)}},
+			wantProgress:  3,
+			wantCompleted: true,
+		},
+		{
+			name:          "weekly streak",
+			challenge:     &domain.Challenge{Type: domain.ChallengeStreak, Period: "week", Goal: 3},
+			events:        []event{{at: day(2)}, {at: day(4)}, {at: day(10)}, {at: day(17)}},
+			wantProgress:  3,
+			wantCompleted: true,
+		},
+		{
+			name:         "a missed week restarts the streak",
+			challenge:    &domain.Challenge{Type: domain.ChallengeStreak, Period: "week", Goal: 3},
+			events:       []event{{at: day(2)}, {at: day(10)}, {at: day(24)}},
+			wantProgress: 1,
+		},
+		{
+			name:      "distinct categories",
+			challenge: &domain.Challenge{Type: domain.ChallengeCategories, Goal: 3},
+			events: []event{
+				{at: day(2), categories: []string{"coffee", "bakery"}},
+				{at: day(3), categories: []string{"coffee"}},
+				{at: day(4), categories: []string{"deli"}},
+			},
+			wantProgress:  3,
+			wantCompleted: true,
+		},
+		{
+			name:         "spend",
+			challenge:    &domain.Challenge{Type: domain.ChallengeSpend, Goal: 100},
+			events:       []event{{at: day(2), amount: 40.9}, {at: day(3), amount: 30.5}},
+			wantProgress: 70,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := &domain.ChallengeProgress{}
+			for _, e := range tt.events {
+				advanceChallenge(tt.challenge, progress, e.at, e.amount, e.categories)
+			}
+
+			assert.Equal(t, tt.wantProgress, progress.Progress)
+			assert.Equal(t, tt.wantCompleted, progress.CompletedAt != nil)
+		})
+	}
+}
+
+func TestCountedPurchase(t *testing.T) {
+	purchase := &domain.Purchase{
+		Store: "downtown",
+		Total: 30,
+		Items: []domain.PurchaseItem{
+			{Category: "coffee", Total: 5},
+			{Category: "bakery", Total: 25},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		conditions     map[string]interface{}
+		wantOK         bool
+		wantAmount     float64
+		wantCategories []string
+	}{
+		{
+			name:           "no conditions",
+			conditions:     map[string]interface{}{},
+			wantOK:         true,
+			wantAmount:     30,
+			wantCategories: []string{"coffee", "bakery"},
+		},
+		{
+			name:       "below the minimum purchase",
+			conditions: map[string]interface{}{"min_purchase": 50.0},
+		},
+		{
+			name:       "other store",
+			conditions: map[string]interface{}{"stores": []interface{}{"airport"}},
+		},
+		{
+			name:           "only listed categories count",
+			conditions:     map[string]interface{}{"categories": []interface{}{"coffee"}},
+			wantOK:         true,
+			wantAmount:     5,
+			wantCategories: []string{"coffee"},
+		},
+		{
+			name:       "no listed category bought",
+			conditions: map[string]interface{}{"categories": []interface{}{"deli"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, categories, ok := countedPurchase(tt.conditions, purchase)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantAmount, amount)
+			assert.Equal(t, tt.wantCategories, categories)
+		})
+	}
+}
+
+type MockChallengeRepository struct {
+	mock.Mock
+}
+
+func (m *MockChallengeRepository) Create(challenge *domain.Challenge) error {
+	args := m.Called(challenge)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) FindByID(id string) (*domain.Challenge, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) Update(challenge *domain.Challenge) error {
+	args := m.Called(challenge)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) List() ([]*domain.Challenge, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) ListActive(now time.Time) ([]*domain.Challenge, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) ListProgressByUser(userID string) ([]*domain.ChallengeProgress, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ChallengeProgress), args.Error(1)
+}
+
+func (m *MockChallengeRepository) Advance(challenge *domain.Challenge, userID uuid.UUID, eventKey string, advance func(progress *domain.ChallengeProgress)) (*domain.ChallengeProgress, error) {
+	args := m.Called(challenge, userID, eventKey, advance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ChallengeProgress), args.Error(1)
+}
+
+func (m *MockChallengeRepository) CreateBadge(badge *domain.Badge) error {
+	args := m.Called(badge)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) ListBadges() ([]*domain.Badge, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Badge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) ListMemberBadges(userID string) ([]*domain.MemberBadge, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MemberBadge), args.Error(1)
+}
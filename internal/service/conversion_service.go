@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type ConversionService interface {
+	CreateRate(rate *domain.CouponConversionRate) error
+	UpdateRate(rate *domain.CouponConversionRate) error
+	DeleteRate(id string) error
+	ListActiveRates() ([]*domain.CouponConversionRate, error)
+	ConvertPoints(userID, rateID string) (*domain.Coupon, int, error)
+}
+
+type conversionService struct {
+	rateRepo   repository.ConversionRateRepository
+	couponRepo repository.CouponRepository
+	pointsRepo repository.PointsRepository
+	userRepo   repository.UserRepository
+	transactor repository.Transactor
+}
+
+func NewConversionService(rateRepo repository.ConversionRateRepository, couponRepo repository.CouponRepository, pointsRepo repository.PointsRepository, userRepo repository.UserRepository, transactor repository.Transactor) ConversionService {
+	return &conversionService{
+		rateRepo:   rateRepo,
+		couponRepo: couponRepo,
+		pointsRepo: pointsRepo,
+		userRepo:   userRepo,
+		transactor: transactor,
+	}
+}
+
+func validateConversionRate(rate *domain.CouponConversionRate) error {
+	if rate.Name == "" {
+		return errors.New("rate name is required")
+	}
+	if rate.Points <= 0 {
+		return errors.New("points must be positive")
+	}
+	if rate.CouponValue <= 0 {
+		return errors.New("coupon value must be positive")
+	}
+	if rate.ValidityDays <= 0 {
+		return errors.New("validity days must be positive")
+	}
+	return nil
+}
+
+func (s *conversionService) CreateRate(rate *domain.CouponConversionRate) error {
+	if err := validateConversionRate(rate); err != nil {
+		return err
+	}
+	return s.rateRepo.Create(rate)
+}
+
+func (s *conversionService) UpdateRate(rate *domain.CouponConversionRate) error {
+	if err := validateConversionRate(rate); err != nil {
+		return err
+	}
+	return s.rateRepo.Update(rate)
+}
+
+func (s *conversionService) DeleteRate(id string) error {
+	return s.rateRepo.Delete(id)
+}
+
+func (s *conversionService) ListActiveRates() ([]*domain.CouponConversionRate, error) {
+	return s.rateRepo.ListActive()
+}
+
+// ConvertPoints debits the rate's points and issues a single-use coupon bound
+// to the member. Both happen in one transaction, so a failure on either side
+// leaves neither behind. It returns the coupon and the new balance.
+func (s *conversionService) ConvertPoints(userID, rateID string) (*domain.Coupon, int, error) {
+	rate, err := s.rateRepo.FindByID(rateID)
+	if err != nil || !rate.IsActive {
+		return nil, 0, errors.New("conversion rate not found")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, 0, errors.New("user not found")
+	}
+
+	code, err := generateCouponCode("PTS")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	coupon := &domain.Coupon{
+		Code:        code,
+		Description: rate.Name,
+		Discount:    rate.CouponValue,
+		Type:        "fixed",
+		MinPurchase: rate.MinPurchase,
+		StartDate:   now,
+		EndDate:     now.AddDate(0, 0, rate.ValidityDays),
+		UsageLimit:  1,
+		IsActive:    true,
+		UserID:      &user.ID,
+		Source:      "points_conversion",
+	}
+
+	var balance int
+	err = s.transactor.WithinTransaction(func(tx repository.Tx) error {
+		if err := s.couponRepo.WithTx(tx).Create(coupon); err != nil {
+			return err
+		}
+
+		key := "coupon:convert:" + coupon.ID.String()
+		var err error
+		_, balance, err = s.pointsRepo.WithTx(tx).Record(&domain.PointsTransaction{
+			UserID:         user.ID,
+			Type:           domain.PointsRedeem,
+			Points:         -rate.Points,
+			Note:           "coupon " + coupon.Code,
+			IdempotencyKey: &key,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return coupon, balance, nil
+}
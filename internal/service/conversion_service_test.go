@@ -0,0 +1,159 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCouponRepository struct {
+	mock.Mock
+}
+
+func (m *MockCouponRepository) Create(coupon *domain.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) FindByID(id string) (*domain.Coupon, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) FindByCode(code string) (*domain.Coupon, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) Update(coupon *domain.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) ListActive() ([]*domain.Coupon, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) IncrementUsageCount(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) ListByUser(userID string) ([]*domain.Coupon, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) WithTx(tx repository.Tx) repository.CouponRepository {
+	return m
+}
+
+type MockConversionRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversionRateRepository) Create(rate *domain.CouponConversionRate) error {
+	args := m.Called(rate)
+	return args.Error(0)
+}
+
+func (m *MockConversionRateRepository) FindByID(id string) (*domain.CouponConversionRate, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CouponConversionRate), args.Error(1)
+}
+
+func (m *MockConversionRateRepository) Update(rate *domain.CouponConversionRate) error {
+	args := m.Called(rate)
+	return args.Error(0)
+}
+
+func (m *MockConversionRateRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockConversionRateRepository) ListActive() ([]*domain.CouponConversionRate, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CouponConversionRate), args.Error(1)
+}
+
+// MockTransactor runs the work directly; rollback is the database's job.
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(fn func(tx repository.Tx) error) error {
+	return fn(repository.Tx{})
+}
+
+func TestConversionService_ConvertPoints(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 800}
+	rate := &domain.CouponConversionRate{ID: uuid.New(), Name: "5 EUR voucher", Points: 500, CouponValue: 5, ValidityDays: 90, IsActive: true}
+
+	t.Run("issues a personal coupon for points", func(t *testing.T) {
+		rateRepo := new(MockConversionRateRepository)
+		couponRepo := new(MockCouponRepository)
+		pointsRepo := new(MockPointsRepository)
+		userRepo := new(MockUserRepository)
+
+		rateRepo.On("FindByID", rate.ID.String()).Return(rate, nil)
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		couponRepo.On("Create", mock.MatchedBy(func(coupon *domain.Coupon) bool {
+			return *coupon.UserID == user.ID && coupon.UsageLimit == 1 && coupon.Discount == 5 && coupon.Type == "fixed"
+		})).Return(nil)
+		pointsRepo.On("Record", mock.MatchedBy(func(entry *domain.PointsTransaction) bool {
+			return entry.Type == domain.PointsRedeem && entry.Points == -500
+		})).Return(&domain.PointsTransaction{}, 300, nil)
+
+		service := NewConversionService(rateRepo, couponRepo, pointsRepo, userRepo, &MockTransactor{})
+		coupon, balance, err := service.ConvertPoints(user.ID.String(), rate.ID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 300, balance)
+		assert.Equal(t, "points_conversion", coupon.Source)
+	})
+
+	t.Run("fails when the debit fails", func(t *testing.T) {
+		rateRepo := new(MockConversionRateRepository)
+		couponRepo := new(MockCouponRepository)
+		pointsRepo := new(MockPointsRepository)
+		userRepo := new(MockUserRepository)
+
+		rateRepo.On("FindByID", rate.ID.String()).Return(rate, nil)
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		couponRepo.On("Create", mock.AnythingOfType("*domain.Coupon")).Return(nil)
+		pointsRepo.On("Record", mock.AnythingOfType("*domain.PointsTransaction")).Return(nil, 0, repository.ErrInsufficientPoints)
+
+		service := NewConversionService(rateRepo, couponRepo, pointsRepo, userRepo, &MockTransactor{})
+		coupon, _, err := service.ConvertPoints(user.ID.String(), rate.ID.String())
+
+		assert.ErrorIs(t, err, repository.ErrInsufficientPoints)
+		assert.Nil(t, coupon)
+	})
+}
@@ -1,12 +1,17 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/gclub/internal/config"
 	"github.com/gclub/internal/domain"
 	"github.com/gclub/internal/middleware"
 	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
 )
 
 type CouponService interface {
@@ -15,16 +20,28 @@ type CouponService interface {
 	GetCouponByCode(code string) (*domain.Coupon, error)
 	UpdateCoupon(coupon *domain.Coupon) error
 	DeleteCoupon(id string) error
+	CloneCoupon(id string, params TemplateParams, createdBy *uuid.UUID) (*domain.Coupon, error)
 	ListActiveCoupons() ([]*domain.Coupon, error)
-	ValidateAndApplyCoupon(code string, purchaseAmount float64) (*domain.Coupon, error)
+	ValidateAndApplyCoupon(code string, userID string, purchaseAmount float64) (*domain.Coupon, error)
+	GetCouponHistory(userID string) ([]*domain.Coupon, error)
 }
 
 type couponService struct {
-	couponRepo repository.CouponRepository
+	couponRepo  repository.CouponRepository
+	userRepo    repository.UserRepository
+	tierRepo    repository.TierRepository
+	segmentRepo repository.SegmentRepository
+	loyalty     config.LoyaltyConfig
 }
 
-func NewCouponService(couponRepo repository.CouponRepository) CouponService {
-	return &couponService{couponRepo: couponRepo}
+func NewCouponService(couponRepo repository.CouponRepository, userRepo repository.UserRepository, tierRepo repository.TierRepository, segmentRepo repository.SegmentRepository, loyalty config.LoyaltyConfig) CouponService {
+	return &couponService{
+		couponRepo:  couponRepo,
+		userRepo:    userRepo,
+		tierRepo:    tierRepo,
+		segmentRepo: segmentRepo,
+		loyalty:     loyalty,
+	}
 }
 
 func (s *couponService) CreateCoupon(coupon *domain.Coupon) error {
@@ -43,9 +60,34 @@ func (s *couponService) CreateCoupon(coupon *domain.Coupon) error {
 		return errors.New("percentage discount must be between 0 and 100")
 	}
 
+	// Validate the target segment
+	if coupon.SegmentID != nil {
+		if _, err := s.segmentRepo.FindByID(coupon.SegmentID.String()); err != nil {
+			return errors.New("target segment not found")
+		}
+	}
+
+	// High-value coupons only go live once approved
+	coupon.ApprovalStatus = domain.ApprovalApproved
+	if highValueCoupon(coupon, s.loyalty.CouponApprovalThreshold) {
+		coupon.ApprovalStatus = domain.ApprovalDraft
+	}
+
 	return s.couponRepo.Create(coupon)
 }
 
+// highValueCoupon reports whether the coupon can be worth threshold or more
+// on one order. Percentage coupons without a maximum discount have no limit.
+func highValueCoupon(coupon *domain.Coupon, threshold float64) bool {
+	if threshold <= 0 {
+		return false
+	}
+	if coupon.Type == "percentage" {
+		return coupon.MaxDiscount <= 0 || coupon.MaxDiscount >= threshold
+	}
+	return coupon.Discount >= threshold
+}
+
 func (s *couponService) GetCouponByID(id string) (*domain.Coupon, error) {
 	return s.couponRepo.FindByID(id)
 }
@@ -54,7 +96,30 @@ func (s *couponService) GetCouponByCode(code string) (*domain.Coupon, error) {
 	return s.couponRepo.FindByCode(code)
 }
 
+// UpdateCoupon saves changes to a coupon. Coupons in review cannot change.
+// Approved coupons can change freely while they stay below the approval
+// threshold; above it they can only be paused or resumed.
 func (s *couponService) UpdateCoupon(coupon *domain.Coupon) error {
+	existing, err := s.couponRepo.FindByID(coupon.ID.String())
+	if err != nil {
+		return errors.New("coupon not found")
+	}
+
+	switch {
+	case existing.ApprovalStatus == domain.ApprovalPending:
+		return errors.New("coupon is in review and cannot be changed")
+	case existing.ApprovalStatus == domain.ApprovalApproved && highValueCoupon(coupon, s.loyalty.CouponApprovalThreshold):
+		if highValueCoupon(existing, s.loyalty.CouponApprovalThreshold) {
+			existing.IsActive = coupon.IsActive
+			return s.couponRepo.Update(existing)
+		}
+		return errors.New("coupon value needs approval, create a new coupon instead")
+	}
+
+	coupon.ApprovalStatus = existing.ApprovalStatus
+	coupon.CreatedBy = existing.CreatedBy
+	coupon.UsedCount = existing.UsedCount
+	coupon.CreatedAt = existing.CreatedAt
 	return s.couponRepo.Update(coupon)
 }
 
@@ -62,11 +127,53 @@ func (s *couponService) DeleteCoupon(id string) error {
 	return s.couponRepo.Delete(id)
 }
 
+// CloneCoupon copies a coupon to a new code, the original code followed by
+// params.NameSuffix, starting at params.StartDate. The end date defaults to
+// keep the coupon's length, and params.Value replaces the discount. The copy
+// goes through approval like any new coupon.
+func (s *couponService) CloneCoupon(id string, params TemplateParams, createdBy *uuid.UUID) (*domain.Coupon, error) {
+	original, err := s.couponRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("coupon not found")
+	}
+	if original.UserID != nil {
+		return nil, errors.New("coupons issued to a member cannot be cloned")
+	}
+	if params.StartDate == nil {
+		return nil, errors.New("start date is required")
+	}
+	if params.NameSuffix == "" {
+		return nil, errors.New("name suffix is required for the new coupon code")
+	}
+
+	clone := *original
+	clone.ID = uuid.Nil
+	clone.Code = original.Code + params.NameSuffix
+	clone.UsedCount = 0
+	clone.CreatedBy = createdBy
+	clone.CreatedAt = time.Time{}
+	clone.UpdatedAt = time.Time{}
+	if params.Value != nil {
+		clone.Discount = *params.Value
+	}
+
+	clone.StartDate = *params.StartDate
+	clone.EndDate = original.EndDate.Add(params.StartDate.Sub(original.StartDate))
+	if params.EndDate != nil {
+		clone.EndDate = *params.EndDate
+	}
+
+	if err := s.CreateCoupon(&clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
 func (s *couponService) ListActiveCoupons() ([]*domain.Coupon, error) {
 	return s.couponRepo.ListActive()
 }
 
-func (s *couponService) ValidateAndApplyCoupon(code string, purchaseAmount float64) (*domain.Coupon, error) {
+func (s *couponService) ValidateAndApplyCoupon(code string, userID string, purchaseAmount float64) (*domain.Coupon, error) {
 	coupon, err := s.couponRepo.FindByCode(code)
 	if err != nil {
 		middleware.RecordCouponUsage(code, "invalid_code")
@@ -78,6 +185,10 @@ func (s *couponService) ValidateAndApplyCoupon(code string, purchaseAmount float
 		middleware.RecordCouponUsage(code, "inactive")
 		return nil, errors.New("coupon is not active")
 	}
+	if coupon.ApprovalStatus != domain.ApprovalApproved {
+		middleware.RecordCouponUsage(code, "not_approved")
+		return nil, errors.New("coupon has not been approved")
+	}
 
 	// Validate dates
 	now := time.Now()
@@ -98,6 +209,43 @@ func (s *couponService) ValidateAndApplyCoupon(code string, purchaseAmount float
 		return nil, errors.New("purchase amount does not meet minimum requirement")
 	}
 
+	// Validate that personal coupons are used by their owner
+	if coupon.UserID != nil && coupon.UserID.String() != userID {
+		middleware.RecordCouponUsage(code, "not_owner")
+		return nil, errors.New("coupon belongs to another member")
+	}
+
+	// Validate tier eligibility for exclusive coupons
+	if coupon.MinTier != "" {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			middleware.RecordCouponUsage(code, "user_not_found")
+			return nil, errors.New("user not found")
+		}
+		tiers, err := s.tierRepo.List()
+		if err != nil {
+			middleware.RecordCouponUsage(code, "error")
+			return nil, err
+		}
+		if !meetsTierRequirement(tiers, user.Tier, map[string]interface{}{"min_tier": coupon.MinTier}) {
+			middleware.RecordCouponUsage(code, "tier_not_eligible")
+			return nil, errors.New("membership tier is not eligible for this coupon")
+		}
+	}
+
+	// Validate target segment membership
+	if coupon.SegmentID != nil {
+		segments, err := segmentMemberships(s.segmentRepo, []uuid.UUID{*coupon.SegmentID}, userID, now)
+		if err != nil {
+			middleware.RecordCouponUsage(code, "error")
+			return nil, err
+		}
+		if !segments[*coupon.SegmentID] {
+			middleware.RecordCouponUsage(code, "segment_not_eligible")
+			return nil, errors.New("member is not in the coupon's target segment")
+		}
+	}
+
 	// Calculate discount
 	var discount float64
 	if coupon.Type == "percentage" {
@@ -118,3 +266,17 @@ func (s *couponService) ValidateAndApplyCoupon(code string, purchaseAmount float
 	middleware.RecordCouponUsage(code, "success")
 	return coupon, nil
 }
+
+// GetCouponHistory returns the coupons issued to the member.
+func (s *couponService) GetCouponHistory(userID string) ([]*domain.Coupon, error) {
+	return s.couponRepo.ListByUser(userID)
+}
+
+// generateCouponCode returns a random code for a member-bound coupon.
+func generateCouponCode(prefix string) (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + "-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
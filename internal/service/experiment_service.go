@@ -0,0 +1,252 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+// significanceLevel is the p-value below which a difference is reported as
+// significant.
+const significanceLevel = 0.05
+
+type ExperimentService interface {
+	CreateVariant(campaignID string, variant *domain.CampaignVariant) error
+	ListVariants(campaignID string) ([]*domain.CampaignVariant, error)
+	DeleteVariant(campaignID, id string) error
+	GetExperimentReport(campaignID string) (*ExperimentReport, error)
+}
+
+// ExperimentReport compares the arms of a campaign experiment with its
+// baseline: the control group when the campaign has a holdout, otherwise the
+// first variant.
+type ExperimentReport struct {
+	CampaignID uuid.UUID        `json:"campaign_id"`
+	Baseline   string           `json:"baseline"`
+	Arms       []*ExperimentArm `json:"arms"`
+}
+
+// ExperimentArm is the performance of one arm. The comparison with the
+// baseline is a two-sample z-test on mean order value with unpooled
+// variances; it is left out while either arm has fewer than two orders.
+type ExperimentArm struct {
+	Name              string     `json:"name"`
+	VariantID         *uuid.UUID `json:"variant_id,omitempty"`
+	Control           bool       `json:"control"`
+	Assigned          int64      `json:"assigned"`
+	Applications      int64      `json:"applications"`
+	Members           int64      `json:"members"`
+	PointsIssued      int64      `json:"points_issued"`
+	Revenue           float64    `json:"revenue"`
+	ParticipationRate float64    `json:"participation_rate"` // members with an order out of those assigned
+	MeanOrderValue    float64    `json:"mean_order_value"`
+	StdDev            float64    `json:"std_dev"`
+	Lift              *float64   `json:"lift,omitempty"` // relative to the baseline mean order value
+	Difference        *float64   `json:"difference,omitempty"`
+	CILow             *float64   `json:"ci_low,omitempty"` // 95% confidence interval of the difference
+	CIHigh            *float64   `json:"ci_high,omitempty"`
+	ZScore            *float64   `json:"z_score,omitempty"`
+	PValue            *float64   `json:"p_value,omitempty"`
+	Significant       bool       `json:"significant"`
+
+	sumSquares float64
+}
+
+type experimentService struct {
+	experimentRepo repository.ExperimentRepository
+	campaignRepo   repository.CampaignRepository
+	types          *CampaignTypeRegistry
+}
+
+func NewExperimentService(experimentRepo repository.ExperimentRepository, campaignRepo repository.CampaignRepository, types *CampaignTypeRegistry) ExperimentService {
+	return &experimentService{
+		experimentRepo: experimentRepo,
+		campaignRepo:   campaignRepo,
+		types:          types,
+	}
+}
+
+// validateVariant checks a variant of the campaign. The campaign with the
+// variant's overrides must pass its type's checks like any other campaign.
+func validateVariant(campaign *domain.Campaign, variant *domain.CampaignVariant, types *CampaignTypeRegistry) error {
+	if variant.Name == "" {
+		return errors.New("variant name is required")
+	}
+	if variant.Weight <= 0 {
+		return errors.New("variant weight must be positive")
+	}
+	if variant.Value != nil && *variant.Value < 0 {
+		return errors.New("variant value must not be negative")
+	}
+	if variant.Conditions != "" {
+		var conditions map[string]interface{}
+		if err := json.Unmarshal([]byte(variant.Conditions), &conditions); err != nil {
+			return errors.New("invalid conditions JSON")
+		}
+	}
+
+	campaignType, ok := types.Lookup(campaign.Type)
+	if !ok {
+		return errors.New("invalid campaign type")
+	}
+	return campaignType.ValidateConfig(withVariant(campaign, variant))
+}
+
+func (s *experimentService) CreateVariant(campaignID string, variant *domain.CampaignVariant) error {
+	campaign, err := s.editableCampaign(campaignID)
+	if err != nil {
+		return err
+	}
+	if err := validateVariant(campaign, variant, s.types); err != nil {
+		return err
+	}
+	variant.CampaignID = campaign.ID
+	return s.experimentRepo.CreateVariant(variant)
+}
+
+func (s *experimentService) ListVariants(campaignID string) ([]*domain.CampaignVariant, error) {
+	id, err := uuid.Parse(campaignID)
+	if err != nil {
+		return nil, errors.New("invalid campaign id")
+	}
+	return s.experimentRepo.ListVariants(id)
+}
+
+func (s *experimentService) DeleteVariant(campaignID, id string) error {
+	if _, err := s.editableCampaign(campaignID); err != nil {
+		return err
+	}
+	return s.experimentRepo.DeleteVariant(campaignID, id)
+}
+
+// editableCampaign returns the campaign if its variants may change. Variants
+// change what members get, so like the campaign itself they can only be
+// edited on drafts and rejected campaigns and go through approval.
+func (s *experimentService) editableCampaign(campaignID string) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.FindByID(campaignID)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	if campaign.ApprovalStatus != domain.ApprovalDraft && campaign.ApprovalStatus != domain.ApprovalRejected {
+		return nil, errors.New("variants can only change while the campaign is a draft or rejected")
+	}
+	return campaign, nil
+}
+
+func (s *experimentService) GetExperimentReport(campaignID string) (*ExperimentReport, error) {
+	campaign, err := s.campaignRepo.FindByID(campaignID)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	variants, err := s.experimentRepo.ListVariants(campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.experimentRepo.ArmStats(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.experimentRepo.AssignmentCounts(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	// One arm per variant, or one for the campaign itself without variants
+	var arms []*ExperimentArm
+	if campaign.HoldoutPercent > 0 {
+		arms = append(arms, &ExperimentArm{Name: "control", Control: true})
+	}
+	if len(variants) == 0 {
+		arms = append(arms, &ExperimentArm{Name: campaign.Name})
+	}
+	for _, variant := range variants {
+		id := variant.ID
+		arms = append(arms, &ExperimentArm{Name: variant.Name, VariantID: &id})
+	}
+
+	armFor := func(control bool, variantID *uuid.UUID) *ExperimentArm {
+		for _, arm := range arms {
+			if arm.Control != control {
+				continue
+			}
+			if control || (arm.VariantID == nil && variantID == nil) || (arm.VariantID != nil && variantID != nil && *arm.VariantID == *variantID) {
+				return arm
+			}
+		}
+		return nil
+	}
+	for _, stat := range stats {
+		if arm := armFor(stat.Control, stat.VariantID); arm != nil {
+			arm.Applications = stat.Applications
+			arm.Members = stat.Members
+			arm.PointsIssued = stat.Points
+			arm.Revenue = stat.Revenue
+			arm.sumSquares = stat.SumSquares
+		}
+	}
+	for _, count := range counts {
+		if arm := armFor(count.Control, count.VariantID); arm != nil {
+			arm.Assigned = count.Members
+		}
+	}
+
+	report := &ExperimentReport{CampaignID: campaign.ID, Arms: arms}
+	if len(arms) > 0 {
+		report.Baseline = arms[0].Name
+		compareArms(arms)
+	}
+	return report, nil
+}
+
+// compareArms fills in each arm's statistics and compares every arm after
+// the first with the first.
+func compareArms(arms []*ExperimentArm) {
+	for _, arm := range arms {
+		if arm.Assigned > 0 {
+			arm.ParticipationRate = round4(float64(arm.Members) / float64(arm.Assigned))
+		}
+		if arm.Applications > 0 {
+			n := float64(arm.Applications)
+			arm.MeanOrderValue = roundCurrency(arm.Revenue / n)
+			if arm.Applications > 1 {
+				variance := (arm.sumSquares - arm.Revenue*arm.Revenue/n) / (n - 1)
+				arm.StdDev = roundCurrency(math.Sqrt(math.Max(variance, 0)))
+			}
+		}
+	}
+
+	baseline := arms[0]
+	for _, arm := range arms[1:] {
+		if baseline.Applications < 2 || arm.Applications < 2 {
+			continue
+		}
+
+		baseMean := baseline.Revenue / float64(baseline.Applications)
+		mean := arm.Revenue / float64(arm.Applications)
+		difference := roundCurrency(mean - baseMean)
+		arm.Difference = &difference
+		if baseMean != 0 {
+			lift := round4((mean - baseMean) / baseMean)
+			arm.Lift = &lift
+		}
+
+		se := math.Sqrt(arm.StdDev*arm.StdDev/float64(arm.Applications) + baseline.StdDev*baseline.StdDev/float64(baseline.Applications))
+		low, high := roundCurrency(mean-baseMean-1.96*se), roundCurrency(mean-baseMean+1.96*se)
+		arm.CILow, arm.CIHigh = &low, &high
+		if se == 0 {
+			continue
+		}
+		z := round4((mean - baseMean) / se)
+		p := round4(math.Erfc(math.Abs(mean-baseMean) / se / math.Sqrt2))
+		arm.ZScore, arm.PValue = &z, &p
+		arm.Significant = p < significanceLevel
+	}
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
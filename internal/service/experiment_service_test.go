@@ -0,0 +1,263 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExperimentRepository struct {
+	mock.Mock
+}
+
+func (m *MockExperimentRepository) CreateVariant(variant *domain.CampaignVariant) error {
+	args := m.Called(variant)
+	return args.Error(0)
+}
+
+func (m *MockExperimentRepository) ListVariants(campaignIDs ...uuid.UUID) ([]*domain.CampaignVariant, error) {
+	args := m.Called(campaignIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CampaignVariant), args.Error(1)
+}
+
+func (m *MockExperimentRepository) DeleteVariant(campaignID, id string) error {
+	args := m.Called(campaignID, id)
+	return args.Error(0)
+}
+
+func (m *MockExperimentRepository) Assign(assignment *domain.ExperimentAssignment) (*domain.ExperimentAssignment, error) {
+	args := m.Called(assignment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExperimentAssignment), args.Error(1)
+}
+
+func (m *MockExperimentRepository) ListAssignments(userID uuid.UUID, campaignIDs ...uuid.UUID) ([]*domain.ExperimentAssignment, error) {
+	args := m.Called(userID, campaignIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ExperimentAssignment), args.Error(1)
+}
+
+func (m *MockExperimentRepository) ArmStats(campaignID string) ([]*repository.ExperimentArmStats, error) {
+	args := m.Called(campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ExperimentArmStats), args.Error(1)
+}
+
+func (m *MockExperimentRepository) AssignmentCounts(campaignID string) ([]*repository.ExperimentArmCount, error) {
+	args := m.Called(campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ExperimentArmCount), args.Error(1)
+}
+
+// noExperiments is an experiment repository for campaigns without variants.
+func noExperiments() *MockExperimentRepository {
+	experimentRepo := new(MockExperimentRepository)
+	experimentRepo.On("ListVariants", mock.Anything).Return([]*domain.CampaignVariant{}, nil)
+	return experimentRepo
+}
+
+func TestAssignArm(t *testing.T) {
+	campaign := &domain.Campaign{ID: uuid.New(), HoldoutPercent: 20}
+	a := &domain.CampaignVariant{ID: uuid.New(), Name: "2x", Weight: 1}
+	b := &domain.CampaignVariant{ID: uuid.New(), Name: "3x", Weight: 3}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		userID := uuid.New()
+		arm := assignArm(campaign, []*domain.CampaignVariant{a, b}, userID)
+		assert.Equal(t, arm, assignArm(campaign, []*domain.CampaignVariant{a, b}, userID), "assignment must be stable")
+		switch {
+		case arm.Control:
+			counts["control"]++
+		case arm.Variant == a:
+			counts["2x"]++
+		case arm.Variant == b:
+			counts["3x"]++
+		}
+	}
+
+	assert.InDelta(t, 800, counts["control"], 120)
+	assert.InDelta(t, 800, counts["2x"], 120)
+	assert.InDelta(t, 2400, counts["3x"], 160)
+}
+
+func TestCampaignArms_StoredAssignmentWins(t *testing.T) {
+	userID := uuid.New()
+	campaign := &domain.Campaign{ID: uuid.New(), HoldoutPercent: 0}
+	plain := &domain.Campaign{ID: uuid.New()}
+	a := &domain.CampaignVariant{ID: uuid.New(), CampaignID: campaign.ID, Weight: 1}
+	b := &domain.CampaignVariant{ID: uuid.New(), CampaignID: campaign.ID, Weight: 1}
+
+	experimentRepo := new(MockExperimentRepository)
+	experimentRepo.On("ListVariants", []uuid.UUID{campaign.ID, plain.ID}).Return([]*domain.CampaignVariant{a, b}, nil)
+	experimentRepo.On("Assign", mock.MatchedBy(func(assignment *domain.ExperimentAssignment) bool {
+		return assignment.CampaignID == campaign.ID && assignment.UserID == userID
+	})).Return(&domain.ExperimentAssignment{CampaignID: campaign.ID, UserID: userID, VariantID: &b.ID}, nil)
+
+	arms, err := campaignArms(experimentRepo, []*domain.Campaign{campaign, plain}, userID)
+
+	assert.NoError(t, err)
+	assert.Same(t, b, arms[campaign.ID].Variant)
+	assert.NotContains(t, arms, plain.ID)
+	experimentRepo.AssertNumberOfCalls(t, "Assign", 1)
+}
+
+func TestPreviewArms_StoresNothing(t *testing.T) {
+	userID := uuid.New()
+	assigned := &domain.Campaign{ID: uuid.New()}
+	holdout := &domain.Campaign{ID: uuid.New(), HoldoutPercent: 100}
+	plain := &domain.Campaign{ID: uuid.New()}
+	a := &domain.CampaignVariant{ID: uuid.New(), CampaignID: assigned.ID, Weight: 1}
+	b := &domain.CampaignVariant{ID: uuid.New(), CampaignID: assigned.ID, Weight: 1}
+
+	experimentRepo := new(MockExperimentRepository)
+	experimentRepo.On("ListVariants", []uuid.UUID{assigned.ID, holdout.ID, plain.ID}).Return([]*domain.CampaignVariant{a, b}, nil)
+	experimentRepo.On("ListAssignments", userID, []uuid.UUID{assigned.ID, holdout.ID}).
+		Return([]*domain.ExperimentAssignment{{CampaignID: assigned.ID, UserID: userID, VariantID: &b.ID}}, nil)
+
+	arms, err := previewArms(experimentRepo, []*domain.Campaign{assigned, holdout, plain}, userID)
+
+	assert.NoError(t, err)
+	assert.Same(t, b, arms[assigned.ID].Variant)
+	assert.True(t, arms[holdout.ID].Control)
+	assert.NotContains(t, arms, plain.ID)
+	experimentRepo.AssertNotCalled(t, "Assign", mock.Anything)
+}
+
+func TestEvaluateCampaign_Variant(t *testing.T) {
+	now := time.Now()
+	value := 3.0
+	campaign := &domain.Campaign{
+		ID:             uuid.New(),
+		Type:           "points_multiplier",
+		Value:          2,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      now.Add(-time.Hour),
+		EndDate:        now.Add(time.Hour),
+	}
+	variant := &domain.CampaignVariant{ID: uuid.New(), Value: &value, Conditions: `{"min_purchase": 50}`}
+
+	outcome, err := evaluateCampaign(campaign, &campaignContext{
+		User:           &domain.User{},
+		PurchaseAmount: 100,
+		Now:            now,
+		Arms:           map[uuid.UUID]*campaignArm{campaign.ID: {Variant: variant}},
+		Types:          builtinTypes(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 300, outcome.Points)
+	assert.Equal(t, variant.ID, *outcome.VariantID)
+	assert.Equal(t, 2.0, campaign.Value, "the campaign itself is not changed")
+
+	_, err = evaluateCampaign(campaign, &campaignContext{
+		User:           &domain.User{},
+		PurchaseAmount: 40,
+		Now:            now,
+		Arms:           map[uuid.UUID]*campaignArm{campaign.ID: {Variant: variant}},
+		Types:          builtinTypes(),
+	})
+	assert.Error(t, err)
+
+	_, err = evaluateCampaign(campaign, &campaignContext{
+		User:           &domain.User{},
+		PurchaseAmount: 100,
+		Now:            now,
+		Arms:           map[uuid.UUID]*campaignArm{campaign.ID: {Control: true}},
+		Types:          builtinTypes(),
+	})
+	rejection, ok := err.(*campaignRejection)
+	assert.True(t, ok)
+	assert.Equal(t, campaignHoldout, rejection.Status)
+}
+
+func TestCompareArms(t *testing.T) {
+	// Control orders average 50 (sd 10), the variant 55 (sd 10), 400 each
+	control := &ExperimentArm{Name: "control", Control: true, Assigned: 1000, Members: 300, Applications: 400, Revenue: 20000}
+	control.sumSquares = 20000*20000/400.0 + 100*399
+	variant := &ExperimentArm{Name: "3x", Assigned: 1000, Members: 350, Applications: 400, Revenue: 22000}
+	variant.sumSquares = 22000*22000/400.0 + 100*399
+	small := &ExperimentArm{Name: "5x", Applications: 1, Revenue: 80}
+
+	compareArms([]*ExperimentArm{control, variant, small})
+
+	assert.Equal(t, 0.3, control.ParticipationRate)
+	assert.Equal(t, 50.0, control.MeanOrderValue)
+	assert.Equal(t, 10.0, control.StdDev)
+	assert.Equal(t, 5.0, *variant.Difference)
+	assert.Equal(t, 0.1, *variant.Lift)
+	assert.InDelta(t, 7.0711, *variant.ZScore, 0.001)
+	assert.True(t, variant.Significant)
+	assert.InDelta(t, 3.61, *variant.CILow, 0.01)
+	assert.Nil(t, small.PValue)
+	assert.False(t, small.Significant)
+}
+
+func TestExperimentService_CreateVariant(t *testing.T) {
+	campaign := &domain.Campaign{ID: uuid.New(), Type: "points_multiplier", Value: 2, ApprovalStatus: domain.ApprovalDraft}
+	zero := 0.0
+
+	tests := []struct {
+		name    string
+		variant *domain.CampaignVariant
+		wantErr bool
+	}{
+		{name: "valid", variant: &domain.CampaignVariant{Name: "3x", Weight: 1}},
+		{name: "missing weight", variant: &domain.CampaignVariant{Name: "3x"}, wantErr: true},
+		{name: "invalid conditions", variant: &domain.CampaignVariant{Name: "3x", Weight: 1, Conditions: "{"}, wantErr: true},
+		{name: "value the campaign type rejects", variant: &domain.CampaignVariant{Name: "0x", Weight: 1, Value: &zero}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experimentRepo := new(MockExperimentRepository)
+			campaignRepo := new(MockCampaignRepository)
+			campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+			experimentRepo.On("CreateVariant", tt.variant).Return(nil)
+
+			service := NewExperimentService(experimentRepo, campaignRepo, builtinTypes())
+			err := service.CreateVariant(campaign.ID.String(), tt.variant)
+			if tt.wantErr {
+				assert.Error(t, err)
+				experimentRepo.AssertNotCalled(t, "CreateVariant", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, campaign.ID, tt.variant.CampaignID)
+		})
+	}
+}
+
+func TestExperimentService_VariantsOfApprovedCampaigns(t *testing.T) {
+	for _, status := range []string{domain.ApprovalPending, domain.ApprovalApproved} {
+		t.Run(status, func(t *testing.T) {
+			campaign := &domain.Campaign{ID: uuid.New(), ApprovalStatus: status}
+			experimentRepo := new(MockExperimentRepository)
+			campaignRepo := new(MockCampaignRepository)
+			campaignRepo.On("FindByID", campaign.ID.String()).Return(campaign, nil)
+
+			service := NewExperimentService(experimentRepo, campaignRepo, builtinTypes())
+
+			assert.Error(t, service.CreateVariant(campaign.ID.String(), &domain.CampaignVariant{Name: "3x", Weight: 1}))
+			assert.Error(t, service.DeleteVariant(campaign.ID.String(), uuid.New().String()))
+			experimentRepo.AssertNotCalled(t, "CreateVariant", mock.Anything)
+			experimentRepo.AssertNotCalled(t, "DeleteVariant", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type HouseholdService interface {
+	CreateHousehold(userID, name string) (*domain.Household, error)
+	GetHousehold(userID string) (*domain.Household, []*domain.HouseholdMember, error)
+	InviteMember(householdID, ownerID, email string, contributes, canRedeem bool) (*domain.HouseholdMember, error)
+	JoinHousehold(householdID, userID string) (*domain.HouseholdMember, error)
+	UpdateMember(householdID, ownerID, memberID string, contributes, canRedeem bool) (*domain.HouseholdMember, error)
+	RemoveMember(householdID, userID, memberID string) error
+	Contribute(householdID, userID string, points int) (int, int, error)
+	Withdraw(householdID, userID string, points int) (int, int, error)
+	GetLedger(householdID, userID string, page, pageSize int) ([]*domain.HouseholdPointsTransaction, int64, error)
+}
+
+type householdService struct {
+	householdRepo repository.HouseholdRepository
+	transferRepo  repository.TransferRepository
+	userRepo      repository.UserRepository
+	loyalty       config.LoyaltyConfig
+}
+
+func NewHouseholdService(householdRepo repository.HouseholdRepository, transferRepo repository.TransferRepository, userRepo repository.UserRepository, loyalty config.LoyaltyConfig) HouseholdService {
+	return &householdService{
+		householdRepo: householdRepo,
+		transferRepo:  transferRepo,
+		userRepo:      userRepo,
+		loyalty:       loyalty,
+	}
+}
+
+func (s *householdService) CreateHousehold(userID, name string) (*domain.Household, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("household name is required")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	household := &domain.Household{Name: name, OwnerID: user.ID}
+	owner := &domain.HouseholdMember{
+		UserID:      user.ID,
+		Role:        domain.HouseholdRoleOwner,
+		Status:      domain.HouseholdActive,
+		Contributes: true,
+		CanRedeem:   true,
+	}
+	if err := s.householdRepo.Create(household, owner); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// GetHousehold returns the household the member belongs to and its members.
+func (s *householdService) GetHousehold(userID string) (*domain.Household, []*domain.HouseholdMember, error) {
+	member, err := s.householdRepo.FindMember(userID)
+	if err != nil {
+		return nil, nil, errors.New("household not found")
+	}
+
+	household, err := s.householdRepo.FindByID(member.HouseholdID.String())
+	if err != nil {
+		return nil, nil, errors.New("household not found")
+	}
+	members, err := s.householdRepo.ListMembers(household.ID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return household, members, nil
+}
+
+// InviteMember adds a member to the household. Their earnings only go to the
+// pool once they join.
+func (s *householdService) InviteMember(householdID, ownerID, email string, contributes, canRedeem bool) (*domain.HouseholdMember, error) {
+	household, err := s.ownedHousehold(householdID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	member := &domain.HouseholdMember{
+		HouseholdID: household.ID,
+		UserID:      user.ID,
+		Role:        domain.HouseholdRoleMember,
+		Status:      domain.HouseholdInvited,
+		Contributes: contributes,
+		CanRedeem:   canRedeem,
+	}
+	if err := s.householdRepo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *householdService) JoinHousehold(householdID, userID string) (*domain.HouseholdMember, error) {
+	member, err := s.householdRepo.FindMember(userID)
+	if err != nil || member.HouseholdID.String() != householdID {
+		return nil, errors.New("invitation not found")
+	}
+	if member.Status != domain.HouseholdInvited {
+		return nil, errors.New("already a member of this household")
+	}
+
+	member.Status = domain.HouseholdActive
+	if err := s.householdRepo.UpdateMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *householdService) UpdateMember(householdID, ownerID, memberID string, contributes, canRedeem bool) (*domain.HouseholdMember, error) {
+	if _, err := s.ownedHousehold(householdID, ownerID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.householdRepo.FindMember(memberID)
+	if err != nil || member.HouseholdID.String() != householdID {
+		return nil, errors.New("member not found")
+	}
+
+	member.Contributes = contributes
+	member.CanRedeem = canRedeem
+	if err := s.householdRepo.UpdateMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember lets the owner remove a member, or a member leave. Points
+// already in the pool stay there.
+func (s *householdService) RemoveMember(householdID, userID, memberID string) error {
+	member, err := s.householdRepo.FindMember(memberID)
+	if err != nil || member.HouseholdID.String() != householdID {
+		return errors.New("member not found")
+	}
+	if member.Role == domain.HouseholdRoleOwner {
+		return errors.New("the owner cannot leave the household")
+	}
+	if userID != memberID {
+		if _, err := s.ownedHousehold(householdID, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.householdRepo.RemoveMember(member)
+}
+
+// Contribute moves points from the member to the pool. Contributions count
+// towards the member's daily transfer limits together with the transfers
+// they sent, and new accounts cannot contribute.
+func (s *householdService) Contribute(householdID, userID string, points int) (int, int, error) {
+	if points <= 0 {
+		return 0, 0, errors.New("points must be positive")
+	}
+
+	member, err := s.activeMember(householdID, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return 0, 0, errors.New("user not found")
+	}
+	if accountTooNew(s.loyalty, user) {
+		return 0, 0, errors.New("contribution blocked: sender account too new")
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	sent, count, err := s.transferRepo.SumSentSince(userID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	contributed, contributions, err := s.householdRepo.SumMovedSince(userID, domain.PointsTransferIn, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := checkTransferLimits(s.loyalty, sent+contributed, count+contributions, points); err != nil {
+		return 0, 0, err
+	}
+
+	return s.householdRepo.Contribute(member, points, transferLimits(s.loyalty))
+}
+
+// Withdraw moves points from the pool to the member. Withdrawals are held to
+// the daily transfer limits, and members already receiving from too many
+// others, by transfer or through the pool, cannot withdraw.
+func (s *householdService) Withdraw(householdID, userID string, points int) (int, int, error) {
+	if points <= 0 {
+		return 0, 0, errors.New("points must be positive")
+	}
+
+	member, err := s.activeMember(householdID, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !member.CanRedeem {
+		return 0, 0, errors.New("member is not allowed to redeem household points")
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	withdrawn, withdrawals, err := s.householdRepo.SumMovedSince(userID, domain.PointsTransferOut, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := checkTransferLimits(s.loyalty, withdrawn, withdrawals, points); err != nil {
+		return 0, 0, err
+	}
+
+	senders, err := s.transferRepo.CountSendersSince(userID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	contributors, err := s.householdRepo.CountContributorsSince(householdID, userID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	if senders+contributors >= s.loyalty.TransferMaxSendersPerDay {
+		return 0, 0, errors.New("withdrawal blocked: recipient receiving from too many members")
+	}
+
+	return s.householdRepo.Withdraw(member, points)
+}
+
+func (s *householdService) GetLedger(householdID, userID string, page, pageSize int) ([]*domain.HouseholdPointsTransaction, int64, error) {
+	if _, err := s.activeMember(householdID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.householdRepo.ListLedger(householdID, (page-1)*pageSize, pageSize)
+}
+
+func (s *householdService) ownedHousehold(householdID, ownerID string) (*domain.Household, error) {
+	household, err := s.householdRepo.FindByID(householdID)
+	if err != nil {
+		return nil, errors.New("household not found")
+	}
+	if household.OwnerID.String() != ownerID {
+		return nil, errors.New("only the household owner can manage members")
+	}
+	return household, nil
+}
+
+func (s *householdService) activeMember(householdID, userID string) (*domain.HouseholdMember, error) {
+	member, err := s.householdRepo.FindMember(userID)
+	if err != nil || member.HouseholdID.String() != householdID || member.Status != domain.HouseholdActive {
+		return nil, errors.New("not a member of this household")
+	}
+	return member, nil
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) Create(household *domain.Household, owner *domain.HouseholdMember) error {
+	args := m.Called(household, owner)
+	return args.Error(0)
+}
+
+func (m *MockHouseholdRepository) FindByID(id string) (*domain.Household, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) FindMember(userID string) (*domain.HouseholdMember, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.HouseholdMember), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) ListMembers(householdID string) ([]*domain.HouseholdMember, error) {
+	args := m.Called(householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.HouseholdMember), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) AddMember(member *domain.HouseholdMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMember(member *domain.HouseholdMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(member *domain.HouseholdMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockHouseholdRepository) Contribute(member *domain.HouseholdMember, points int, limits repository.TransferLimits) (int, int, error) {
+	args := m.Called(member, points, limits)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockHouseholdRepository) Withdraw(member *domain.HouseholdMember, points int) (int, int, error) {
+	args := m.Called(member, points)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockHouseholdRepository) SumMovedSince(userID, pointsType string, since time.Time) (int, int, error) {
+	args := m.Called(userID, pointsType, since)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockHouseholdRepository) CountContributorsSince(householdID, excludeUserID string, since time.Time) (int, error) {
+	args := m.Called(householdID, excludeUserID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) ListLedger(householdID string, offset, limit int) ([]*domain.HouseholdPointsTransaction, int64, error) {
+	args := m.Called(householdID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.HouseholdPointsTransaction), args.Get(1).(int64), args.Error(2)
+}
+
+func TestHouseholdService_Contribute(t *testing.T) {
+	loyalty := config.LoyaltyConfig{
+		TransferDailyLimit:        1000,
+		TransferMaxPerDay:         3,
+		TransferMinAccountAgeDays: 30,
+		TransferMaxSendersPerDay:  2,
+	}
+	householdID := uuid.New()
+
+	tests := []struct {
+		name          string
+		user          *domain.User
+		points        int
+		sent          int
+		transfers     int
+		contributed   int
+		contributions int
+		wantErr       bool
+	}{
+		{
+			name:   "contributes to the pool",
+			user:   &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points: 300,
+		},
+		{
+			name:        "over the daily points limit",
+			user:        &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:      300,
+			contributed: 800,
+			wantErr:     true,
+		},
+		{
+			name:    "transfers count towards the daily points limit",
+			user:    &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:  300,
+			sent:    800,
+			wantErr: true,
+		},
+		{
+			name:          "over the daily movement count",
+			user:          &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:        100,
+			transfers:     1,
+			contributions: 2,
+			wantErr:       true,
+		},
+		{
+			name:    "blocks new accounts",
+			user:    &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(0, 0, -2)},
+			points:  300,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			householdRepo := new(MockHouseholdRepository)
+			transferRepo := new(MockTransferRepository)
+			userRepo := new(MockUserRepository)
+			member := &domain.HouseholdMember{HouseholdID: householdID, UserID: tt.user.ID, Status: domain.HouseholdActive, Contributes: true}
+
+			householdRepo.On("FindMember", tt.user.ID.String()).Return(member, nil)
+			userRepo.On("FindByID", tt.user.ID.String()).Return(tt.user, nil)
+			transferRepo.On("SumSentSince", tt.user.ID.String(), mock.AnythingOfType("time.Time")).Return(tt.sent, tt.transfers, nil)
+			householdRepo.On("SumMovedSince", tt.user.ID.String(), domain.PointsTransferIn, mock.AnythingOfType("time.Time")).Return(tt.contributed, tt.contributions, nil)
+			householdRepo.On("Contribute", member, tt.points, repository.TransferLimits{DailyPoints: 1000, MaxPerDay: 3}).Return(tt.user.Points-tt.points, tt.points, nil)
+
+			service := NewHouseholdService(householdRepo, transferRepo, userRepo, loyalty)
+			balance, pool, err := service.Contribute(householdID.String(), tt.user.ID.String(), tt.points)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				householdRepo.AssertNotCalled(t, "Contribute", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.user.Points-tt.points, balance)
+				assert.Equal(t, tt.points, pool)
+			}
+		})
+	}
+}
+
+func TestHouseholdService_Withdraw(t *testing.T) {
+	loyalty := config.LoyaltyConfig{
+		TransferDailyLimit:       1000,
+		TransferMaxPerDay:        3,
+		TransferMaxSendersPerDay: 2,
+	}
+	householdID := uuid.New()
+
+	tests := []struct {
+		name         string
+		canRedeem    bool
+		points       int
+		withdrawn    int
+		withdrawals  int
+		senders      int
+		contributors int
+		withdrawErr  error
+		wantErr      bool
+	}{
+		{
+			name:      "withdraws from the pool",
+			canRedeem: true,
+			points:    300,
+		},
+		{
+			name:    "members who may not redeem",
+			points:  300,
+			wantErr: true,
+		},
+		{
+			name:      "over the daily points limit",
+			canRedeem: true,
+			points:    300,
+			withdrawn: 800,
+			wantErr:   true,
+		},
+		{
+			name:        "over the daily movement count",
+			canRedeem:   true,
+			points:      100,
+			withdrawals: 3,
+			wantErr:     true,
+		},
+		{
+			name:         "blocks members fed by many others",
+			canRedeem:    true,
+			points:       300,
+			senders:      1,
+			contributors: 1,
+			wantErr:      true,
+		},
+		{
+			name:        "more than the pool holds",
+			canRedeem:   true,
+			points:      300,
+			withdrawErr: repository.ErrInsufficientPoolPoints,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			householdRepo := new(MockHouseholdRepository)
+			transferRepo := new(MockTransferRepository)
+			userID := uuid.New()
+			member := &domain.HouseholdMember{HouseholdID: householdID, UserID: userID, Status: domain.HouseholdActive, CanRedeem: tt.canRedeem}
+
+			householdRepo.On("FindMember", userID.String()).Return(member, nil)
+			householdRepo.On("SumMovedSince", userID.String(), domain.PointsTransferOut, mock.AnythingOfType("time.Time")).Return(tt.withdrawn, tt.withdrawals, nil)
+			transferRepo.On("CountSendersSince", userID.String(), mock.AnythingOfType("time.Time")).Return(tt.senders, nil)
+			householdRepo.On("CountContributorsSince", householdID.String(), userID.String(), mock.AnythingOfType("time.Time")).Return(tt.contributors, nil)
+			householdRepo.On("Withdraw", member, tt.points).Return(tt.points, 500-tt.points, tt.withdrawErr)
+
+			service := NewHouseholdService(householdRepo, transferRepo, new(MockUserRepository), loyalty)
+			balance, pool, err := service.Withdraw(householdID.String(), userID.String(), tt.points)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.withdrawErr == nil {
+					householdRepo.AssertNotCalled(t, "Withdraw", mock.Anything, mock.Anything)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.points, balance)
+				assert.Equal(t, 500-tt.points, pool)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type LeaderboardService interface {
+	GetLeaderboard(userID, scope, key, period string, at time.Time, limit int) (*Leaderboard, error)
+	GetSnapshot(scope, key, period string) (*domain.LeaderboardSnapshot, error)
+	SnapshotClosedPeriods(now time.Time) (int, error)
+}
+
+// Leaderboard is the top of a ranking together with the member's own place.
+type Leaderboard struct {
+	Scope    string                     `json:"scope"`
+	Key      string                     `json:"key,omitempty"`
+	Period   string                     `json:"period,omitempty"`
+	Entries  []*domain.LeaderboardEntry `json:"entries"`
+	MyRank   int                        `json:"my_rank,omitempty"`
+	MyPoints int                        `json:"my_points"`
+	OptedOut bool                       `json:"opted_out"`
+}
+
+// snapshotSize is how many members a leaderboard snapshot keeps.
+const snapshotSize = 100
+
+type leaderboardService struct {
+	leaderboardRepo repository.LeaderboardRepository
+	userRepo        repository.UserRepository
+	challengeRepo   repository.ChallengeRepository
+}
+
+func NewLeaderboardService(leaderboardRepo repository.LeaderboardRepository, userRepo repository.UserRepository, challengeRepo repository.ChallengeRepository) LeaderboardService {
+	return &leaderboardService{
+		leaderboardRepo: leaderboardRepo,
+		userRepo:        userRepo,
+		challengeRepo:   challengeRepo,
+	}
+}
+
+// GetLeaderboard ranks members by points earned in the month or week that at
+// falls in, or by progress for challenge leaderboards.
+func (s *leaderboardService) GetLeaderboard(userID, scope, key, period string, at time.Time, limit int) (*Leaderboard, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	board := &Leaderboard{Scope: scope, Key: key, OptedOut: user.LeaderboardOptOut}
+
+	if scope == domain.LeaderboardChallenge {
+		if _, err := s.challengeRepo.FindByID(key); err != nil {
+			return nil, errors.New("challenge not found")
+		}
+		if board.Entries, err = s.leaderboardRepo.ChallengeTop(key, limit); err != nil {
+			return nil, err
+		}
+		if !user.LeaderboardOptOut {
+			board.MyRank, board.MyPoints, err = s.leaderboardRepo.ChallengeRank(key, userID)
+		}
+		return board, err
+	}
+
+	switch scope {
+	case domain.LeaderboardGlobal:
+		board.Key = ""
+	case domain.LeaderboardClub:
+		if board.Key == "" {
+			board.Key = user.Club
+		}
+		if board.Key == "" {
+			return nil, errors.New("club is required")
+		}
+	case domain.LeaderboardStore:
+		if board.Key == "" {
+			return nil, errors.New("store is required")
+		}
+	default:
+		return nil, errors.New("invalid leaderboard scope")
+	}
+
+	month, week := repository.LeaderboardPeriods(at)
+	switch period {
+	case "", "month":
+		board.Period = month
+	case "week":
+		board.Period = week
+	default:
+		return nil, errors.New("period must be month or week")
+	}
+
+	if board.Entries, err = s.leaderboardRepo.Top(board.Scope, board.Key, board.Period, limit); err != nil {
+		return nil, err
+	}
+	if !user.LeaderboardOptOut {
+		board.MyRank, board.MyPoints, err = s.leaderboardRepo.Rank(board.Scope, board.Key, board.Period, userID)
+	}
+	return board, err
+}
+
+func (s *leaderboardService) GetSnapshot(scope, key, period string) (*domain.LeaderboardSnapshot, error) {
+	snapshot, err := s.leaderboardRepo.FindSnapshot(scope, key, period)
+	if err != nil {
+		return nil, errors.New("snapshot not found")
+	}
+	return snapshot, nil
+}
+
+// SnapshotClosedPeriods keeps the final standings of every leaderboard of the
+// last month and week, and of challenges that have ended. Leaderboards that
+// already have a snapshot are skipped, so it is safe to run repeatedly. It
+// returns the number of snapshots taken.
+func (s *leaderboardService) SnapshotClosedPeriods(now time.Time) (int, error) {
+	taken := 0
+
+	lastMonth, _ := repository.LeaderboardPeriods(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1))
+	_, lastWeek := repository.LeaderboardPeriods(now.AddDate(0, 0, -7))
+	for _, period := range []string{lastMonth, lastWeek} {
+		keys, err := s.leaderboardRepo.ListKeys(period)
+		if err != nil {
+			return taken, err
+		}
+		for _, key := range keys {
+			ok, err := s.snapshot(key.Scope, key.ScopeKey, period, now, func() ([]*domain.LeaderboardEntry, error) {
+				return s.leaderboardRepo.Top(key.Scope, key.ScopeKey, period, snapshotSize)
+			})
+			if err != nil {
+				return taken, err
+			}
+			if ok {
+				taken++
+			}
+		}
+	}
+
+	challenges, err := s.challengeRepo.List()
+	if err != nil {
+		return taken, err
+	}
+	for _, challenge := range challenges {
+		if challenge.EndDate.After(now) {
+			continue
+		}
+		id := challenge.ID.String()
+		ok, err := s.snapshot(domain.LeaderboardChallenge, id, "", now, func() ([]*domain.LeaderboardEntry, error) {
+			return s.leaderboardRepo.ChallengeTop(id, snapshotSize)
+		})
+		if err != nil {
+			return taken, err
+		}
+		if ok {
+			taken++
+		}
+	}
+
+	return taken, nil
+}
+
+func (s *leaderboardService) snapshot(scope, key, period string, now time.Time, top func() ([]*domain.LeaderboardEntry, error)) (bool, error) {
+	exists, err := s.leaderboardRepo.SnapshotExists(scope, key, period)
+	if err != nil || exists {
+		return false, err
+	}
+
+	entries, err := top()
+	if err != nil {
+		return false, err
+	}
+
+	err = s.leaderboardRepo.CreateSnapshot(&domain.LeaderboardSnapshot{
+		Scope:    scope,
+		ScopeKey: key,
+		Period:   period,
+		TakenAt:  now,
+		Entries:  entries,
+	})
+	return err == nil, err
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLeaderboardRepository struct {
+	mock.Mock
+}
+
+func (m *MockLeaderboardRepository) Top(scope, key, period string, limit int) ([]*domain.LeaderboardEntry, error) {
+	args := m.Called(scope, key, period, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LeaderboardEntry), args.Error(1)
+}
+
+func (m *MockLeaderboardRepository) Rank(scope, key, period, userID string) (int, int, error) {
+	args := m.Called(scope, key, period, userID)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockLeaderboardRepository) ChallengeTop(challengeID string, limit int) ([]*domain.LeaderboardEntry, error) {
+	args := m.Called(challengeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LeaderboardEntry), args.Error(1)
+}
+
+func (m *MockLeaderboardRepository) ChallengeRank(challengeID, userID string) (int, int, error) {
+	args := m.Called(challengeID, userID)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockLeaderboardRepository) ListKeys(period string) ([]repository.LeaderboardKey, error) {
+	args := m.Called(period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.LeaderboardKey), args.Error(1)
+}
+
+func (m *MockLeaderboardRepository) SnapshotExists(scope, key, period string) (bool, error) {
+	args := m.Called(scope, key, period)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaderboardRepository) CreateSnapshot(snapshot *domain.LeaderboardSnapshot) error {
+	args := m.Called(snapshot)
+	return args.Error(0)
+}
+
+func (m *MockLeaderboardRepository) FindSnapshot(scope, key, period string) (*domain.LeaderboardSnapshot, error) {
+	args := m.Called(scope, key, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LeaderboardSnapshot), args.Error(1)
+}
+
+func TestLeaderboardService_GetLeaderboard(t *testing.T) {
+	at := time.Date(2024, time.June, 12, 0, 0, 0, 0, time.UTC)
+	entries := []*domain.LeaderboardEntry{{Rank: 1, Name: "Anna", Points: 900}}
+
+	t.Run("club leaderboard of the member's club with their rank", func(t *testing.T) {
+		leaderboardRepo := new(MockLeaderboardRepository)
+		userRepo := new(MockUserRepository)
+		user := &domain.User{ID: uuid.New(), Club: "downtown"}
+
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		leaderboardRepo.On("Top", domain.LeaderboardClub, "downtown", "2024-06", 10).Return(entries, nil)
+		leaderboardRepo.On("Rank", domain.LeaderboardClub, "downtown", "2024-06", user.ID.String()).Return(7, 120, nil)
+
+		service := NewLeaderboardService(leaderboardRepo, userRepo, new(MockChallengeRepository))
+		board, err := service.GetLeaderboard(user.ID.String(), domain.LeaderboardClub, "", "", at, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entries, board.Entries)
+		assert.Equal(t, 7, board.MyRank)
+		assert.Equal(t, 120, board.MyPoints)
+	})
+
+	t.Run("weekly period", func(t *testing.T) {
+		leaderboardRepo := new(MockLeaderboardRepository)
+		userRepo := new(MockUserRepository)
+		user := &domain.User{ID: uuid.New()}
+
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		leaderboardRepo.On("Top", domain.LeaderboardGlobal, "", "2024-W24", 10).Return(entries, nil)
+		leaderboardRepo.On("Rank", domain.LeaderboardGlobal, "", "2024-W24", user.ID.String()).Return(0, 0, nil)
+
+		service := NewLeaderboardService(leaderboardRepo, userRepo, new(MockChallengeRepository))
+		board, err := service.GetLeaderboard(user.ID.String(), domain.LeaderboardGlobal, "", "week", at, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-W24", board.Period)
+	})
+
+	t.Run("members who opted out are not ranked", func(t *testing.T) {
+		leaderboardRepo := new(MockLeaderboardRepository)
+		userRepo := new(MockUserRepository)
+		user := &domain.User{ID: uuid.New(), LeaderboardOptOut: true}
+
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		leaderboardRepo.On("Top", domain.LeaderboardGlobal, "", "2024-06", 10).Return(entries, nil)
+
+		service := NewLeaderboardService(leaderboardRepo, userRepo, new(MockChallengeRepository))
+		board, err := service.GetLeaderboard(user.ID.String(), domain.LeaderboardGlobal, "", "", at, 10)
+
+		assert.NoError(t, err)
+		assert.True(t, board.OptedOut)
+		assert.Zero(t, board.MyRank)
+		leaderboardRepo.AssertNotCalled(t, "Rank", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLeaderboardService_SnapshotClosedPeriods(t *testing.T) {
+	now := time.Date(2024, time.July, 3, 2, 0, 0, 0, time.UTC)
+	leaderboardRepo := new(MockLeaderboardRepository)
+	challengeRepo := new(MockChallengeRepository)
+	ended := &domain.Challenge{ID: uuid.New(), EndDate: now.AddDate(0, 0, -1)}
+	running := &domain.Challenge{ID: uuid.New(), EndDate: now.AddDate(0, 0, 10)}
+
+	leaderboardRepo.On("ListKeys", "2024-06").Return([]repository.LeaderboardKey{
+		{Scope: domain.LeaderboardGlobal},
+		{Scope: domain.LeaderboardClub, ScopeKey: "downtown"},
+	}, nil)
+	leaderboardRepo.On("ListKeys", "2024-W26").Return([]repository.LeaderboardKey{}, nil)
+	leaderboardRepo.On("SnapshotExists", domain.LeaderboardGlobal, "", "2024-06").Return(true, nil)
+	leaderboardRepo.On("SnapshotExists", domain.LeaderboardClub, "downtown", "2024-06").Return(false, nil)
+	leaderboardRepo.On("Top", domain.LeaderboardClub, "downtown", "2024-06", snapshotSize).Return([]*domain.LeaderboardEntry{}, nil)
+	challengeRepo.On("List").Return([]*domain.Challenge{ended, running}, nil)
+	leaderboardRepo.On("SnapshotExists", domain.LeaderboardChallenge, ended.ID.String(), "").Return(false, nil)
+	leaderboardRepo.On("ChallengeTop", ended.ID.String(), snapshotSize).Return([]*domain.LeaderboardEntry{}, nil)
+	leaderboardRepo.On("CreateSnapshot", mock.AnythingOfType("*domain.LeaderboardSnapshot")).Return(nil)
+
+	service := NewLeaderboardService(leaderboardRepo, new(MockUserRepository), challengeRepo)
+	taken, err := service.SnapshotClosedPeriods(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, taken)
+	leaderboardRepo.AssertNumberOfCalls(t, "CreateSnapshot", 2)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+type PointsService interface {
+	GetBalance(userID string) (int, error)
+	GetHistory(userID string, page, pageSize int) ([]*domain.PointsTransaction, int64, error)
+	AdjustPoints(userID string, points int, reasonCode, note, adminID string) (*domain.PointsTransaction, int, error)
+	ReconcileBalance(userID string) (int, int, error)
+	ExpirePoints(now time.Time) (int, error)
+	GetExpiringPoints(userID string, days int) (int, []*domain.PointsLot, error)
+	ListExpiryPolicies() ([]*domain.PointsExpiryPolicy, error)
+	SetExpiryPolicy(policy *domain.PointsExpiryPolicy) error
+}
+
+type pointsService struct {
+	pointsRepo repository.PointsRepository
+	userRepo   repository.UserRepository
+}
+
+func NewPointsService(pointsRepo repository.PointsRepository, userRepo repository.UserRepository) PointsService {
+	return &pointsService{
+		pointsRepo: pointsRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *pointsService) GetBalance(userID string) (int, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return 0, errors.New("user not found")
+	}
+	return user.Points, nil
+}
+
+func (s *pointsService) GetHistory(userID string, page, pageSize int) ([]*domain.PointsTransaction, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.pointsRepo.ListByUser(userID, (page-1)*pageSize, pageSize)
+}
+
+func (s *pointsService) AdjustPoints(userID string, points int, reasonCode, note, adminID string) (*domain.PointsTransaction, int, error) {
+	if points == 0 {
+		return nil, 0, errors.New("adjustment must not be zero")
+	}
+
+	// Every manual adjustment must be explained
+	if !domain.AdjustmentReasonCodes[reasonCode] {
+		return nil, 0, errors.New("invalid reason code")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, 0, errors.New("user not found")
+	}
+
+	entry := &domain.PointsTransaction{
+		UserID:     user.ID,
+		Type:       domain.PointsAdjust,
+		Points:     points,
+		ReasonCode: reasonCode,
+		Note:       note,
+	}
+	if admin, err := uuid.Parse(adminID); err == nil {
+		entry.CreatedBy = &admin
+	}
+
+	return s.pointsRepo.Record(entry)
+}
+
+func (s *pointsService) ReconcileBalance(userID string) (int, int, error) {
+	return s.pointsRepo.Reconcile(userID)
+}
+
+func (s *pointsService) ExpirePoints(now time.Time) (int, error) {
+	return s.pointsRepo.ExpireLots(now)
+}
+
+func (s *pointsService) GetExpiringPoints(userID string, days int) (int, []*domain.PointsLot, error) {
+	if days < 1 {
+		days = 30
+	}
+
+	lots, err := s.pointsRepo.ListExpiringLots(userID, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	total := 0
+	for _, lot := range lots {
+		total += lot.Remaining
+	}
+	return total, lots, nil
+}
+
+func (s *pointsService) ListExpiryPolicies() ([]*domain.PointsExpiryPolicy, error) {
+	return s.pointsRepo.ListExpiryPolicies()
+}
+
+func (s *pointsService) SetExpiryPolicy(policy *domain.PointsExpiryPolicy) error {
+	if policy.ExpiryMonths < 0 {
+		return errors.New("expiry months must not be negative")
+	}
+	return s.pointsRepo.SaveExpiryPolicy(policy)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPointsRepository struct {
+	mock.Mock
+}
+
+func (m *MockPointsRepository) Record(entry *domain.PointsTransaction) (*domain.PointsTransaction, int, error) {
+	args := m.Called(entry)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).(*domain.PointsTransaction), args.Int(1), args.Error(2)
+}
+
+func (m *MockPointsRepository) WithTx(tx repository.Tx) repository.PointsRepository {
+	return m
+}
+
+func (m *MockPointsRepository) ListByUser(userID string, offset, limit int) ([]*domain.PointsTransaction, int64, error) {
+	args := m.Called(userID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.PointsTransaction), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockPointsRepository) ListByOrder(userID, orderRef string) ([]*domain.PointsTransaction, error) {
+	args := m.Called(userID, orderRef)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PointsTransaction), args.Error(1)
+}
+
+func (m *MockPointsRepository) LedgerBalance(userID string) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPointsRepository) SumEarned(userID string, since time.Time) (int, error) {
+	args := m.Called(userID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPointsRepository) Reconcile(userID string) (int, int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockPointsRepository) ExpireLots(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPointsRepository) ListExpiringLots(userID string, before time.Time) ([]*domain.PointsLot, error) {
+	args := m.Called(userID, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PointsLot), args.Error(1)
+}
+
+func (m *MockPointsRepository) ListExpiryPolicies() ([]*domain.PointsExpiryPolicy, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PointsExpiryPolicy), args.Error(1)
+}
+
+func (m *MockPointsRepository) SaveExpiryPolicy(policy *domain.PointsExpiryPolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func TestPointsService_AdjustPoints(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Points: 50}
+	adminID := uuid.New()
+
+	tests := []struct {
+		name       string
+		points     int
+		reasonCode string
+		mock       func(userRepo *MockUserRepository, pointsRepo *MockPointsRepository)
+		wantErr    bool
+	}{
+		{
+			name:       "adjustment with reason code",
+			points:     -20,
+			reasonCode: "correction",
+			mock: func(userRepo *MockUserRepository, pointsRepo *MockPointsRepository) {
+				userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+				pointsRepo.On("Record", mock.MatchedBy(func(entry *domain.PointsTransaction) bool {
+					return entry.Type == domain.PointsAdjust && entry.Points == -20 &&
+						entry.ReasonCode == "correction" && *entry.CreatedBy == adminID
+				})).Return(&domain.PointsTransaction{Points: -20}, 30, nil)
+			},
+		},
+		{
+			name:       "unknown reason code",
+			points:     10,
+			reasonCode: "because",
+			mock:       func(userRepo *MockUserRepository, pointsRepo *MockPointsRepository) {},
+			wantErr:    true,
+		},
+		{
+			name:       "zero adjustment",
+			points:     0,
+			reasonCode: "goodwill",
+			mock:       func(userRepo *MockUserRepository, pointsRepo *MockPointsRepository) {},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			pointsRepo := new(MockPointsRepository)
+			tt.mock(userRepo, pointsRepo)
+
+			service := NewPointsService(pointsRepo, userRepo)
+			entry, _, err := service.AdjustPoints(user.ID.String(), tt.points, tt.reasonCode, "", adminID.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, entry)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, entry)
+			pointsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPointsService_GetExpiringPoints(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	pointsRepo := new(MockPointsRepository)
+	userID := uuid.New().String()
+
+	pointsRepo.On("ListExpiringLots", userID, mock.AnythingOfType("time.Time")).Return([]*domain.PointsLot{
+		{Points: 100, Remaining: 40},
+		{Points: 25, Remaining: 25},
+	}, nil)
+
+	service := NewPointsService(pointsRepo, userRepo)
+	total, lots, err := service.GetExpiringPoints(userID, 30)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 65, total)
+	assert.Len(t, lots, 2)
+}
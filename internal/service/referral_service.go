@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type ReferralService interface {
+	AttachReferral(referee *domain.User, code string) (*domain.Referral, error)
+	QualifyReferral(userID string, purchaseTotal float64) (*domain.Referral, error)
+	GetReferralStats(userID string) (*ReferralStats, error)
+	PurchaseObserver
+}
+
+// ReferralStats summarizes the referrals a member has made.
+type ReferralStats struct {
+	Code         string             `json:"code"`
+	Total        int                `json:"total"`
+	Pending      int                `json:"pending"`
+	Rewarded     int                `json:"rewarded"`
+	Rejected     int                `json:"rejected"`
+	PointsEarned int                `json:"points_earned"`
+	Referrals    []*domain.Referral `json:"referrals"`
+}
+
+// publicEmailDomains are shared by unrelated people, so a matching domain
+// says nothing about who signed up.
+var publicEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"aol.com":        true,
+	"proton.me":      true,
+	"protonmail.com": true,
+}
+
+type referralService struct {
+	referralRepo repository.ReferralRepository
+	userRepo     repository.UserRepository
+	loyalty      config.LoyaltyConfig
+}
+
+func NewReferralService(referralRepo repository.ReferralRepository, userRepo repository.UserRepository, loyalty config.LoyaltyConfig) ReferralService {
+	return &referralService{
+		referralRepo: referralRepo,
+		userRepo:     userRepo,
+		loyalty:      loyalty,
+	}
+}
+
+// AttachReferral attributes a newly registered member to the owner of code.
+// Sign-ups that look like self-referrals are still recorded, but rejected so
+// they never pay out.
+func (s *referralService) AttachReferral(referee *domain.User, code string) (*domain.Referral, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	referrer, err := s.userRepo.FindByReferralCode(code)
+	if err != nil {
+		return nil, errors.New("invalid referral code")
+	}
+	if referrer.ID == referee.ID {
+		return nil, errors.New("cannot refer yourself")
+	}
+
+	referral := &domain.Referral{
+		ReferrerID: referrer.ID,
+		RefereeID:  referee.ID,
+		Code:       code,
+		Status:     domain.ReferralPending,
+		SignupIP:   referee.SignupIP,
+		DeviceID:   referee.DeviceID,
+	}
+
+	reason, err := s.abuseCheck(referrer, referee)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		referral.Status = domain.ReferralRejected
+		referral.RejectReason = reason
+	}
+
+	if err := s.referralRepo.Create(referral); err != nil {
+		return nil, err
+	}
+	return referral, nil
+}
+
+// abuseCheck returns why a referral looks like a member referring themselves,
+// or an empty string.
+func (s *referralService) abuseCheck(referrer, referee *domain.User) (string, error) {
+	refereeDomain := emailDomain(referee.Email)
+	if refereeDomain != "" && !publicEmailDomains[refereeDomain] && refereeDomain == emailDomain(referrer.Email) {
+		return "same email domain", nil
+	}
+	if referee.DeviceID != "" && referee.DeviceID == referrer.DeviceID {
+		return "same device", nil
+	}
+	if referee.SignupIP != "" && referee.SignupIP == referrer.SignupIP {
+		return "same ip address", nil
+	}
+
+	// Several sign-ups from one device or address are one person
+	count, err := s.referralRepo.CountBySignal(referrer.ID.String(), referee.SignupIP, referee.DeviceID)
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "repeated device or ip address", nil
+	}
+
+	return "", nil
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// QualifyReferral rewards both sides of the member's pending referral if the
+// purchase qualifies. It returns nil if there is nothing to reward.
+func (s *referralService) QualifyReferral(userID string, purchaseTotal float64) (*domain.Referral, error) {
+	referral, err := s.referralRepo.FindByReferee(userID)
+	if err != nil {
+		return nil, err
+	}
+	if referral == nil || referral.Status != domain.ReferralPending || purchaseTotal < s.loyalty.ReferralMinPurchase {
+		return nil, nil
+	}
+
+	var entries []*domain.PointsTransaction
+	var coupons []*domain.Coupon
+	add := func(user *domain.User, side string, reward float64) error {
+		if reward <= 0 {
+			return nil
+		}
+		note := "referral reward"
+		if s.loyalty.ReferralRewardType == "coupon" {
+			code, err := generateCouponCode("REF")
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			coupons = append(coupons, &domain.Coupon{
+				Code:        code,
+				Description: note,
+				Discount:    reward,
+				Type:        "fixed",
+				UserID:      &user.ID,
+				Source:      "referral",
+				StartDate:   now,
+				EndDate:     now.AddDate(0, 0, s.loyalty.ReferralCouponValidityDays),
+				UsageLimit:  1,
+				IsActive:    true,
+			})
+			return nil
+		}
+
+		points := int(math.Floor(reward))
+		if side == "referrer" {
+			referral.ReferrerPoints = points
+		}
+		key := "referral:" + referral.ID.String() + ":" + side
+		entries = append(entries, &domain.PointsTransaction{
+			UserID:         user.ID,
+			Type:           domain.PointsEarn,
+			Points:         points,
+			Note:           note,
+			IdempotencyKey: &key,
+		})
+		return nil
+	}
+
+	referrer, err := s.userRepo.FindByID(referral.ReferrerID.String())
+	if err != nil {
+		return nil, err
+	}
+	referee, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := add(referrer, "referrer", s.loyalty.ReferralReferrerReward); err != nil {
+		return nil, err
+	}
+	if err := add(referee, "referee", s.loyalty.ReferralRefereeReward); err != nil {
+		return nil, err
+	}
+
+	if err := s.referralRepo.Reward(referral, entries, coupons); err != nil {
+		if errors.Is(err, repository.ErrReferralNotPending) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return referral, nil
+}
+
+// PurchaseRecorded qualifies the member's referral on their purchases.
+func (s *referralService) PurchaseRecorded(purchase *domain.Purchase, user *domain.User, campaigns []AppliedCampaign) {
+	if _, err := s.QualifyReferral(user.ID.String(), purchase.Total); err != nil {
+		log.Printf("Failed to qualify referral of member %s: %v", user.ID, err)
+	}
+}
+
+func (s *referralService) GetReferralStats(userID string) (*ReferralStats, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	referrals, err := s.referralRepo.ListByReferrer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ReferralStats{Total: len(referrals), Referrals: referrals}
+	if user.ReferralCode != nil {
+		stats.Code = *user.ReferralCode
+	}
+	for _, referral := range referrals {
+		switch referral.Status {
+		case domain.ReferralPending:
+			stats.Pending++
+		case domain.ReferralRewarded:
+			stats.Rewarded++
+			stats.PointsEarned += referral.ReferrerPoints
+		case domain.ReferralRejected:
+			stats.Rejected++
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReferralRepository struct {
+	mock.Mock
+}
+
+func (m *MockReferralRepository) Create(referral *domain.Referral) error {
+	args := m.Called(referral)
+	return args.Error(0)
+}
+
+func (m *MockReferralRepository) FindByReferee(userID string) (*domain.Referral, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Referral), args.Error(1)
+}
+
+func (m *MockReferralRepository) ListByReferrer(userID string) ([]*domain.Referral, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Referral), args.Error(1)
+}
+
+func (m *MockReferralRepository) CountBySignal(referrerID, ip, deviceID string) (int64, error) {
+	args := m.Called(referrerID, ip, deviceID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReferralRepository) Reward(referral *domain.Referral, entries []*domain.PointsTransaction, coupons []*domain.Coupon) error {
+	args := m.Called(referral, entries, coupons)
+	return args.Error(0)
+}
+
+func TestReferralService_AttachReferral(t *testing.T) {
+	code := "REF-ABC123"
+	referrer := &domain.User{ID: uuid.New(), Email: "anna@acme.com", SignupIP: "10.0.0.1", DeviceID: "device-1", ReferralCode: &code}
+
+	tests := []struct {
+		name       string
+		referee    *domain.User
+		earlier    int64
+		wantStatus string
+		wantReason string
+	}{
+		{
+			name:       "attributes a new member",
+			referee:    &domain.User{ID: uuid.New(), Email: "ben@gmail.com", SignupIP: "10.0.0.2", DeviceID: "device-2"},
+			wantStatus: domain.ReferralPending,
+		},
+		{
+			name:       "same public email domain is fine",
+			referee:    &domain.User{ID: uuid.New(), Email: "ben@gmail.com"},
+			wantStatus: domain.ReferralPending,
+		},
+		{
+			name:       "same company email domain",
+			referee:    &domain.User{ID: uuid.New(), Email: "anna2@ACME.com"},
+			wantStatus: domain.ReferralRejected,
+			wantReason: "same email domain",
+		},
+		{
+			name:       "same device",
+			referee:    &domain.User{ID: uuid.New(), Email: "ben@gmail.com", DeviceID: "device-1"},
+			wantStatus: domain.ReferralRejected,
+			wantReason: "same device",
+		},
+		{
+			name:       "same ip address",
+			referee:    &domain.User{ID: uuid.New(), Email: "ben@gmail.com", SignupIP: "10.0.0.1"},
+			wantStatus: domain.ReferralRejected,
+			wantReason: "same ip address",
+		},
+		{
+			name:       "repeated sign-ups from one device",
+			referee:    &domain.User{ID: uuid.New(), Email: "ben@gmail.com", DeviceID: "device-9"},
+			earlier:    1,
+			wantStatus: domain.ReferralRejected,
+			wantReason: "repeated device or ip address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referralRepo := new(MockReferralRepository)
+			userRepo := new(MockUserRepository)
+
+			userRepo.On("FindByReferralCode", code).Return(referrer, nil)
+			referralRepo.On("CountBySignal", referrer.ID.String(), tt.referee.SignupIP, tt.referee.DeviceID).Return(tt.earlier, nil)
+			referralRepo.On("Create", mock.AnythingOfType("*domain.Referral")).Return(nil)
+
+			service := NewReferralService(referralRepo, userRepo, config.LoyaltyConfig{})
+			referral, err := service.AttachReferral(tt.referee, " ref-abc123 ")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, referral.Status)
+			assert.Equal(t, tt.wantReason, referral.RejectReason)
+			assert.Equal(t, referrer.ID, referral.ReferrerID)
+		})
+	}
+}
+
+func TestReferralService_QualifyReferral(t *testing.T) {
+	loyalty := config.LoyaltyConfig{
+		ReferralRewardType:     "points",
+		ReferralReferrerReward: 500,
+		ReferralRefereeReward:  250,
+		ReferralMinPurchase:    20,
+	}
+	referrer := &domain.User{ID: uuid.New()}
+	referee := &domain.User{ID: uuid.New()}
+
+	t.Run("rewards both members on a qualifying purchase", func(t *testing.T) {
+		referralRepo := new(MockReferralRepository)
+		userRepo := new(MockUserRepository)
+		referral := &domain.Referral{ID: uuid.New(), ReferrerID: referrer.ID, RefereeID: referee.ID, Status: domain.ReferralPending}
+
+		referralRepo.On("FindByReferee", referee.ID.String()).Return(referral, nil)
+		userRepo.On("FindByID", referrer.ID.String()).Return(referrer, nil)
+		userRepo.On("FindByID", referee.ID.String()).Return(referee, nil)
+		referralRepo.On("Reward", referral, mock.MatchedBy(func(entries []*domain.PointsTransaction) bool {
+			return len(entries) == 2 &&
+				entries[0].UserID == referrer.ID && entries[0].Points == 500 &&
+				entries[1].UserID == referee.ID && entries[1].Points == 250
+		}), []*domain.Coupon(nil)).Return(nil)
+
+		service := NewReferralService(referralRepo, userRepo, loyalty)
+		rewarded, err := service.QualifyReferral(referee.ID.String(), 35)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, rewarded)
+		assert.Equal(t, 500, rewarded.ReferrerPoints)
+		referralRepo.AssertExpectations(t)
+	})
+
+	t.Run("small purchases do not qualify", func(t *testing.T) {
+		referralRepo := new(MockReferralRepository)
+		referral := &domain.Referral{ID: uuid.New(), ReferrerID: referrer.ID, RefereeID: referee.ID, Status: domain.ReferralPending}
+		referralRepo.On("FindByReferee", referee.ID.String()).Return(referral, nil)
+
+		service := NewReferralService(referralRepo, new(MockUserRepository), loyalty)
+		rewarded, err := service.QualifyReferral(referee.ID.String(), 10)
+
+		assert.NoError(t, err)
+		assert.Nil(t, rewarded)
+		referralRepo.AssertNotCalled(t, "Reward", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("members who were not referred earn nothing", func(t *testing.T) {
+		referralRepo := new(MockReferralRepository)
+		referralRepo.On("FindByReferee", referee.ID.String()).Return(nil, nil)
+
+		service := NewReferralService(referralRepo, new(MockUserRepository), loyalty)
+		rewarded, err := service.QualifyReferral(referee.ID.String(), 100)
+
+		assert.NoError(t, err)
+		assert.Nil(t, rewarded)
+		referralRepo.AssertNotCalled(t, "Reward", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected referrals never pay out", func(t *testing.T) {
+		referralRepo := new(MockReferralRepository)
+		referral := &domain.Referral{ID: uuid.New(), ReferrerID: referrer.ID, RefereeID: referee.ID, Status: domain.ReferralRejected}
+		referralRepo.On("FindByReferee", referee.ID.String()).Return(referral, nil)
+
+		service := NewReferralService(referralRepo, new(MockUserRepository), loyalty)
+		rewarded, err := service.QualifyReferral(referee.ID.String(), 100)
+
+		assert.NoError(t, err)
+		assert.Nil(t, rewarded)
+		referralRepo.AssertNotCalled(t, "Reward", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type RefundService interface {
+	RefundPurchase(orderID string, refund *domain.Refund) (*RefundResult, error)
+	ListRefundsForReview() ([]*domain.Refund, error)
+	ResolveRefund(id string, action string) (*RefundResult, error)
+}
+
+// RefundResult describes the points a refund clawed back.
+type RefundResult struct {
+	Refund    *domain.Refund `json:"refund"`
+	Balance   int            `json:"balance"`
+	Duplicate bool           `json:"duplicate"`
+}
+
+type refundService struct {
+	refundRepo   repository.RefundRepository
+	purchaseRepo repository.PurchaseRepository
+	pointsRepo   repository.PointsRepository
+	userRepo     repository.UserRepository
+	loyalty      config.LoyaltyConfig
+}
+
+func NewRefundService(refundRepo repository.RefundRepository, purchaseRepo repository.PurchaseRepository, pointsRepo repository.PointsRepository, userRepo repository.UserRepository, loyalty config.LoyaltyConfig) RefundService {
+	return &refundService{
+		refundRepo:   refundRepo,
+		purchaseRepo: purchaseRepo,
+		pointsRepo:   pointsRepo,
+		userRepo:     userRepo,
+		loyalty:      loyalty,
+	}
+}
+
+func (s *refundService) RefundPurchase(orderID string, refund *domain.Refund) (*RefundResult, error) {
+	if refund.RefundRef == "" {
+		return nil, errors.New("refund id is required")
+	}
+	if refund.Amount <= 0 {
+		return nil, errors.New("refund amount must be positive")
+	}
+
+	// Refunds are recorded once per refund id
+	if existing, err := s.refundRepo.FindByRef(refund.RefundRef); err == nil {
+		if existing.OrderID != orderID {
+			return nil, errors.New("refund id belongs to another order")
+		}
+		user, err := s.userRepo.FindByID(existing.UserID.String())
+		if err != nil {
+			return nil, err
+		}
+		return &RefundResult{Refund: existing, Balance: user.Points, Duplicate: true}, nil
+	}
+
+	// Reversals are computed from the refunded amount seen here, so retry if
+	// another refund of the same order got in first
+	for attempt := 0; ; attempt++ {
+		result, err := s.refund(orderID, refund)
+		if errors.Is(err, repository.ErrConcurrentRefund) && attempt < 2 {
+			continue
+		}
+		return result, err
+	}
+}
+
+func (s *refundService) refund(orderID string, refund *domain.Refund) (*RefundResult, error) {
+	purchase, err := s.purchaseRepo.FindByOrderID(orderID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	if purchase.RefundedAmount+refund.Amount > purchase.Total+0.005 {
+		return nil, repository.ErrRefundExceedsPurchase
+	}
+
+	ledger, err := s.pointsRepo.ListByOrder(purchase.UserID.String(), orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.PurchaseID = purchase.ID
+	refund.OrderID = purchase.OrderID
+	refund.UserID = purchase.UserID
+	refund.Status = domain.RefundCompleted
+	refund.PointsDeficit = 0
+
+	entries := reversalEntries(purchase, ledger, refund)
+	balance, err := s.refundRepo.Create(refund, entries, purchase.RefundedAmount, s.loyalty.RefundBalancePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefundResult{Refund: refund, Balance: balance}, nil
+}
+
+// reversalEntries claws back the points of every earn entry of the purchase,
+// base and campaign bonus alike, in proportion to the refunded amount. The
+// reversal is the difference between what the cumulative refund and the
+// previously refunded amount account for, so rounding never adds up to more
+// than was earned and a full refund reverses everything.
+func reversalEntries(purchase *domain.Purchase, ledger []*domain.PointsTransaction, refund *domain.Refund) []*domain.PointsTransaction {
+	if purchase.Total <= 0 {
+		return nil
+	}
+
+	before := purchase.RefundedAmount / purchase.Total
+	after := math.Min((purchase.RefundedAmount+refund.Amount)/purchase.Total, 1)
+	if after > 0.99999 {
+		after = 1
+	}
+
+	var entries []*domain.PointsTransaction
+	for _, earned := range ledger {
+		if earned.Type != domain.PointsEarn || earned.Points <= 0 {
+			continue
+		}
+
+		points := int(math.Floor(float64(earned.Points)*after)) - int(math.Floor(float64(earned.Points)*before))
+		if points <= 0 {
+			continue
+		}
+
+		earnedID := earned.ID
+		key := "refund:" + refund.RefundRef + ":" + earned.ID.String()
+		entries = append(entries, &domain.PointsTransaction{
+			UserID:         earned.UserID,
+			Type:           domain.PointsReverse,
+			Points:         -points,
+			CampaignID:     earned.CampaignID,
+			OrderRef:       earned.OrderRef,
+			Store:          earned.Store,
+			ReversesID:     &earnedID,
+			IdempotencyKey: &key,
+		})
+	}
+	return entries
+}
+
+func (s *refundService) ListRefundsForReview() ([]*domain.Refund, error) {
+	return s.refundRepo.ListByStatus(domain.RefundInReview)
+}
+
+// ResolveRefund closes a refund flagged for review, either recording the
+// outstanding reversal as debt ("apply_debt") or writing it off ("waive").
+func (s *refundService) ResolveRefund(id string, action string) (*RefundResult, error) {
+	refund, err := s.refundRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("refund not found")
+	}
+	if refund.Status != domain.RefundInReview {
+		return nil, errors.New("refund is not awaiting review")
+	}
+
+	var entry *domain.PointsTransaction
+	switch action {
+	case "apply_debt":
+		refund.Resolution = "applied_debt"
+		key := "refund:" + refund.RefundRef + ":deficit"
+		entry = &domain.PointsTransaction{
+			UserID:         refund.UserID,
+			Type:           domain.PointsReverse,
+			Points:         -refund.PointsDeficit,
+			OrderRef:       refund.OrderID,
+			IdempotencyKey: &key,
+		}
+	case "waive":
+		refund.Resolution = "waived"
+	default:
+		return nil, errors.New("invalid action")
+	}
+
+	balance, err := s.refundRepo.Resolve(refund, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefundResult{Refund: refund, Balance: balance}, nil
+}
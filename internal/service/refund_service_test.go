@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefundRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefundRepository) Create(refund *domain.Refund, entries []*domain.PointsTransaction, refundedBefore float64, policy string) (int, error) {
+	args := m.Called(refund, entries, refundedBefore, policy)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRefundRepository) FindByID(id string) (*domain.Refund, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Refund), args.Error(1)
+}
+
+func (m *MockRefundRepository) FindByRef(refundRef string) (*domain.Refund, error) {
+	args := m.Called(refundRef)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Refund), args.Error(1)
+}
+
+func (m *MockRefundRepository) ListByStatus(status string) ([]*domain.Refund, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Refund), args.Error(1)
+}
+
+func (m *MockRefundRepository) Resolve(refund *domain.Refund, entry *domain.PointsTransaction) (int, error) {
+	args := m.Called(refund, entry)
+	return args.Int(0), args.Error(1)
+}
+
+func TestReversalEntries(t *testing.T) {
+	userID := uuid.New()
+	campaignID := uuid.New()
+	ledger := []*domain.PointsTransaction{
+		{ID: uuid.New(), UserID: userID, Type: domain.PointsEarn, Points: 101, OrderRef: "order-1"},
+		{ID: uuid.New(), UserID: userID, Type: domain.PointsEarn, Points: 50, OrderRef: "order-1", CampaignID: &campaignID},
+		{ID: uuid.New(), UserID: userID, Type: domain.PointsReverse, Points: -10, OrderRef: "order-1"},
+	}
+
+	tests := []struct {
+		name       string
+		refunded   float64
+		amount     float64
+		wantPoints []int
+	}{
+		{
+			name:       "partial refund reverses proportionally",
+			refunded:   0,
+			amount:     50,
+			wantPoints: []int{-50, -25},
+		},
+		{
+			name:       "final refund reverses the remainder",
+			refunded:   50,
+			amount:     50,
+			wantPoints: []int{-51, -25},
+		},
+		{
+			name:       "full refund reverses everything",
+			refunded:   0,
+			amount:     100,
+			wantPoints: []int{-101, -50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purchase := &domain.Purchase{OrderID: "order-1", Total: 100, RefundedAmount: tt.refunded}
+			refund := &domain.Refund{RefundRef: "refund-1", Amount: tt.amount}
+
+			entries := reversalEntries(purchase, ledger, refund)
+
+			var points []int
+			for _, entry := range entries {
+				assert.Equal(t, domain.PointsReverse, entry.Type)
+				assert.NotNil(t, entry.ReversesID)
+				points = append(points, entry.Points)
+			}
+			assert.Equal(t, tt.wantPoints, points)
+			assert.Equal(t, campaignID, *entries[1].CampaignID)
+		})
+	}
+}
+
+func TestRefund_SettleReversals(t *testing.T) {
+	tests := []struct {
+		name         string
+		balance      int
+		policy       string
+		wantPoints   []int
+		wantReversed int
+		wantDeficit  int
+		wantStatus   string
+	}{
+		{
+			name:         "balance covers the reversal",
+			balance:      100,
+			policy:       domain.RefundPolicyCapAtZero,
+			wantPoints:   []int{-50, -25},
+			wantReversed: 75,
+			wantStatus:   domain.RefundCompleted,
+		},
+		{
+			name:         "allow_debt reverses everything",
+			balance:      30,
+			policy:       domain.RefundPolicyAllowDebt,
+			wantPoints:   []int{-50, -25},
+			wantReversed: 75,
+			wantStatus:   domain.RefundCompleted,
+		},
+		{
+			name:         "cap_at_zero only takes what is left",
+			balance:      30,
+			policy:       domain.RefundPolicyCapAtZero,
+			wantPoints:   []int{-30, 0},
+			wantReversed: 30,
+			wantDeficit:  45,
+			wantStatus:   domain.RefundCompleted,
+		},
+		{
+			name:         "flag_for_review caps and puts the refund in review",
+			balance:      30,
+			policy:       domain.RefundPolicyFlagForReview,
+			wantPoints:   []int{-30, 0},
+			wantReversed: 30,
+			wantDeficit:  45,
+			wantStatus:   domain.RefundInReview,
+		},
+		{
+			name:        "cap_at_zero takes nothing from a negative balance",
+			balance:     -10,
+			policy:      domain.RefundPolicyCapAtZero,
+			wantPoints:  []int{0, 0},
+			wantDeficit: 75,
+			wantStatus:  domain.RefundCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund := &domain.Refund{Status: domain.RefundCompleted}
+			entries := []*domain.PointsTransaction{{Points: -50}, {Points: -25}}
+
+			refund.SettleReversals(entries, tt.balance, tt.policy)
+
+			assert.Equal(t, tt.wantPoints, []int{entries[0].Points, entries[1].Points})
+			assert.Equal(t, tt.wantReversed, refund.PointsReversed)
+			assert.Equal(t, tt.wantDeficit, refund.PointsDeficit)
+			assert.Equal(t, tt.wantStatus, refund.Status)
+		})
+	}
+}
+
+func TestRefundService_RefundPurchase(t *testing.T) {
+	userID := uuid.New()
+	purchase := &domain.Purchase{ID: uuid.New(), OrderID: "order-1", UserID: userID, Total: 100}
+	ledger := []*domain.PointsTransaction{
+		{ID: uuid.New(), UserID: userID, Type: domain.PointsEarn, Points: 100, OrderRef: "order-1"},
+	}
+
+	tests := []struct {
+		name        string
+		policy      string
+		wantBalance int
+		wantDeficit int
+		wantStatus  string
+	}{
+		{name: "allow_debt", policy: domain.RefundPolicyAllowDebt, wantBalance: -70, wantStatus: domain.RefundCompleted},
+		{name: "cap_at_zero", policy: domain.RefundPolicyCapAtZero, wantDeficit: 70, wantStatus: domain.RefundCompleted},
+		{name: "flag_for_review", policy: domain.RefundPolicyFlagForReview, wantDeficit: 70, wantStatus: domain.RefundInReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refundRepo := new(MockRefundRepository)
+			purchaseRepo := new(MockPurchaseRepository)
+			pointsRepo := new(MockPointsRepository)
+
+			refundRepo.On("FindByRef", "refund-1").Return(nil, errors.New("record not found"))
+			purchaseRepo.On("FindByOrderID", "order-1").Return(purchase, nil)
+			pointsRepo.On("ListByOrder", userID.String(), "order-1").Return(ledger, nil)
+			// The member holds 30 points when the refund is recorded
+			refundRepo.On("Create", mock.AnythingOfType("*domain.Refund"), mock.Anything, 0.0, tt.policy).
+				Run(func(args mock.Arguments) {
+					refund := args.Get(0).(*domain.Refund)
+					refund.SettleReversals(args.Get(1).([]*domain.PointsTransaction), 30, args.String(3))
+				}).
+				Return(tt.wantBalance, nil)
+
+			service := NewRefundService(refundRepo, purchaseRepo, pointsRepo, new(MockUserRepository), config.LoyaltyConfig{RefundBalancePolicy: tt.policy})
+			result, err := service.RefundPurchase("order-1", &domain.Refund{RefundRef: "refund-1", Amount: 100})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBalance, result.Balance)
+			assert.Equal(t, tt.wantDeficit, result.Refund.PointsDeficit)
+			assert.Equal(t, tt.wantStatus, result.Refund.Status)
+			assert.Equal(t, userID, result.Refund.UserID)
+			refundRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefundService_ResolveRefund(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		action         string
+		wantEntry      bool
+		wantResolution string
+		wantErr        string
+	}{
+		{name: "apply_debt records the deficit", status: domain.RefundInReview, action: "apply_debt", wantEntry: true, wantResolution: "applied_debt"},
+		{name: "waive writes the deficit off", status: domain.RefundInReview, action: "waive", wantResolution: "waived"},
+		{name: "refund not in review", status: domain.RefundCompleted, action: "waive", wantErr: "refund is not awaiting review"},
+		{name: "unknown action", status: domain.RefundInReview, action: "forgive", wantErr: "invalid action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund := &domain.Refund{ID: uuid.New(), RefundRef: "refund-1", OrderID: "order-1", UserID: uuid.New(), PointsDeficit: 70, Status: tt.status}
+			refundRepo := new(MockRefundRepository)
+			refundRepo.On("FindByID", refund.ID.String()).Return(refund, nil)
+			refundRepo.On("Resolve", refund, mock.Anything).Return(-70, nil)
+
+			service := NewRefundService(refundRepo, new(MockPurchaseRepository), new(MockPointsRepository), new(MockUserRepository), config.LoyaltyConfig{})
+			result, err := service.ResolveRefund(refund.ID.String(), tt.action)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				refundRepo.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantResolution, result.Refund.Resolution)
+
+			entry := refundRepo.Calls[1].Arguments.Get(1).(*domain.PointsTransaction)
+			if tt.wantEntry {
+				assert.Equal(t, -70, entry.Points)
+				assert.Equal(t, domain.PointsReverse, entry.Type)
+				assert.Equal(t, refund.UserID, entry.UserID)
+			} else {
+				assert.Nil(t, entry)
+			}
+		})
+	}
+}
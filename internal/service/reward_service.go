@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+type RewardService interface {
+	CreateReward(reward *domain.Reward) error
+	GetRewardByID(id string) (*domain.Reward, error)
+	UpdateReward(reward *domain.Reward) error
+	DeleteReward(id string) error
+	ListAvailableRewards() ([]*domain.Reward, error)
+	RedeemReward(rewardID, userID string) (*domain.RewardRedemption, int, error)
+	GetRedemptionHistory(userID string) ([]*domain.RewardRedemption, error)
+	ListRedemptions(status string, page, pageSize int) ([]*domain.RewardRedemption, int64, error)
+	UpdateRedemptionStatus(id, status, note string) (*domain.RewardRedemption, error)
+}
+
+type rewardService struct {
+	rewardRepo repository.RewardRepository
+	userRepo   repository.UserRepository
+}
+
+func NewRewardService(rewardRepo repository.RewardRepository, userRepo repository.UserRepository) RewardService {
+	return &rewardService{
+		rewardRepo: rewardRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func validateReward(reward *domain.Reward) error {
+	if reward.Name == "" {
+		return errors.New("reward name is required")
+	}
+	if reward.Points <= 0 {
+		return errors.New("points cost must be positive")
+	}
+	if reward.Stock != nil && *reward.Stock < 0 {
+		return errors.New("stock must not be negative")
+	}
+	if reward.PerMemberLimit < 0 {
+		return errors.New("per member limit must not be negative")
+	}
+	return nil
+}
+
+func (s *rewardService) CreateReward(reward *domain.Reward) error {
+	if err := validateReward(reward); err != nil {
+		return err
+	}
+	return s.rewardRepo.Create(reward)
+}
+
+func (s *rewardService) GetRewardByID(id string) (*domain.Reward, error) {
+	return s.rewardRepo.FindByID(id)
+}
+
+func (s *rewardService) UpdateReward(reward *domain.Reward) error {
+	if err := validateReward(reward); err != nil {
+		return err
+	}
+	return s.rewardRepo.Update(reward)
+}
+
+func (s *rewardService) DeleteReward(id string) error {
+	return s.rewardRepo.Delete(id)
+}
+
+func (s *rewardService) ListAvailableRewards() ([]*domain.Reward, error) {
+	return s.rewardRepo.ListAvailable()
+}
+
+func (s *rewardService) RedeemReward(rewardID, userID string) (*domain.RewardRedemption, int, error) {
+	reward, err := s.rewardRepo.FindByID(rewardID)
+	if err != nil {
+		return nil, 0, errors.New("reward not found")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, 0, errors.New("user not found")
+	}
+
+	redemption := &domain.RewardRedemption{
+		RewardID: reward.ID,
+		UserID:   user.ID,
+	}
+	balance, err := s.rewardRepo.Redeem(redemption)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	redemption.Reward = reward
+	return redemption, balance, nil
+}
+
+func (s *rewardService) GetRedemptionHistory(userID string) ([]*domain.RewardRedemption, error) {
+	return s.rewardRepo.ListRedemptionsByUser(userID)
+}
+
+func (s *rewardService) ListRedemptions(status string, page, pageSize int) ([]*domain.RewardRedemption, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.rewardRepo.ListRedemptions(status, (page-1)*pageSize, pageSize)
+}
+
+func (s *rewardService) UpdateRedemptionStatus(id, status, note string) (*domain.RewardRedemption, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.New("invalid redemption id")
+	}
+
+	redemption, err := s.rewardRepo.FindRedemption(id)
+	if err != nil {
+		return nil, errors.New("redemption not found")
+	}
+
+	if note != "" {
+		redemption.Note = note
+	}
+	if err := s.rewardRepo.ChangeRedemptionStatus(redemption, status); err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
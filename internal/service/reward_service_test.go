@@ -0,0 +1,287 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRewardRepository struct {
+	mock.Mock
+}
+
+func (m *MockRewardRepository) Create(reward *domain.Reward) error {
+	args := m.Called(reward)
+	return args.Error(0)
+}
+
+func (m *MockRewardRepository) FindByID(id string) (*domain.Reward, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Reward), args.Error(1)
+}
+
+func (m *MockRewardRepository) Update(reward *domain.Reward) error {
+	args := m.Called(reward)
+	return args.Error(0)
+}
+
+func (m *MockRewardRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRewardRepository) ListAvailable() ([]*domain.Reward, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Reward), args.Error(1)
+}
+
+func (m *MockRewardRepository) Redeem(redemption *domain.RewardRedemption) (int, error) {
+	args := m.Called(redemption)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRewardRepository) FindRedemption(id string) (*domain.RewardRedemption, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RewardRedemption), args.Error(1)
+}
+
+func (m *MockRewardRepository) ListRedemptionsByUser(userID string) ([]*domain.RewardRedemption, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RewardRedemption), args.Error(1)
+}
+
+func (m *MockRewardRepository) ListRedemptions(status string, offset, limit int) ([]*domain.RewardRedemption, int64, error) {
+	args := m.Called(status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.RewardRedemption), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRewardRepository) ChangeRedemptionStatus(redemption *domain.RewardRedemption, status string) error {
+	args := m.Called(redemption, status)
+	return args.Error(0)
+}
+
+func TestRewardService_CreateReward(t *testing.T) {
+	stock := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		reward  *domain.Reward
+		wantErr bool
+	}{
+		{
+			name:   "limited stock and per member limit",
+			reward: &domain.Reward{Name: "Tote bag", Points: 500, Stock: stock(10), PerMemberLimit: 1},
+		},
+		{
+			name:   "unlimited stock",
+			reward: &domain.Reward{Name: "Coffee", Points: 100},
+		},
+		{
+			name:    "negative stock",
+			reward:  &domain.Reward{Name: "Tote bag", Points: 500, Stock: stock(-1)},
+			wantErr: true,
+		},
+		{
+			name:    "negative per member limit",
+			reward:  &domain.Reward{Name: "Tote bag", Points: 500, PerMemberLimit: -1},
+			wantErr: true,
+		},
+		{
+			name:    "free reward",
+			reward:  &domain.Reward{Name: "Tote bag"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewardRepo := new(MockRewardRepository)
+			rewardRepo.On("Create", tt.reward).Return(nil)
+
+			service := NewRewardService(rewardRepo, new(MockUserRepository))
+			err := service.CreateReward(tt.reward)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				rewardRepo.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				rewardRepo.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestRewardService_RedeemReward(t *testing.T) {
+	stock := 1
+	reward := &domain.Reward{ID: uuid.New(), Name: "Tote bag", Points: 500, Stock: &stock, PerMemberLimit: 1, IsActive: true}
+	user := &domain.User{ID: uuid.New(), Points: 900}
+
+	tests := []struct {
+		name        string
+		redeemErr   error
+		balance     int
+		wantErr     error
+		wantBalance int
+	}{
+		{
+			name:        "debits the member and reserves stock",
+			balance:     400,
+			wantBalance: 400,
+		},
+		{
+			name:      "out of stock",
+			redeemErr: repository.ErrOutOfStock,
+			wantErr:   repository.ErrOutOfStock,
+		},
+		{
+			name:      "per member limit reached",
+			redeemErr: repository.ErrRedemptionLimit,
+			wantErr:   repository.ErrRedemptionLimit,
+		},
+		{
+			name:      "insufficient balance",
+			redeemErr: repository.ErrInsufficientPoints,
+			wantErr:   repository.ErrInsufficientPoints,
+		},
+		{
+			name:      "inactive reward",
+			redeemErr: repository.ErrRewardUnavailable,
+			wantErr:   repository.ErrRewardUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewardRepo := new(MockRewardRepository)
+			userRepo := new(MockUserRepository)
+
+			rewardRepo.On("FindByID", reward.ID.String()).Return(reward, nil)
+			userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+			rewardRepo.On("Redeem", mock.MatchedBy(func(redemption *domain.RewardRedemption) bool {
+				return redemption.RewardID == reward.ID && redemption.UserID == user.ID
+			})).Return(tt.balance, tt.redeemErr)
+
+			service := NewRewardService(rewardRepo, userRepo)
+			redemption, balance, err := service.RedeemReward(reward.ID.String(), user.ID.String())
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, redemption)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantBalance, balance)
+				assert.Same(t, reward, redemption.Reward)
+			}
+			rewardRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("stock runs out after the last unit", func(t *testing.T) {
+		rewardRepo := new(MockRewardRepository)
+		userRepo := new(MockUserRepository)
+		rewardRepo.On("FindByID", reward.ID.String()).Return(reward, nil)
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		rewardRepo.On("Redeem", mock.AnythingOfType("*domain.RewardRedemption")).Return(400, nil).Once()
+		rewardRepo.On("Redeem", mock.AnythingOfType("*domain.RewardRedemption")).Return(0, repository.ErrOutOfStock).Once()
+
+		service := NewRewardService(rewardRepo, userRepo)
+		_, balance, err := service.RedeemReward(reward.ID.String(), user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, 400, balance)
+
+		_, _, err = service.RedeemReward(reward.ID.String(), user.ID.String())
+		assert.ErrorIs(t, err, repository.ErrOutOfStock)
+		rewardRepo.AssertNumberOfCalls(t, "Redeem", 2)
+	})
+
+	t.Run("unknown reward", func(t *testing.T) {
+		rewardRepo := new(MockRewardRepository)
+		rewardRepo.On("FindByID", "missing").Return(nil, errors.New("record not found"))
+
+		service := NewRewardService(rewardRepo, new(MockUserRepository))
+		_, _, err := service.RedeemReward("missing", user.ID.String())
+
+		assert.EqualError(t, err, "reward not found")
+		rewardRepo.AssertNotCalled(t, "Redeem", mock.Anything)
+	})
+}
+
+func TestRewardService_UpdateRedemptionStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		changeErr error
+		wantErr   bool
+	}{
+		{name: "fulfils a requested redemption", from: domain.RedemptionRequested, to: domain.RedemptionFulfilled},
+		{name: "cancels a requested redemption", from: domain.RedemptionRequested, to: domain.RedemptionCancelled},
+		{name: "refunds a fulfilled redemption", from: domain.RedemptionFulfilled, to: domain.RedemptionRefunded},
+		{
+			name:      "cannot refund a requested redemption",
+			from:      domain.RedemptionRequested,
+			to:        domain.RedemptionRefunded,
+			changeErr: repository.ErrInvalidStatusChange,
+			wantErr:   true,
+		},
+		{
+			name:      "cannot reopen a cancelled redemption",
+			from:      domain.RedemptionCancelled,
+			to:        domain.RedemptionRequested,
+			changeErr: repository.ErrInvalidStatusChange,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redemption := &domain.RewardRedemption{ID: uuid.New(), RewardID: uuid.New(), UserID: uuid.New(), Points: 500, Status: tt.from}
+			rewardRepo := new(MockRewardRepository)
+			rewardRepo.On("FindRedemption", redemption.ID.String()).Return(redemption, nil)
+			rewardRepo.On("ChangeRedemptionStatus", redemption, tt.to).Return(tt.changeErr)
+
+			service := NewRewardService(rewardRepo, new(MockUserRepository))
+			updated, err := service.UpdateRedemptionStatus(redemption.ID.String(), tt.to, "shipped")
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, repository.ErrInvalidStatusChange)
+				assert.Nil(t, updated)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "shipped", updated.Note)
+			}
+			rewardRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("rejects a malformed id", func(t *testing.T) {
+		rewardRepo := new(MockRewardRepository)
+
+		service := NewRewardService(rewardRepo, new(MockUserRepository))
+		_, err := service.UpdateRedemptionStatus("not-a-uuid", domain.RedemptionFulfilled, "")
+
+		assert.Error(t, err)
+		rewardRepo.AssertNotCalled(t, "FindRedemption", mock.Anything)
+	})
+}
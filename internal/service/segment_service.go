@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+// segmentPreviewSize is how many matching members a preview returns.
+const segmentPreviewSize = 20
+
+type SegmentService interface {
+	CreateSegment(segment *domain.Segment) error
+	GetSegmentByID(id string) (*domain.Segment, error)
+	UpdateSegment(segment *domain.Segment) error
+	DeleteSegment(id string) error
+	ListSegments() ([]*domain.Segment, error)
+	PreviewSegment(id string) (int64, []*domain.User, error)
+	MaterializeSegment(id string) (*domain.Segment, error)
+	MaterializeAll(now time.Time) (int, error)
+	ListSegmentMembers(id string, page, pageSize int) ([]*domain.User, int64, error)
+	SetUserTags(userID string, tags []string) ([]string, error)
+	GetUserTags(userID string) ([]string, error)
+}
+
+type segmentService struct {
+	segmentRepo repository.SegmentRepository
+	userRepo    repository.UserRepository
+}
+
+func NewSegmentService(segmentRepo repository.SegmentRepository, userRepo repository.UserRepository) SegmentService {
+	return &segmentService{
+		segmentRepo: segmentRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// parseSegmentRules decodes and validates segment rules. Unknown rules are
+// rejected so a typo cannot silently widen an audience.
+func parseSegmentRules(raw string) (*domain.SegmentRules, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("segment rules are required")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.DisallowUnknownFields()
+	var rules domain.SegmentRules
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, errors.New("invalid segment rules: " + err.Error())
+	}
+
+	if rules.MinPoints != nil && rules.MaxPoints != nil && *rules.MinPoints > *rules.MaxPoints {
+		return nil, errors.New("min points must not exceed max points")
+	}
+	if rules.PurchasedWithinDays < 0 || rules.NoPurchaseForDays < 0 || rules.SpendDays < 0 {
+		return nil, errors.New("day windows must not be negative")
+	}
+	if rules.PurchasedWithinDays > 0 && rules.NoPurchaseForDays >= rules.PurchasedWithinDays {
+		return nil, errors.New("purchased within days and no purchase for days cannot both hold")
+	}
+	if rules.MinSpend < 0 {
+		return nil, errors.New("min spend must not be negative")
+	}
+	if rules.SpendDays > 0 && rules.MinSpend == 0 {
+		return nil, errors.New("spend days requires min spend")
+	}
+	if rules.SignedUpAfter != nil && rules.SignedUpBefore != nil && !rules.SignedUpAfter.Before(*rules.SignedUpBefore) {
+		return nil, errors.New("signed up after must be before signed up before")
+	}
+	return &rules, nil
+}
+
+func validateSegment(segment *domain.Segment) (*domain.SegmentRules, error) {
+	if segment.Name == "" {
+		return nil, errors.New("segment name is required")
+	}
+	return parseSegmentRules(segment.Rules)
+}
+
+func (s *segmentService) CreateSegment(segment *domain.Segment) error {
+	if _, err := validateSegment(segment); err != nil {
+		return err
+	}
+	return s.segmentRepo.Create(segment)
+}
+
+func (s *segmentService) GetSegmentByID(id string) (*domain.Segment, error) {
+	return s.segmentRepo.FindByID(id)
+}
+
+func (s *segmentService) UpdateSegment(segment *domain.Segment) error {
+	if _, err := validateSegment(segment); err != nil {
+		return err
+	}
+	return s.segmentRepo.Update(segment)
+}
+
+func (s *segmentService) DeleteSegment(id string) error {
+	return s.segmentRepo.Delete(id)
+}
+
+func (s *segmentService) ListSegments() ([]*domain.Segment, error) {
+	return s.segmentRepo.List()
+}
+
+// PreviewSegment evaluates the segment on demand, without touching its
+// materialized members.
+func (s *segmentService) PreviewSegment(id string) (int64, []*domain.User, error) {
+	segment, err := s.segmentRepo.FindByID(id)
+	if err != nil {
+		return 0, nil, errors.New("segment not found")
+	}
+	rules, err := parseSegmentRules(segment.Rules)
+	if err != nil {
+		return 0, nil, err
+	}
+	return s.segmentRepo.Preview(rules, time.Now(), segmentPreviewSize)
+}
+
+func (s *segmentService) MaterializeSegment(id string) (*domain.Segment, error) {
+	segment, err := s.segmentRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("segment not found")
+	}
+	rules, err := parseSegmentRules(segment.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.segmentRepo.Materialize(segment, rules, time.Now()); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// MaterializeAll refreshes the stored members of every active segment and
+// returns how many segments were refreshed. A segment with broken rules is
+// logged and skipped so it does not hold up the others.
+func (s *segmentService) MaterializeAll(now time.Time) (int, error) {
+	segments, err := s.segmentRepo.List()
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, segment := range segments {
+		if !segment.IsActive {
+			continue
+		}
+		rules, err := parseSegmentRules(segment.Rules)
+		if err != nil {
+			log.Printf("segment %s: %v", segment.ID, err)
+			continue
+		}
+		if _, err := s.segmentRepo.Materialize(segment, rules, now); err != nil {
+			return refreshed, err
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+func (s *segmentService) ListSegmentMembers(id string, page, pageSize int) ([]*domain.User, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	return s.segmentRepo.ListMembers(id, (page-1)*pageSize, pageSize)
+}
+
+// SetUserTags replaces a member's tags. Tags are case-insensitive and
+// stored in lower case.
+func (s *segmentService) SetUserTags(userID string, tags []string) ([]string, error) {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if err := s.segmentRepo.SetUserTags(userID, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func (s *segmentService) GetUserTags(userID string) ([]string, error) {
+	return s.segmentRepo.ListUserTags(userID)
+}
+
+// segmentMemberships resolves whether the member belongs to each of the
+// given segments, evaluated live. Inactive, deleted or broken segments have
+// no members.
+func segmentMemberships(segmentRepo repository.SegmentRepository, segmentIDs []uuid.UUID, userID string, now time.Time) (map[uuid.UUID]bool, error) {
+	memberships := make(map[uuid.UUID]bool, len(segmentIDs))
+	for _, id := range segmentIDs {
+		if _, done := memberships[id]; done {
+			continue
+		}
+		memberships[id] = false
+
+		segment, err := segmentRepo.FindByID(id.String())
+		if err != nil || !segment.IsActive {
+			continue
+		}
+		rules, err := parseSegmentRules(segment.Rules)
+		if err != nil {
+			continue
+		}
+		member, err := segmentRepo.IsMember(rules, userID, now)
+		if err != nil {
+			return nil, err
+		}
+		memberships[id] = member
+	}
+	return memberships, nil
+}
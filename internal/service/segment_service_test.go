@@ -0,0 +1,204 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSegmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockSegmentRepository) Create(segment *domain.Segment) error {
+	args := m.Called(segment)
+	return args.Error(0)
+}
+
+func (m *MockSegmentRepository) FindByID(id string) (*domain.Segment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Segment), args.Error(1)
+}
+
+func (m *MockSegmentRepository) Update(segment *domain.Segment) error {
+	args := m.Called(segment)
+	return args.Error(0)
+}
+
+func (m *MockSegmentRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSegmentRepository) List() ([]*domain.Segment, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Segment), args.Error(1)
+}
+
+func (m *MockSegmentRepository) IsMember(rules *domain.SegmentRules, userID string, now time.Time) (bool, error) {
+	args := m.Called(rules, userID, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSegmentRepository) Preview(rules *domain.SegmentRules, now time.Time, limit int) (int64, []*domain.User, error) {
+	args := m.Called(rules, now, limit)
+	if args.Get(1) == nil {
+		return args.Get(0).(int64), nil, args.Error(2)
+	}
+	return args.Get(0).(int64), args.Get(1).([]*domain.User), args.Error(2)
+}
+
+func (m *MockSegmentRepository) Materialize(segment *domain.Segment, rules *domain.SegmentRules, now time.Time) (int, error) {
+	args := m.Called(segment, rules, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSegmentRepository) ListMembers(segmentID string, offset, limit int) ([]*domain.User, int64, error) {
+	args := m.Called(segmentID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockSegmentRepository) SetUserTags(userID string, tags []string) error {
+	args := m.Called(userID, tags)
+	return args.Error(0)
+}
+
+func (m *MockSegmentRepository) ListUserTags(userID string) ([]string, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestParseSegmentRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "lapsed gold members", rules: `{"tiers":["gold"],"no_purchase_for_days":90}`},
+		{name: "big spenders", rules: `{"min_spend":500,"spend_days":30,"tags":["vip"]}`},
+		{name: "empty", rules: "", wantErr: "segment rules are required"},
+		{name: "unknown rule", rules: `{"tier":"gold"}`, wantErr: "invalid segment rules"},
+		{name: "inverted points range", rules: `{"min_points":100,"max_points":50}`, wantErr: "min points must not exceed max points"},
+		{name: "contradicting purchase windows", rules: `{"purchased_within_days":30,"no_purchase_for_days":60}`, wantErr: "cannot both hold"},
+		{name: "spend window without amount", rules: `{"spend_days":30}`, wantErr: "spend days requires min spend"},
+		{name: "inverted signup range", rules: `{"signed_up_after":"2024-06-01T00:00:00Z","signed_up_before":"2024-01-01T00:00:00Z"}`, wantErr: "signed up after must be before"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := parseSegmentRules(tt.rules)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, rules)
+		})
+	}
+}
+
+func TestEvaluateCampaign_TargetSegment(t *testing.T) {
+	now := time.Now()
+	segmentID := uuid.New()
+	campaign := &domain.Campaign{
+		Type:           "bonus_points",
+		Value:          100,
+		IsActive:       true,
+		ApprovalStatus: domain.ApprovalApproved,
+		StartDate:      now.Add(-time.Hour),
+		EndDate:        now.Add(time.Hour),
+		SegmentID:      &segmentID,
+	}
+
+	t.Run("member of the segment", func(t *testing.T) {
+		outcome, err := evaluateCampaign(campaign, &campaignContext{
+			User:     &domain.User{},
+			Now:      now,
+			Segments: map[uuid.UUID]bool{segmentID: true},
+			Types:    builtinTypes(),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, outcome.Points)
+	})
+
+	t.Run("not a member of the segment", func(t *testing.T) {
+		_, err := evaluateCampaign(campaign, &campaignContext{
+			User:     &domain.User{},
+			Now:      now,
+			Segments: map[uuid.UUID]bool{segmentID: false},
+			Types:    builtinTypes(),
+		})
+		rejection, ok := err.(*campaignRejection)
+		assert.True(t, ok)
+		assert.Equal(t, "segment_not_eligible", rejection.Status)
+	})
+}
+
+func TestSegmentMemberships(t *testing.T) {
+	now := time.Now()
+	userID := uuid.New().String()
+	active := &domain.Segment{ID: uuid.New(), IsActive: true, Rules: `{"tags":["vip"]}`}
+	inactive := &domain.Segment{ID: uuid.New(), IsActive: false, Rules: `{"tags":["vip"]}`}
+
+	segmentRepo := new(MockSegmentRepository)
+	segmentRepo.On("FindByID", active.ID.String()).Return(active, nil)
+	segmentRepo.On("FindByID", inactive.ID.String()).Return(inactive, nil)
+	segmentRepo.On("IsMember", &domain.SegmentRules{Tags: []string{"vip"}}, userID, now).Return(true, nil).Once()
+
+	memberships, err := segmentMemberships(segmentRepo, []uuid.UUID{active.ID, inactive.ID, active.ID}, userID, now)
+
+	assert.NoError(t, err)
+	assert.True(t, memberships[active.ID])
+	assert.False(t, memberships[inactive.ID])
+	segmentRepo.AssertExpectations(t)
+}
+
+func TestSegmentService_MaterializeAll(t *testing.T) {
+	now := time.Now()
+	active := &domain.Segment{ID: uuid.New(), IsActive: true, Rules: `{"min_points":1000}`}
+	inactive := &domain.Segment{ID: uuid.New(), IsActive: false, Rules: `{"min_points":1000}`}
+	broken := &domain.Segment{ID: uuid.New(), IsActive: true, Rules: `{"points":1000}`}
+
+	segmentRepo := new(MockSegmentRepository)
+	segmentRepo.On("List").Return([]*domain.Segment{active, inactive, broken}, nil)
+	segmentRepo.On("Materialize", active, mock.AnythingOfType("*domain.SegmentRules"), now).Return(42, nil)
+
+	service := NewSegmentService(segmentRepo, new(MockUserRepository))
+	refreshed, err := service.MaterializeAll(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+	segmentRepo.AssertNumberOfCalls(t, "Materialize", 1)
+}
+
+func TestSegmentService_SetUserTags(t *testing.T) {
+	user := &domain.User{ID: uuid.New()}
+
+	segmentRepo := new(MockSegmentRepository)
+	userRepo := new(MockUserRepository)
+	userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+	segmentRepo.On("SetUserTags", user.ID.String(), []string{"vip", "staff"}).Return(nil)
+
+	service := NewSegmentService(segmentRepo, userRepo)
+	tags, err := service.SetUserTags(user.ID.String(), []string{" VIP ", "staff", "vip", ""})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vip", "staff"}, tags)
+	segmentRepo.AssertExpectations(t)
+}
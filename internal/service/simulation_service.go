@@ -0,0 +1,321 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+type SimulationService interface {
+	SimulateCampaign(campaignID string, draft *domain.Campaign, from, to time.Time) (*CampaignSimulation, error)
+}
+
+// CampaignSimulation is what a campaign would have issued had it run over
+// a past period. Cost values the points at the configured point value and
+// adds the discounts. Skipped counts the replayed purchases the campaign
+// would not have applied to, by the campaign usage status.
+type CampaignSimulation struct {
+	From           time.Time              `json:"from"`
+	To             time.Time              `json:"to"`
+	Purchases      int                    `json:"purchases"`
+	Applications   int                    `json:"applications"`
+	Members        int                    `json:"members"`
+	PointsIssued   int64                  `json:"points_issued"`
+	DiscountIssued float64                `json:"discount_issued"`
+	Cost           float64                `json:"cost"`
+	Skipped        map[string]int         `json:"skipped"`
+	ByTier         []*SimulationBreakdown `json:"by_tier"`
+	ByStore        []*SimulationBreakdown `json:"by_store"`
+}
+
+// SimulationBreakdown is the share of a simulation falling to one tier or
+// store.
+type SimulationBreakdown struct {
+	Name           string  `json:"name"`
+	Applications   int     `json:"applications"`
+	Members        int     `json:"members"`
+	PointsIssued   int64   `json:"points_issued"`
+	DiscountIssued float64 `json:"discount_issued"`
+
+	members map[uuid.UUID]bool
+}
+
+// maxSimulationDays bounds how much history one simulation replays.
+const maxSimulationDays = 366
+
+// simulationBatchSize is how many purchases are loaded at a time.
+const simulationBatchSize = 500
+
+type simulationService struct {
+	purchaseRepo repository.PurchaseRepository
+	campaignRepo repository.CampaignRepository
+	userRepo     repository.UserRepository
+	tierRepo     repository.TierRepository
+	segmentRepo  repository.SegmentRepository
+	types        *CampaignTypeRegistry
+	loyalty      config.LoyaltyConfig
+}
+
+func NewSimulationService(purchaseRepo repository.PurchaseRepository, campaignRepo repository.CampaignRepository, userRepo repository.UserRepository, tierRepo repository.TierRepository, segmentRepo repository.SegmentRepository, types *CampaignTypeRegistry, loyalty config.LoyaltyConfig) SimulationService {
+	return &simulationService{
+		purchaseRepo: purchaseRepo,
+		campaignRepo: campaignRepo,
+		userRepo:     userRepo,
+		tierRepo:     tierRepo,
+		segmentRepo:  segmentRepo,
+		types:        types,
+		loyalty:      loyalty,
+	}
+}
+
+// SimulateCampaign replays the purchases made in [from, to) through the
+// campaign rules without recording anything. The campaign is either a
+// stored one or a draft definition validated like a new campaign. It is
+// treated as live over the whole period, whatever its own dates and approval
+// state, and every member is treated as enrolled. Members are judged on
+// their current tier, and their segments at their first replayed purchase.
+func (s *simulationService) SimulateCampaign(campaignID string, draft *domain.Campaign, from, to time.Time) (*CampaignSimulation, error) {
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	if to.Sub(from) > maxSimulationDays*24*time.Hour {
+		return nil, errors.New("simulation period must not exceed a year")
+	}
+
+	var campaign domain.Campaign
+	switch {
+	case campaignID != "":
+		stored, err := s.campaignRepo.FindByID(campaignID)
+		if err != nil {
+			return nil, errors.New("campaign not found")
+		}
+		campaign = *stored
+	case draft != nil:
+		campaign = *draft
+		if campaign.EndDate.IsZero() {
+			campaign.EndDate = to
+		}
+		if err := validateCampaign(&campaign, s.types, s.loyalty, s.segmentRepo); err != nil {
+			return nil, err
+		}
+		if campaign.ID == uuid.Nil {
+			campaign.ID = uuid.New()
+		}
+	default:
+		return nil, errors.New("campaign id or definition is required")
+	}
+	campaign.IsActive = true
+	campaign.ApprovalStatus = domain.ApprovalApproved
+	campaign.StartDate = from
+	campaign.EndDate = to
+
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sim := newCampaignSimulator(&campaign, from, to, s.loyalty.PointValue)
+	users := make(map[uuid.UUID]*domain.User)
+	segments := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for offset := 0; ; offset += simulationBatchSize {
+		purchases, err := s.purchaseRepo.ListBetween(from, to, offset, simulationBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, purchase := range purchases {
+			user, ok := users[purchase.UserID]
+			if !ok {
+				if user, err = s.userRepo.FindByID(purchase.UserID.String()); err != nil {
+					user = nil
+				}
+				users[purchase.UserID] = user
+			}
+			if user == nil {
+				continue
+			}
+
+			if _, ok := segments[user.ID]; !ok {
+				var ids []uuid.UUID
+				if campaign.SegmentID != nil {
+					ids = append(ids, *campaign.SegmentID)
+				}
+				if segments[user.ID], err = segmentMemberships(s.segmentRepo, ids, user.ID.String(), purchase.PurchasedAt); err != nil {
+					return nil, err
+				}
+			}
+
+			sim.add(purchase, &campaignContext{
+				User:           user,
+				Tiers:          tiers,
+				PurchaseAmount: purchase.Total,
+				Now:            purchase.PurchasedAt,
+				Segments:       segments[user.ID],
+				Enrolled:       map[uuid.UUID]bool{campaign.ID: true},
+				Types:          s.types,
+			})
+		}
+
+		if len(purchases) < simulationBatchSize {
+			break
+		}
+	}
+	return sim.result(), nil
+}
+
+// campaignSimulator accumulates what one campaign yields on a sequence of
+// purchases in time order, applying the campaign's per-member caps as the
+// purchase repository does.
+type campaignSimulator struct {
+	campaign   *domain.Campaign
+	location   *time.Location
+	pointValue float64
+	report     *CampaignSimulation
+	usage      map[uuid.UUID]*simulatedUsage
+	tiers      map[string]*SimulationBreakdown
+	stores     map[string]*SimulationBreakdown
+}
+
+// simulatedUsage is a member's use of the campaign so far.
+type simulatedUsage struct {
+	Day    time.Time
+	Week   time.Time
+	Daily  int
+	Weekly int
+	Total  int
+	Points int
+}
+
+func newCampaignSimulator(campaign *domain.Campaign, from, to time.Time, pointValue float64) *campaignSimulator {
+	location, err := campaignLocation(campaign)
+	if err != nil {
+		location = time.UTC
+	}
+	return &campaignSimulator{
+		campaign:   campaign,
+		location:   location,
+		pointValue: pointValue,
+		report:     &CampaignSimulation{From: from, To: to, Skipped: make(map[string]int)},
+		usage:      make(map[uuid.UUID]*simulatedUsage),
+		tiers:      make(map[string]*SimulationBreakdown),
+		stores:     make(map[string]*SimulationBreakdown),
+	}
+}
+
+func (s *campaignSimulator) add(purchase *domain.Purchase, ctx *campaignContext) {
+	s.report.Purchases++
+
+	outcome, err := evaluateCampaign(s.campaign, ctx)
+	if err != nil {
+		var rejection *campaignRejection
+		if errors.As(err, &rejection) {
+			s.report.Skipped[rejection.Status]++
+		}
+		return
+	}
+
+	points, ok := s.limit(ctx.User.ID, purchase.PurchasedAt, outcome.Points)
+	if !ok {
+		s.report.Skipped["cap_reached"]++
+		return
+	}
+	discount := outcome.Discount
+
+	s.report.Applications++
+	s.report.PointsIssued += int64(points)
+	s.report.DiscountIssued += discount
+	for _, breakdown := range []*SimulationBreakdown{
+		simulationBreakdown(s.tiers, ctx.User.Tier),
+		simulationBreakdown(s.stores, purchase.Store),
+	} {
+		breakdown.Applications++
+		breakdown.PointsIssued += int64(points)
+		breakdown.DiscountIssued += discount
+		breakdown.members[ctx.User.ID] = true
+	}
+}
+
+// limit applies the per-member caps to an application at t, returning the
+// points it may earn, or false when a cap leaves nothing.
+func (s *campaignSimulator) limit(userID uuid.UUID, t time.Time, points int) (int, bool) {
+	usage, ok := s.usage[userID]
+	if !ok {
+		usage = &simulatedUsage{}
+		s.usage[userID] = usage
+	}
+
+	local := t.In(s.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	if !day.Equal(usage.Day) {
+		usage.Day, usage.Daily = day, 0
+	}
+	if !week.Equal(usage.Week) {
+		usage.Week, usage.Weekly = week, 0
+	}
+
+	reached := func(max, count int) bool { return max > 0 && count >= max }
+	if reached(s.campaign.MaxApplicationsPerDay, usage.Daily) ||
+		reached(s.campaign.MaxApplicationsPerWeek, usage.Weekly) ||
+		reached(s.campaign.MaxApplicationsPerMember, usage.Total) {
+		return 0, false
+	}
+	if s.campaign.MaxPointsPerMember > 0 && points > 0 {
+		left := s.campaign.MaxPointsPerMember - usage.Points
+		if left <= 0 {
+			return 0, false
+		}
+		if points > left {
+			points = left
+		}
+	}
+
+	usage.Daily++
+	usage.Weekly++
+	usage.Total++
+	usage.Points += points
+	return points, true
+}
+
+func (s *campaignSimulator) result() *CampaignSimulation {
+	report := s.report
+	for _, usage := range s.usage {
+		if usage.Total > 0 {
+			report.Members++
+		}
+	}
+	report.Cost = float64(report.PointsIssued)*s.pointValue + report.DiscountIssued
+	report.ByTier = sortedBreakdowns(s.tiers)
+	report.ByStore = sortedBreakdowns(s.stores)
+	return report
+}
+
+func simulationBreakdown(breakdowns map[string]*SimulationBreakdown, name string) *SimulationBreakdown {
+	breakdown, ok := breakdowns[name]
+	if !ok {
+		breakdown = &SimulationBreakdown{Name: name, members: make(map[uuid.UUID]bool)}
+		breakdowns[name] = breakdown
+	}
+	return breakdown
+}
+
+// sortedBreakdowns lists the breakdowns by points issued, highest first.
+func sortedBreakdowns(breakdowns map[string]*SimulationBreakdown) []*SimulationBreakdown {
+	sorted := make([]*SimulationBreakdown, 0, len(breakdowns))
+	for _, breakdown := range breakdowns {
+		breakdown.Members = len(breakdown.members)
+		sorted = append(sorted, breakdown)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].PointsIssued != sorted[j].PointsIssued {
+			return sorted[i].PointsIssued > sorted[j].PointsIssued
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
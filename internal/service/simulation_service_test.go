@@ -0,0 +1,104 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSimulationService_SimulateCampaign(t *testing.T) {
+	from := time.Date(2024, 11, 25, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)
+	gold := &domain.User{ID: uuid.New(), Tier: "gold"}
+	silver := &domain.User{ID: uuid.New(), Tier: "silver"}
+	purchase := func(user *domain.User, store string, total float64, day int) *domain.Purchase {
+		return &domain.Purchase{
+			ID:          uuid.New(),
+			UserID:      user.ID,
+			Store:       store,
+			Total:       total,
+			PurchasedAt: from.Add(time.Duration(day)*24*time.Hour + 12*time.Hour),
+		}
+	}
+	purchases := []*domain.Purchase{
+		purchase(gold, "downtown", 200, 0),
+		purchase(gold, "downtown", 150, 0),
+		purchase(gold, "airport", 300, 1),
+		purchase(silver, "airport", 120, 2),
+		purchase(silver, "airport", 40, 3),
+	}
+
+	// A draft for next year is replayed as if it had run over the period
+	draft := &domain.Campaign{
+		Name:                  "Black Friday",
+		Type:                  "bonus_points",
+		Value:                 100,
+		StartDate:             time.Date(2025, 11, 28, 0, 0, 0, 0, time.UTC),
+		EndDate:               time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Conditions:            `{"min_purchase": 100}`,
+		MaxApplicationsPerDay: 1,
+	}
+
+	purchaseRepo := new(MockPurchaseRepository)
+	userRepo := new(MockUserRepository)
+	tierRepo := new(MockTierRepository)
+	purchaseRepo.On("ListBetween", from, to, 0, simulationBatchSize).Return(purchases, nil)
+	userRepo.On("FindByID", gold.ID.String()).Return(gold, nil).Once()
+	userRepo.On("FindByID", silver.ID.String()).Return(silver, nil).Once()
+	tierRepo.On("List").Return([]*domain.Tier{
+		{Name: "silver", Rank: 1, PointsMultiplier: 1},
+		{Name: "gold", Rank: 2, PointsMultiplier: 1.5},
+	}, nil)
+
+	service := NewSimulationService(purchaseRepo, new(MockCampaignRepository), userRepo, tierRepo, new(MockSegmentRepository), builtinTypes(), config.LoyaltyConfig{PointValue: 0.01, DefaultTimezone: "UTC"})
+	simulation, err := service.SimulateCampaign("", draft, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, simulation.Purchases)
+	assert.Equal(t, 3, simulation.Applications)
+	assert.Equal(t, 2, simulation.Members)
+	assert.Equal(t, int64(400), simulation.PointsIssued)
+	assert.InDelta(t, 4.0, simulation.Cost, 0.001)
+	assert.Equal(t, map[string]int{"cap_reached": 1, "min_purchase_not_met": 1}, simulation.Skipped)
+
+	assert.Len(t, simulation.ByTier, 2)
+	assert.Equal(t, "gold", simulation.ByTier[0].Name)
+	assert.Equal(t, int64(300), simulation.ByTier[0].PointsIssued)
+	assert.Equal(t, 1, simulation.ByTier[0].Members)
+	assert.Equal(t, "airport", simulation.ByStore[0].Name)
+	assert.Equal(t, 2, simulation.ByStore[0].Applications)
+	assert.Equal(t, 2, simulation.ByStore[0].Members)
+
+	// Nothing is recorded
+	purchaseRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	userRepo.AssertExpectations(t)
+}
+
+func TestSimulationService_SimulateCampaign_Invalid(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := NewSimulationService(new(MockPurchaseRepository), new(MockCampaignRepository), new(MockUserRepository), new(MockTierRepository), new(MockSegmentRepository), builtinTypes(), config.LoyaltyConfig{})
+
+	tests := []struct {
+		name    string
+		draft   *domain.Campaign
+		to      time.Time
+		wantErr string
+	}{
+		{"empty period", &domain.Campaign{Type: "bonus_points"}, from, "from must be before to"},
+		{"period too long", &domain.Campaign{Type: "bonus_points"}, from.AddDate(2, 0, 0), "simulation period must not exceed a year"},
+		{"no campaign", nil, from.AddDate(0, 1, 0), "campaign id or definition is required"},
+		{"invalid draft", &domain.Campaign{Type: "cashback"}, from.AddDate(0, 1, 0), "invalid campaign type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SimulateCampaign("", tt.draft, from, tt.to)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+type TemplateService interface {
+	CreateTemplate(template *domain.Template) error
+	GetTemplate(id string) (*domain.Template, error)
+	UpdateTemplate(template *domain.Template) error
+	DeleteTemplate(id string) error
+	ListTemplates(kind string) ([]*domain.Template, error)
+	UseTemplate(id string, params TemplateParams, createdBy *uuid.UUID) (*TemplateResult, error)
+}
+
+// TemplateParams fill a template's placeholders, and adjust a campaign or
+// coupon being cloned.
+type TemplateParams struct {
+	StartDate  *time.Time `json:"start_date"`
+	EndDate    *time.Time `json:"end_date"`
+	Value      *float64   `json:"value"`
+	NameSuffix string     `json:"name_suffix"`
+}
+
+// TemplateResult is the draft created from a template.
+type TemplateResult struct {
+	Campaign *domain.Campaign `json:"campaign,omitempty"`
+	Coupon   *domain.Coupon   `json:"coupon,omitempty"`
+}
+
+type templateService struct {
+	templateRepo    repository.TemplateRepository
+	campaignService CampaignService
+	couponService   CouponService
+}
+
+func NewTemplateService(templateRepo repository.TemplateRepository, campaignService CampaignService, couponService CouponService) TemplateService {
+	return &templateService{
+		templateRepo:    templateRepo,
+		campaignService: campaignService,
+		couponService:   couponService,
+	}
+}
+
+func (s *templateService) CreateTemplate(template *domain.Template) error {
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+	return s.templateRepo.Create(template)
+}
+
+func (s *templateService) GetTemplate(id string) (*domain.Template, error) {
+	return s.templateRepo.FindByID(id)
+}
+
+func (s *templateService) UpdateTemplate(template *domain.Template) error {
+	existing, err := s.templateRepo.FindByID(template.ID.String())
+	if err != nil {
+		return errors.New("template not found")
+	}
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+
+	template.CreatedBy = existing.CreatedBy
+	template.CreatedAt = existing.CreatedAt
+	return s.templateRepo.Update(template)
+}
+
+func (s *templateService) DeleteTemplate(id string) error {
+	return s.templateRepo.Delete(id)
+}
+
+func (s *templateService) ListTemplates(kind string) ([]*domain.Template, error) {
+	return s.templateRepo.List(kind)
+}
+
+// UseTemplate creates a draft campaign or coupon from the template. The draft
+// goes through the same validation as one created directly.
+func (s *templateService) UseTemplate(id string, params TemplateParams, createdBy *uuid.UUID) (*TemplateResult, error) {
+	template, err := s.templateRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("template not found")
+	}
+
+	definition, err := renderTemplate(template.Definition, params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch template.Kind {
+	case domain.TemplateCampaign:
+		var campaign domain.Campaign
+		if err := json.Unmarshal(definition, &campaign); err != nil {
+			return nil, errors.New("template definition is not a valid campaign")
+		}
+		campaign.ID = uuid.Nil
+		campaign.CreatedBy = createdBy
+		if err := s.campaignService.CreateCampaign(&campaign); err != nil {
+			return nil, err
+		}
+		return &TemplateResult{Campaign: &campaign}, nil
+	case domain.TemplateCoupon:
+		var coupon domain.Coupon
+		if err := json.Unmarshal(definition, &coupon); err != nil {
+			return nil, errors.New("template definition is not a valid coupon")
+		}
+		coupon.ID = uuid.Nil
+		coupon.UsedCount = 0
+		coupon.CreatedBy = createdBy
+		if err := s.couponService.CreateCoupon(&coupon); err != nil {
+			return nil, err
+		}
+		return &TemplateResult{Coupon: &coupon}, nil
+	}
+	return nil, errors.New("invalid template kind")
+}
+
+// validateTemplate checks the template renders into its kind with every
+// parameter given, so mistakes surface when it is saved rather than used.
+func validateTemplate(template *domain.Template) error {
+	if template.Name == "" {
+		return errors.New("template name is required")
+	}
+
+	now := time.Now()
+	later := now.Add(24 * time.Hour)
+	value := 1.0
+	definition, err := renderTemplate(template.Definition, TemplateParams{
+		StartDate:  &now,
+		EndDate:    &later,
+		Value:      &value,
+		NameSuffix: "sample",
+	})
+	if err != nil {
+		return err
+	}
+
+	switch template.Kind {
+	case domain.TemplateCampaign:
+		if err := json.Unmarshal(definition, &domain.Campaign{}); err != nil {
+			return errors.New("template definition is not a valid campaign")
+		}
+	case domain.TemplateCoupon:
+		if err := json.Unmarshal(definition, &domain.Coupon{}); err != nil {
+			return errors.New("template definition is not a valid coupon")
+		}
+	default:
+		return errors.New("invalid template kind")
+	}
+	return nil
+}
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// renderTemplate fills the placeholders in the string values of a template
+// definition. A string holding nothing but {{value}} becomes a number, so
+// the value can fill numeric fields; other placeholders are filled in as
+// text, with dates in RFC 3339.
+func renderTemplate(definition string, params TemplateParams) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(definition), &doc); err != nil {
+		return nil, errors.New("template definition must be a JSON object")
+	}
+
+	filled, err := fillPlaceholders(doc, params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(filled)
+}
+
+func fillPlaceholders(node interface{}, params TemplateParams) (interface{}, error) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			filled, err := fillPlaceholders(child, params)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = filled
+		}
+	case []interface{}:
+		for i, child := range v {
+			filled, err := fillPlaceholders(child, params)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = filled
+		}
+	case string:
+		return fillString(v, params)
+	}
+	return node, nil
+}
+
+func fillString(s string, params TemplateParams) (interface{}, error) {
+	match := templatePlaceholder.FindStringSubmatch(s)
+	if match == nil {
+		return s, nil
+	}
+	if match[0] == strings.TrimSpace(s) && match[1] == "value" {
+		if params.Value == nil {
+			return nil, errors.New("template parameter value is required")
+		}
+		return *params.Value, nil
+	}
+
+	var err error
+	filled := templatePlaceholder.ReplaceAllStringFunc(s, func(placeholder string) string {
+		text, textErr := placeholderText(templatePlaceholder.FindStringSubmatch(placeholder)[1], params)
+		if textErr != nil && err == nil {
+			err = textErr
+		}
+		return text
+	})
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimSpace(filled), nil
+}
+
+func placeholderText(name string, params TemplateParams) (string, error) {
+	switch name {
+	case "start_date":
+		if params.StartDate == nil {
+			return "", errors.New("template parameter start_date is required")
+		}
+		return params.StartDate.Format(time.RFC3339), nil
+	case "end_date":
+		if params.EndDate == nil {
+			return "", errors.New("template parameter end_date is required")
+		}
+		return params.EndDate.Format(time.RFC3339), nil
+	case "value":
+		if params.Value == nil {
+			return "", errors.New("template parameter value is required")
+		}
+		return strconv.FormatFloat(*params.Value, 'f', -1, 64), nil
+	case "name_suffix":
+		return params.NameSuffix, nil
+	}
+	return "", fmt.Errorf("unknown template placeholder %s", name)
+}
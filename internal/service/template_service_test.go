@@ -0,0 +1,80 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 8, 31, 23, 59, 59, 0, time.UTC)
+	value := 2.5
+
+	tests := []struct {
+		name       string
+		definition string
+		params     TemplateParams
+		expected   map[string]interface{}
+		wantErr    string
+	}{
+		{
+			name:       "fills every placeholder",
+			definition: `{"name": "Summer Bonus {{name_suffix}}", "value": "{{value}}", "start_date": "{{start_date}}", "end_date": "{{ end_date }}", "conditions": "{\"min_purchase\": 100}"}`,
+			params:     TemplateParams{StartDate: &start, EndDate: &end, Value: &value, NameSuffix: "2025"},
+			expected: map[string]interface{}{
+				"name":       "Summer Bonus 2025",
+				"value":      2.5,
+				"start_date": "2025-06-01T00:00:00Z",
+				"end_date":   "2025-08-31T23:59:59Z",
+				"conditions": `{"min_purchase": 100}`,
+			},
+		},
+		{
+			name:       "value inside text",
+			definition: `{"description": "{{value}}x points", "conditions": "{\"min_purchase\": {{value}}}"}`,
+			params:     TemplateParams{Value: &value},
+			expected: map[string]interface{}{
+				"description": "2.5x points",
+				"conditions":  `{"min_purchase": 2.5}`,
+			},
+		},
+		{
+			name:       "empty suffix is trimmed",
+			definition: `{"name": "Summer Bonus {{name_suffix}}"}`,
+			expected:   map[string]interface{}{"name": "Summer Bonus"},
+		},
+		{
+			name:       "missing parameter",
+			definition: `{"start_date": "{{start_date}}"}`,
+			wantErr:    "template parameter start_date is required",
+		},
+		{
+			name:       "unknown placeholder",
+			definition: `{"name": "{{season}}"}`,
+			wantErr:    "unknown template placeholder season",
+		},
+		{
+			name:       "not an object",
+			definition: `["{{value}}"]`,
+			wantErr:    "template definition must be a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := renderTemplate(tt.definition, tt.params)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			var doc map[string]interface{}
+			assert.NoError(t, json.Unmarshal(rendered, &doc))
+			assert.Equal(t, tt.expected, doc)
+		})
+	}
+}
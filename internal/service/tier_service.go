@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type TierService interface {
+	CreateTier(tier *domain.Tier) error
+	UpdateTier(tier *domain.Tier) error
+	DeleteTier(id string) error
+	ListTiers() ([]*domain.Tier, error)
+	GetMembership(userID string) (*Membership, error)
+	GetTierHistory(userID string) ([]*domain.TierHistory, error)
+	ReevaluateAll(now time.Time) (int, error)
+}
+
+// Membership describes a member's tier and progress towards the next one.
+type Membership struct {
+	Tier             string     `json:"membership_level"`
+	QualifyingPoints int        `json:"qualifying_points"`
+	NextTier         string     `json:"next_level,omitempty"`
+	NextTierPoints   int        `json:"next_level_points"`
+	GraceUntil       *time.Time `json:"grace_until,omitempty"`
+}
+
+type tierService struct {
+	tierRepo   repository.TierRepository
+	userRepo   repository.UserRepository
+	pointsRepo repository.PointsRepository
+}
+
+func NewTierService(tierRepo repository.TierRepository, userRepo repository.UserRepository, pointsRepo repository.PointsRepository) TierService {
+	return &tierService{
+		tierRepo:   tierRepo,
+		userRepo:   userRepo,
+		pointsRepo: pointsRepo,
+	}
+}
+
+func validateTier(tier *domain.Tier) error {
+	if tier.Name == "" {
+		return errors.New("tier name is required")
+	}
+	if tier.QualifyingPoints < 0 {
+		return errors.New("qualifying points must not be negative")
+	}
+	if tier.WindowDays <= 0 {
+		tier.WindowDays = 365
+	}
+	if tier.PointsMultiplier == 0 {
+		tier.PointsMultiplier = 1
+	}
+	if tier.PointsMultiplier < 0 {
+		return errors.New("points multiplier must not be negative")
+	}
+	if tier.GraceDays < 0 {
+		return errors.New("grace days must not be negative")
+	}
+	return nil
+}
+
+func (s *tierService) CreateTier(tier *domain.Tier) error {
+	if err := validateTier(tier); err != nil {
+		return err
+	}
+	return s.tierRepo.Create(tier)
+}
+
+func (s *tierService) UpdateTier(tier *domain.Tier) error {
+	if err := validateTier(tier); err != nil {
+		return err
+	}
+	return s.tierRepo.Update(tier)
+}
+
+func (s *tierService) DeleteTier(id string) error {
+	return s.tierRepo.Delete(id)
+}
+
+func (s *tierService) ListTiers() ([]*domain.Tier, error) {
+	return s.tierRepo.List()
+}
+
+func (s *tierService) GetMembership(userID string) (*Membership, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	membership := &Membership{Tier: user.Tier, GraceUntil: user.TierGraceUntil}
+	current := tierRank(tiers, user.Tier)
+	for _, tier := range tiers {
+		if tier.Rank <= current {
+			continue
+		}
+
+		// Progress is measured over the window of the tier being worked towards
+		earned, err := s.pointsRepo.SumEarned(userID, time.Now().AddDate(0, 0, -tier.WindowDays))
+		if err != nil {
+			return nil, err
+		}
+		membership.QualifyingPoints = earned
+		membership.NextTier = tier.Name
+		membership.NextTierPoints = tier.QualifyingPoints
+		break
+	}
+
+	return membership, nil
+}
+
+func (s *tierService) GetTierHistory(userID string) ([]*domain.TierHistory, error) {
+	return s.tierRepo.ListHistory(userID)
+}
+
+// ReevaluateAll re-qualifies every member and returns the number of members
+// whose tier or grace period changed.
+func (s *tierService) ReevaluateAll(now time.Time) (int, error) {
+	tiers, err := s.tierRepo.List()
+	if err != nil || len(tiers) == 0 {
+		return 0, err
+	}
+
+	const batchSize = 500
+	changed := 0
+	for offset := 0; ; offset += batchSize {
+		users, err := s.userRepo.List(offset, batchSize)
+		if err != nil {
+			return changed, err
+		}
+
+		for _, user := range users {
+			ok, err := s.reevaluate(user, tiers, now)
+			if err != nil {
+				return changed, err
+			}
+			if ok {
+				changed++
+			}
+		}
+
+		if len(users) < batchSize {
+			return changed, nil
+		}
+	}
+}
+
+func (s *tierService) reevaluate(user *domain.User, tiers []*domain.Tier, now time.Time) (bool, error) {
+	qualified, err := s.qualifyingTier(user, tiers, now)
+	if err != nil {
+		return false, err
+	}
+
+	current := tierRank(tiers, user.Tier)
+	history := &domain.TierHistory{UserID: user.ID, FromTier: user.Tier, ToTier: qualified.Name}
+
+	switch {
+	case qualified.Rank > current:
+		history.Reason = "upgraded"
+		user.Tier = qualified.Name
+		user.TierGraceUntil = nil
+
+	case qualified.Rank == current:
+		if user.TierGraceUntil == nil {
+			return false, nil
+		}
+		// Requalified during the grace period
+		user.TierGraceUntil = nil
+		history = nil
+
+	case user.TierGraceUntil == nil:
+		// Fell below the current tier, keep it for the grace period first
+		graceDays := 0
+		for _, tier := range tiers {
+			if tier.Name == user.Tier {
+				graceDays = tier.GraceDays
+			}
+		}
+		if graceDays > 0 {
+			graceUntil := now.AddDate(0, 0, graceDays)
+			user.TierGraceUntil = &graceUntil
+			history.ToTier = user.Tier
+			history.Reason = "grace_started"
+			break
+		}
+		history.Reason = "downgraded"
+		user.Tier = qualified.Name
+
+	case now.After(*user.TierGraceUntil):
+		history.Reason = "downgraded"
+		user.Tier = qualified.Name
+		user.TierGraceUntil = nil
+
+	default:
+		// Still within the grace period
+		return false, nil
+	}
+
+	return true, s.tierRepo.ChangeUserTier(user, history)
+}
+
+// qualifyingTier returns the highest tier whose threshold the member reaches.
+// The lowest tier is always reached.
+func (s *tierService) qualifyingTier(user *domain.User, tiers []*domain.Tier, now time.Time) (*domain.Tier, error) {
+	qualified := tiers[0]
+	for _, tier := range tiers[1:] {
+		earned, err := s.pointsRepo.SumEarned(user.ID.String(), now.AddDate(0, 0, -tier.WindowDays))
+		if err != nil {
+			return nil, err
+		}
+		if earned >= tier.QualifyingPoints {
+			qualified = tier
+		}
+	}
+	return qualified, nil
+}
+
+// tierRank returns the rank of the named tier, or -1 for members without a
+// known tier.
+func tierRank(tiers []*domain.Tier, name string) int {
+	for _, tier := range tiers {
+		if tier.Name == name {
+			return tier.Rank
+		}
+	}
+	return -1
+}
+
+// meetsTierRequirement reports whether a member in memberTier satisfies the
+// tier conditions of a campaign or coupon: "min_tier" names the lowest
+// eligible tier and "tiers" lists the eligible tiers explicitly.
+func meetsTierRequirement(tiers []*domain.Tier, memberTier string, conditions map[string]interface{}) bool {
+	if minTier, ok := conditions["min_tier"].(string); ok && minTier != "" {
+		if tierRank(tiers, memberTier) < tierRank(tiers, minTier) || tierRank(tiers, memberTier) == -1 {
+			return false
+		}
+	}
+
+	if allowed, ok := conditions["tiers"].([]interface{}); ok {
+		for _, name := range allowed {
+			if name == memberTier {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// tierMultiplier returns the points multiplier benefit of the named tier.
+func tierMultiplier(tiers []*domain.Tier, name string) float64 {
+	for _, tier := range tiers {
+		if tier.Name == name && tier.PointsMultiplier > 0 {
+			return tier.PointsMultiplier
+		}
+	}
+	return 1
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTierRepository struct {
+	mock.Mock
+}
+
+func (m *MockTierRepository) Create(tier *domain.Tier) error {
+	args := m.Called(tier)
+	return args.Error(0)
+}
+
+func (m *MockTierRepository) FindByName(name string) (*domain.Tier, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tier), args.Error(1)
+}
+
+func (m *MockTierRepository) Update(tier *domain.Tier) error {
+	args := m.Called(tier)
+	return args.Error(0)
+}
+
+func (m *MockTierRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTierRepository) List() ([]*domain.Tier, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Tier), args.Error(1)
+}
+
+func (m *MockTierRepository) ChangeUserTier(user *domain.User, history *domain.TierHistory) error {
+	args := m.Called(user, history)
+	return args.Error(0)
+}
+
+func (m *MockTierRepository) ListHistory(userID string) ([]*domain.TierHistory, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TierHistory), args.Error(1)
+}
+
+func TestTierService_ReevaluateAll(t *testing.T) {
+	now := time.Now()
+	expiredGrace := now.AddDate(0, 0, -1)
+	tiers := []*domain.Tier{
+		{Name: "silver", Rank: 1, QualifyingPoints: 0, WindowDays: 365},
+		{Name: "gold", Rank: 2, QualifyingPoints: 1000, WindowDays: 365, GraceDays: 30},
+	}
+
+	tests := []struct {
+		name        string
+		user        *domain.User
+		earned      int
+		wantChanged int
+		wantTier    string
+		wantReason  string
+		wantGrace   bool
+	}{
+		{
+			name:        "upgrade on reaching the threshold",
+			user:        &domain.User{ID: uuid.New(), Tier: "silver"},
+			earned:      1200,
+			wantChanged: 1,
+			wantTier:    "gold",
+			wantReason:  "upgraded",
+		},
+		{
+			name:        "grace period before downgrade",
+			user:        &domain.User{ID: uuid.New(), Tier: "gold"},
+			earned:      200,
+			wantChanged: 1,
+			wantTier:    "gold",
+			wantReason:  "grace_started",
+			wantGrace:   true,
+		},
+		{
+			name:        "downgrade once grace period lapsed",
+			user:        &domain.User{ID: uuid.New(), Tier: "gold", TierGraceUntil: &expiredGrace},
+			earned:      200,
+			wantChanged: 1,
+			wantTier:    "silver",
+			wantReason:  "downgraded",
+		},
+		{
+			name:        "unchanged tier",
+			user:        &domain.User{ID: uuid.New(), Tier: "gold"},
+			earned:      1500,
+			wantChanged: 0,
+			wantTier:    "gold",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tierRepo := new(MockTierRepository)
+			userRepo := new(MockUserRepository)
+			pointsRepo := new(MockPointsRepository)
+
+			tierRepo.On("List").Return(tiers, nil)
+			userRepo.On("List", 0, 500).Return([]*domain.User{tt.user}, nil)
+			pointsRepo.On("SumEarned", tt.user.ID.String(), mock.AnythingOfType("time.Time")).Return(tt.earned, nil)
+			tierRepo.On("ChangeUserTier", tt.user, mock.Anything).Return(nil)
+
+			service := NewTierService(tierRepo, userRepo, pointsRepo)
+			changed, err := service.ReevaluateAll(now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.wantTier, tt.user.Tier)
+			assert.Equal(t, tt.wantGrace, tt.user.TierGraceUntil != nil)
+			if tt.wantReason != "" {
+				tierRepo.AssertCalled(t, "ChangeUserTier", tt.user, mock.MatchedBy(func(history *domain.TierHistory) bool {
+					return history.Reason == tt.wantReason
+				}))
+			} else {
+				tierRepo.AssertNotCalled(t, "ChangeUserTier", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/middleware"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+)
+
+type TransactionService interface {
+	RecordPurchase(purchase *domain.Purchase, memberID, memberEmail string) (*PurchaseResult, error)
+	GetPurchase(orderID string) (*domain.Purchase, error)
+}
+
+// PurchaseResult describes the points a purchase earned.
+type PurchaseResult struct {
+	Purchase     *domain.Purchase  `json:"purchase"`
+	PointsEarned int               `json:"points_earned"`
+	Balance      int               `json:"balance"`
+	Campaigns    []AppliedCampaign `json:"campaigns"`
+	Duplicate    bool              `json:"duplicate"`
+}
+
+// AppliedCampaign is a campaign that applied to a purchase.
+type AppliedCampaign struct {
+	CampaignID uuid.UUID `json:"campaign_id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Result     float64   `json:"result"`
+	Points     int       `json:"points"`
+}
+
+// PurchaseObserver is told about every newly recorded purchase and the
+// campaigns it earned, after its points have been credited. Duplicate
+// submissions are not reported.
+type PurchaseObserver interface {
+	PurchaseRecorded(purchase *domain.Purchase, user *domain.User, campaigns []AppliedCampaign)
+}
+
+type transactionService struct {
+	purchaseRepo   repository.PurchaseRepository
+	userRepo       repository.UserRepository
+	campaignRepo   repository.CampaignRepository
+	tierRepo       repository.TierRepository
+	segmentRepo    repository.SegmentRepository
+	experimentRepo repository.ExperimentRepository
+	types          *CampaignTypeRegistry
+	loyalty        config.LoyaltyConfig
+	observers      []PurchaseObserver
+}
+
+func NewTransactionService(purchaseRepo repository.PurchaseRepository, userRepo repository.UserRepository, campaignRepo repository.CampaignRepository, tierRepo repository.TierRepository, segmentRepo repository.SegmentRepository, experimentRepo repository.ExperimentRepository, types *CampaignTypeRegistry, loyalty config.LoyaltyConfig, observers ...PurchaseObserver) TransactionService {
+	return &transactionService{
+		purchaseRepo:   purchaseRepo,
+		userRepo:       userRepo,
+		campaignRepo:   campaignRepo,
+		tierRepo:       tierRepo,
+		segmentRepo:    segmentRepo,
+		experimentRepo: experimentRepo,
+		types:          types,
+		loyalty:        loyalty,
+		observers:      observers,
+	}
+}
+
+func (s *transactionService) RecordPurchase(purchase *domain.Purchase, memberID, memberEmail string) (*PurchaseResult, error) {
+	if err := validatePurchase(purchase); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findMember(memberID, memberEmail)
+	if err != nil {
+		return nil, err
+	}
+	purchase.UserID = user.ID
+
+	// Orders only ever earn once
+	if existing, err := s.purchaseRepo.FindByOrderID(purchase.OrderID); err == nil {
+		return s.duplicateResult(existing)
+	}
+
+	tiers, err := s.tierRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	campaigns, err := s.campaignRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var segmentIDs []uuid.UUID
+	for _, campaign := range campaigns {
+		if campaign.SegmentID != nil {
+			segmentIDs = append(segmentIDs, *campaign.SegmentID)
+		}
+	}
+	segments, err := segmentMemberships(s.segmentRepo, segmentIDs, user.ID.String(), purchase.PurchasedAt)
+	if err != nil {
+		return nil, err
+	}
+	arms, err := campaignArms(s.experimentRepo, campaigns, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	enrolled, err := campaignEnrollments(s.campaignRepo, campaigns, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Base earning rule
+	basePoints := int(math.Floor(purchase.Total * s.loyalty.PointsPerCurrencyUnit * tierMultiplier(tiers, user.Tier)))
+	var entries []*domain.PointsTransaction
+	if basePoints > 0 {
+		key := "purchase:" + purchase.OrderID + ":base"
+		entries = append(entries, &domain.PointsTransaction{
+			UserID:         user.ID,
+			Type:           domain.PointsEarn,
+			Points:         basePoints,
+			OrderRef:       purchase.OrderID,
+			Store:          purchase.Store,
+			IdempotencyKey: &key,
+		})
+	}
+
+	// Every eligible active campaign, with overlapping campaigns resolved
+	// by their group policies
+	policies, err := campaignPolicies(s.campaignRepo)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &campaignContext{
+		User:           user,
+		Tiers:          tiers,
+		PurchaseAmount: purchase.Total,
+		Now:            purchase.PurchasedAt,
+		Segments:       segments,
+		Arms:           arms,
+		Enrolled:       enrolled,
+		Types:          s.types,
+	}
+	result := &PurchaseResult{Purchase: purchase, PointsEarned: basePoints, Campaigns: []AppliedCampaign{}}
+	chosen, skipped := evaluateOffers(campaigns, ctx, policies, s.loyalty.PointValue)
+	var applications []*domain.CampaignApplication
+	for _, skip := range skipped {
+		if skip.Status == campaignHoldout {
+			applications = append(applications, &domain.CampaignApplication{
+				CampaignID:     skip.CampaignID,
+				OrderRef:       purchase.OrderID,
+				UserID:         user.ID,
+				Holdout:        true,
+				PurchaseAmount: purchase.Total,
+				AppliedAt:      purchase.PurchasedAt,
+			})
+		}
+	}
+	for _, candidate := range chosen {
+		campaign, outcome := candidate.Campaign, candidate.Outcome
+		applications = append(applications, &domain.CampaignApplication{
+			CampaignID:     campaign.ID,
+			OrderRef:       purchase.OrderID,
+			UserID:         user.ID,
+			VariantID:      outcome.VariantID,
+			PurchaseAmount: purchase.Total,
+			Points:         outcome.Points,
+			Discount:       outcome.Discount,
+			AppliedAt:      purchase.PurchasedAt,
+		})
+
+		result.Campaigns = append(result.Campaigns, AppliedCampaign{
+			CampaignID: campaign.ID,
+			Name:       campaign.Name,
+			Type:       campaign.Type,
+			Result:     outcome.Result,
+			Points:     outcome.Points,
+		})
+		if outcome.Points == 0 {
+			continue
+		}
+
+		campaignID := campaign.ID
+		key := campaignEarnKey(campaign.ID, purchase.OrderID)
+		entries = append(entries, &domain.PointsTransaction{
+			UserID:         user.ID,
+			Type:           domain.PointsEarn,
+			Points:         outcome.Points,
+			CampaignID:     &campaignID,
+			OrderRef:       purchase.OrderID,
+			Store:          purchase.Store,
+			IdempotencyKey: &key,
+		})
+		result.PointsEarned += outcome.Points
+	}
+
+	purchase.PointsEarned = result.PointsEarned
+	balance, withheld, err := s.purchaseRepo.Create(purchase, entries, applications)
+	if err != nil {
+		// Lost a race against a concurrent submission of the same order
+		if existing, findErr := s.purchaseRepo.FindByOrderID(purchase.OrderID); findErr == nil {
+			return s.duplicateResult(existing)
+		}
+		return nil, err
+	}
+
+	// Drop the campaigns the member's caps withheld and take the points the
+	// caps allowed
+	if len(withheld) > 0 || purchase.PointsEarned != result.PointsEarned {
+		capped := make(map[uuid.UUID]bool)
+		for _, id := range withheld {
+			capped[id] = true
+		}
+		points := make(map[uuid.UUID]int)
+		for _, application := range applications {
+			points[application.CampaignID] = application.Points
+		}
+		kept := result.Campaigns[:0]
+		for _, applied := range result.Campaigns {
+			if capped[applied.CampaignID] {
+				middleware.RecordCampaignUsage(applied.Type, "cap_reached")
+				continue
+			}
+			applied.Points = points[applied.CampaignID]
+			kept = append(kept, applied)
+		}
+		result.Campaigns = kept
+		result.PointsEarned = purchase.PointsEarned
+	}
+
+	for _, applied := range result.Campaigns {
+		middleware.RecordCampaignUsage(applied.Type, "success")
+	}
+	for _, observer := range s.observers {
+		observer.PurchaseRecorded(purchase, user, result.Campaigns)
+	}
+
+	result.Balance = balance
+	return result, nil
+}
+
+func (s *transactionService) GetPurchase(orderID string) (*domain.Purchase, error) {
+	return s.purchaseRepo.FindByOrderID(orderID)
+}
+
+func (s *transactionService) findMember(memberID, memberEmail string) (*domain.User, error) {
+	var user *domain.User
+	var err error
+	switch {
+	case memberID != "":
+		user, err = s.userRepo.FindByID(memberID)
+	case memberEmail != "":
+		user, err = s.userRepo.FindByEmail(memberEmail)
+	default:
+		return nil, errors.New("member id or email is required")
+	}
+	if err != nil {
+		return nil, errors.New("member not found")
+	}
+	return user, nil
+}
+
+func (s *transactionService) duplicateResult(existing *domain.Purchase) (*PurchaseResult, error) {
+	user, err := s.userRepo.FindByID(existing.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PurchaseResult{
+		Purchase:     existing,
+		PointsEarned: existing.PointsEarned,
+		Balance:      user.Points,
+		Campaigns:    []AppliedCampaign{},
+		Duplicate:    true,
+	}, nil
+}
+
+func validatePurchase(purchase *domain.Purchase) error {
+	if purchase.OrderID == "" {
+		return errors.New("order id is required")
+	}
+	if purchase.Total < 0 || purchase.Subtotal < 0 || purchase.Discount < 0 {
+		return errors.New("amounts must not be negative")
+	}
+
+	itemsTotal := 0.0
+	for i := range purchase.Items {
+		item := &purchase.Items[i]
+		if item.Quantity <= 0 {
+			return errors.New("item quantity must be positive")
+		}
+		if item.Total == 0 {
+			item.Total = item.UnitPrice * float64(item.Quantity)
+		}
+		itemsTotal += item.Total
+	}
+	if purchase.Subtotal == 0 {
+		purchase.Subtotal = itemsTotal
+	}
+
+	if purchase.PurchasedAt.IsZero() {
+		purchase.PurchasedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPurchaseRepository struct {
+	mock.Mock
+}
+
+func (m *MockPurchaseRepository) Create(purchase *domain.Purchase, entries []*domain.PointsTransaction, applications []*domain.CampaignApplication) (int, []uuid.UUID, error) {
+	args := m.Called(purchase, entries, applications)
+	if args.Get(1) == nil {
+		return args.Int(0), nil, args.Error(2)
+	}
+	return args.Int(0), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
+func (m *MockPurchaseRepository) FindByOrderID(orderID string) (*domain.Purchase, error) {
+	args := m.Called(orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Purchase), args.Error(1)
+}
+
+func (m *MockPurchaseRepository) ListBetween(from, to time.Time, offset, limit int) ([]*domain.Purchase, error) {
+	args := m.Called(from, to, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Purchase), args.Error(1)
+}
+
+func TestTransactionService_RecordPurchase(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Email: "member@example.com", Points: 10}
+	campaigns := []*domain.Campaign{
+		{
+			ID:             uuid.New(),
+			Name:           "Double points",
+			Type:           "points_multiplier",
+			Value:          2,
+			IsActive:       true,
+			ApprovalStatus: domain.ApprovalApproved,
+			StartDate:      time.Now().Add(-time.Hour),
+			EndDate:        time.Now().Add(time.Hour),
+		},
+		{
+			ID:             uuid.New(),
+			Name:           "Big spender",
+			Type:           "bonus_points",
+			Value:          500,
+			IsActive:       true,
+			ApprovalStatus: domain.ApprovalApproved,
+			StartDate:      time.Now().Add(-time.Hour),
+			EndDate:        time.Now().Add(time.Hour),
+			Conditions:     `{"min_purchase": 1000}`,
+		},
+	}
+
+	t.Run("earns base and campaign points", func(t *testing.T) {
+		purchaseRepo := new(MockPurchaseRepository)
+		userRepo := new(MockUserRepository)
+		campaignRepo := new(MockCampaignRepository)
+		tierRepo := new(MockTierRepository)
+
+		userRepo.On("FindByEmail", user.Email).Return(user, nil)
+		purchaseRepo.On("FindByOrderID", "order-1").Return(nil, assert.AnError)
+		tierRepo.On("List").Return([]*domain.Tier{}, nil)
+		campaignRepo.On("ListActive").Return(campaigns, nil)
+		campaignRepo.On("ListGroups").Return([]*domain.CampaignGroup{}, nil)
+		purchaseRepo.On("Create", mock.AnythingOfType("*domain.Purchase"), mock.MatchedBy(func(entries []*domain.PointsTransaction) bool {
+			return len(entries) == 2 && entries[0].Points == 120 && entries[0].CampaignID == nil &&
+				entries[1].Points == 240 && *entries[1].CampaignID == campaigns[0].ID
+		}), mock.MatchedBy(func(applications []*domain.CampaignApplication) bool {
+			return len(applications) == 1 && applications[0].CampaignID == campaigns[0].ID && applications[0].Points == 240 &&
+				applications[0].PurchaseAmount == 120
+		})).Return(370, nil, nil)
+
+		service := NewTransactionService(purchaseRepo, userRepo, campaignRepo, tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{PointsPerCurrencyUnit: 1})
+		result, err := service.RecordPurchase(&domain.Purchase{
+			OrderID: "order-1",
+			Total:   120,
+			Items:   []domain.PurchaseItem{{SKU: "A", Quantity: 2, UnitPrice: 60}},
+		}, "", user.Email)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Duplicate)
+		assert.Equal(t, 360, result.PointsEarned)
+		assert.Equal(t, 370, result.Balance)
+		assert.Equal(t, 120.0, result.Purchase.Subtotal)
+		assert.Len(t, result.Campaigns, 1)
+		purchaseRepo.AssertExpectations(t)
+	})
+
+	t.Run("campaign withheld by a member cap", func(t *testing.T) {
+		purchaseRepo := new(MockPurchaseRepository)
+		userRepo := new(MockUserRepository)
+		campaignRepo := new(MockCampaignRepository)
+		tierRepo := new(MockTierRepository)
+
+		userRepo.On("FindByEmail", user.Email).Return(user, nil)
+		purchaseRepo.On("FindByOrderID", "order-2").Return(nil, assert.AnError)
+		tierRepo.On("List").Return([]*domain.Tier{}, nil)
+		campaignRepo.On("ListActive").Return(campaigns, nil)
+		campaignRepo.On("ListGroups").Return([]*domain.CampaignGroup{}, nil)
+		purchaseRepo.On("Create", mock.AnythingOfType("*domain.Purchase"), mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				args.Get(0).(*domain.Purchase).PointsEarned = 120
+			}).
+			Return(130, []uuid.UUID{campaigns[0].ID}, nil)
+
+		service := NewTransactionService(purchaseRepo, userRepo, campaignRepo, tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{PointsPerCurrencyUnit: 1})
+		result, err := service.RecordPurchase(&domain.Purchase{
+			OrderID: "order-2",
+			Total:   120,
+			Items:   []domain.PurchaseItem{{SKU: "A", Quantity: 2, UnitPrice: 60}},
+		}, "", user.Email)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 120, result.PointsEarned)
+		assert.Equal(t, 130, result.Balance)
+		assert.Empty(t, result.Campaigns)
+	})
+
+	t.Run("repeated order does not earn again", func(t *testing.T) {
+		purchaseRepo := new(MockPurchaseRepository)
+		userRepo := new(MockUserRepository)
+		campaignRepo := new(MockCampaignRepository)
+		tierRepo := new(MockTierRepository)
+
+		existing := &domain.Purchase{OrderID: "order-1", UserID: user.ID, PointsEarned: 360}
+		userRepo.On("FindByID", user.ID.String()).Return(user, nil)
+		purchaseRepo.On("FindByOrderID", "order-1").Return(existing, nil)
+
+		service := NewTransactionService(purchaseRepo, userRepo, campaignRepo, tierRepo, new(MockSegmentRepository), noExperiments(), builtinTypes(), config.LoyaltyConfig{PointsPerCurrencyUnit: 1})
+		result, err := service.RecordPurchase(&domain.Purchase{OrderID: "order-1", Total: 120}, user.ID.String(), "")
+
+		assert.NoError(t, err)
+		assert.True(t, result.Duplicate)
+		assert.Equal(t, 360, result.PointsEarned)
+		purchaseRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
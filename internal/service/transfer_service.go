@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+)
+
+type TransferService interface {
+	SendPoints(senderID, recipientEmail string, points int, message string) (*domain.PointsTransfer, error)
+	AcceptTransfer(id, userID string) (*domain.PointsTransfer, int, error)
+	DeclineTransfer(id, userID string) (*domain.PointsTransfer, error)
+	CancelTransfer(id, userID string) (*domain.PointsTransfer, error)
+	ListTransfers(userID string, page, pageSize int) ([]*domain.PointsTransfer, int64, error)
+}
+
+type transferService struct {
+	transferRepo  repository.TransferRepository
+	householdRepo repository.HouseholdRepository
+	userRepo      repository.UserRepository
+	loyalty       config.LoyaltyConfig
+}
+
+func NewTransferService(transferRepo repository.TransferRepository, householdRepo repository.HouseholdRepository, userRepo repository.UserRepository, loyalty config.LoyaltyConfig) TransferService {
+	return &transferService{
+		transferRepo:  transferRepo,
+		householdRepo: householdRepo,
+		userRepo:      userRepo,
+		loyalty:       loyalty,
+	}
+}
+
+// SendPoints offers points to another member. Nothing moves until the
+// recipient accepts. Transfers that trip a fraud check are kept as blocked
+// for review and returned together with the error.
+func (s *transferService) SendPoints(senderID, recipientEmail string, points int, message string) (*domain.PointsTransfer, error) {
+	if points <= 0 {
+		return nil, errors.New("points must be positive")
+	}
+
+	sender, err := s.userRepo.FindByID(senderID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	recipient, err := s.userRepo.FindByEmail(strings.TrimSpace(recipientEmail))
+	if err != nil {
+		return nil, errors.New("recipient not found")
+	}
+	if recipient.ID == sender.ID {
+		return nil, errors.New("cannot transfer points to yourself")
+	}
+	if sender.Points < points {
+		return nil, repository.ErrInsufficientPoints
+	}
+
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+
+	sent, count, err := s.transferRepo.SumSentSince(sender.ID.String(), since)
+	if err != nil {
+		return nil, err
+	}
+	contributed, contributions, err := s.householdRepo.SumMovedSince(sender.ID.String(), domain.PointsTransferIn, since)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransferLimits(s.loyalty, sent+contributed, count+contributions, points); err != nil {
+		return nil, err
+	}
+
+	transfer := &domain.PointsTransfer{
+		SenderID:    sender.ID,
+		RecipientID: recipient.ID,
+		Points:      points,
+		Message:     message,
+		Status:      domain.TransferPending,
+		ExpiresAt:   now.Add(time.Duration(s.loyalty.TransferExpiryHours) * time.Hour),
+	}
+
+	reason, err := s.fraudCheck(sender, recipient, since)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		transfer.Status = domain.TransferBlocked
+		transfer.BlockReason = reason
+	}
+
+	if err := s.transferRepo.Create(transfer); err != nil {
+		return nil, err
+	}
+	if transfer.Status == domain.TransferBlocked {
+		return transfer, errors.New("transfer blocked: " + reason)
+	}
+	return transfer, nil
+}
+
+// checkTransferLimits checks a movement of points against the daily limits,
+// given what the member already moved in the last 24 hours. Household
+// contributions and withdrawals are held to the same limits as transfers.
+func checkTransferLimits(loyalty config.LoyaltyConfig, sent, count, points int) error {
+	if count >= loyalty.TransferMaxPerDay {
+		return fmt.Errorf("daily limit of %d transfers reached", loyalty.TransferMaxPerDay)
+	}
+	if sent+points > loyalty.TransferDailyLimit {
+		return fmt.Errorf("transfer exceeds the daily limit of %d points", loyalty.TransferDailyLimit)
+	}
+	return nil
+}
+
+// transferLimits are the daily limits the repositories check again under
+// the member's lock.
+func transferLimits(loyalty config.LoyaltyConfig) repository.TransferLimits {
+	return repository.TransferLimits{DailyPoints: loyalty.TransferDailyLimit, MaxPerDay: loyalty.TransferMaxPerDay}
+}
+
+// accountTooNew reports whether the member is too new to give points away.
+func accountTooNew(loyalty config.LoyaltyConfig, user *domain.User) bool {
+	minAge := time.Duration(loyalty.TransferMinAccountAgeDays) * 24 * time.Hour
+	return time.Since(user.CreatedAt) < minAge
+}
+
+// fraudCheck returns why a transfer looks suspicious, or an empty string.
+func (s *transferService) fraudCheck(sender, recipient *domain.User, since time.Time) (string, error) {
+	if accountTooNew(s.loyalty, sender) {
+		return "sender account too new", nil
+	}
+
+	// Many members feeding one account is a sign of points being pooled
+	// from fake accounts
+	senders, err := s.transferRepo.CountSendersSince(recipient.ID.String(), since)
+	if err != nil {
+		return "", err
+	}
+	if senders >= s.loyalty.TransferMaxSendersPerDay {
+		return "recipient receiving from too many members", nil
+	}
+
+	return "", nil
+}
+
+// AcceptTransfer moves the points to the recipient. It returns the transfer
+// and the recipient's new balance.
+func (s *transferService) AcceptTransfer(id, userID string) (*domain.PointsTransfer, int, error) {
+	transfer, err := s.findPending(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if transfer.RecipientID.String() != userID {
+		return nil, 0, errors.New("transfer not found")
+	}
+
+	if time.Now().After(transfer.ExpiresAt) {
+		if err := s.transferRepo.ChangeStatus(transfer, domain.TransferExpired); err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, errors.New("transfer has expired")
+	}
+
+	if _, err := s.transferRepo.Complete(transfer, transferLimits(s.loyalty)); err != nil {
+		return nil, 0, err
+	}
+
+	recipient, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return transfer, recipient.Points, nil
+}
+
+func (s *transferService) DeclineTransfer(id, userID string) (*domain.PointsTransfer, error) {
+	transfer, err := s.findPending(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.RecipientID.String() != userID {
+		return nil, errors.New("transfer not found")
+	}
+
+	if err := s.transferRepo.ChangeStatus(transfer, domain.TransferDeclined); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (s *transferService) CancelTransfer(id, userID string) (*domain.PointsTransfer, error) {
+	transfer, err := s.findPending(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.SenderID.String() != userID {
+		return nil, errors.New("transfer not found")
+	}
+
+	if err := s.transferRepo.ChangeStatus(transfer, domain.TransferCancelled); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (s *transferService) findPending(id string) (*domain.PointsTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("transfer not found")
+	}
+	if transfer.Status != domain.TransferPending {
+		return nil, repository.ErrTransferNotPending
+	}
+	return transfer, nil
+}
+
+func (s *transferService) ListTransfers(userID string, page, pageSize int) ([]*domain.PointsTransfer, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.transferRepo.ListByUser(userID, (page-1)*pageSize, pageSize)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gclub/internal/config"
+	"github.com/gclub/internal/domain"
+	"github.com/gclub/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferRepository) Create(transfer *domain.PointsTransfer) error {
+	args := m.Called(transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) FindByID(id string) (*domain.PointsTransfer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PointsTransfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) ListByUser(userID string, offset, limit int) ([]*domain.PointsTransfer, int64, error) {
+	args := m.Called(userID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.PointsTransfer), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTransferRepository) SumSentSince(senderID string, since time.Time) (int, int, error) {
+	args := m.Called(senderID, since)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockTransferRepository) CountSendersSince(recipientID string, since time.Time) (int, error) {
+	args := m.Called(recipientID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransferRepository) Complete(transfer *domain.PointsTransfer, limits repository.TransferLimits) (int, error) {
+	args := m.Called(transfer, limits)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransferRepository) ChangeStatus(transfer *domain.PointsTransfer, status string) error {
+	args := m.Called(transfer, status)
+	return args.Error(0)
+}
+
+func TestTransferService_SendPoints(t *testing.T) {
+	loyalty := config.LoyaltyConfig{
+		TransferDailyLimit:        1000,
+		TransferMaxPerDay:         3,
+		TransferMinAccountAgeDays: 30,
+		TransferMaxSendersPerDay:  2,
+		TransferExpiryHours:       72,
+	}
+	recipient := &domain.User{ID: uuid.New(), Email: "friend@example.com", CreatedAt: time.Now().AddDate(-1, 0, 0)}
+
+	tests := []struct {
+		name        string
+		sender      *domain.User
+		points      int
+		sent        int
+		count       int
+		contributed int
+		senders     int
+		wantStatus  string
+		wantErr     bool
+	}{
+		{
+			name:       "sends a pending transfer",
+			sender:     &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:     300,
+			wantStatus: domain.TransferPending,
+		},
+		{
+			name:    "over the daily points limit",
+			sender:  &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:  300,
+			sent:    800,
+			count:   1,
+			wantErr: true,
+		},
+		{
+			name:        "household contributions count towards the daily points limit",
+			sender:      &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:      300,
+			contributed: 800,
+			wantErr:     true,
+		},
+		{
+			name:    "over the daily transfer count",
+			sender:  &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:  100,
+			count:   3,
+			wantErr: true,
+		},
+		{
+			name:    "more than the sender holds",
+			sender:  &domain.User{ID: uuid.New(), Points: 100, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:  300,
+			wantErr: true,
+		},
+		{
+			name:       "blocks new accounts",
+			sender:     &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(0, 0, -2)},
+			points:     300,
+			wantStatus: domain.TransferBlocked,
+			wantErr:    true,
+		},
+		{
+			name:       "blocks recipients fed by many members",
+			sender:     &domain.User{ID: uuid.New(), Points: 800, CreatedAt: time.Now().AddDate(-1, 0, 0)},
+			points:     300,
+			senders:    2,
+			wantStatus: domain.TransferBlocked,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transferRepo := new(MockTransferRepository)
+			householdRepo := new(MockHouseholdRepository)
+			userRepo := new(MockUserRepository)
+
+			userRepo.On("FindByID", tt.sender.ID.String()).Return(tt.sender, nil)
+			userRepo.On("FindByEmail", recipient.Email).Return(recipient, nil)
+			transferRepo.On("SumSentSince", tt.sender.ID.String(), mock.AnythingOfType("time.Time")).Return(tt.sent, tt.count, nil)
+			householdRepo.On("SumMovedSince", tt.sender.ID.String(), domain.PointsTransferIn, mock.AnythingOfType("time.Time")).Return(tt.contributed, 0, nil)
+			transferRepo.On("CountSendersSince", recipient.ID.String(), mock.AnythingOfType("time.Time")).Return(tt.senders, nil)
+			transferRepo.On("Create", mock.AnythingOfType("*domain.PointsTransfer")).Return(nil)
+
+			service := NewTransferService(transferRepo, householdRepo, userRepo, loyalty)
+			transfer, err := service.SendPoints(tt.sender.ID.String(), recipient.Email, tt.points, "")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantStatus == "" {
+				assert.Nil(t, transfer)
+				transferRepo.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Equal(t, tt.wantStatus, transfer.Status)
+				assert.Equal(t, recipient.ID, transfer.RecipientID)
+			}
+		})
+	}
+}
+
+func TestTransferService_AcceptTransfer(t *testing.T) {
+	recipientID := uuid.New()
+
+	t.Run("only the recipient can accept", func(t *testing.T) {
+		transferRepo := new(MockTransferRepository)
+		transfer := &domain.PointsTransfer{ID: uuid.New(), RecipientID: recipientID, Status: domain.TransferPending, ExpiresAt: time.Now().Add(time.Hour)}
+		transferRepo.On("FindByID", transfer.ID.String()).Return(transfer, nil)
+
+		service := NewTransferService(transferRepo, new(MockHouseholdRepository), new(MockUserRepository), config.LoyaltyConfig{})
+		_, _, err := service.AcceptTransfer(transfer.ID.String(), uuid.New().String())
+
+		assert.Error(t, err)
+		transferRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired transfers are closed", func(t *testing.T) {
+		transferRepo := new(MockTransferRepository)
+		transfer := &domain.PointsTransfer{ID: uuid.New(), RecipientID: recipientID, Status: domain.TransferPending, ExpiresAt: time.Now().Add(-time.Hour)}
+		transferRepo.On("FindByID", transfer.ID.String()).Return(transfer, nil)
+		transferRepo.On("ChangeStatus", transfer, domain.TransferExpired).Return(nil)
+
+		service := NewTransferService(transferRepo, new(MockHouseholdRepository), new(MockUserRepository), config.LoyaltyConfig{})
+		_, _, err := service.AcceptTransfer(transfer.ID.String(), recipientID.String())
+
+		assert.Error(t, err)
+		transferRepo.AssertExpectations(t)
+		transferRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	})
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"time"
 
 	"github.com/gclub/internal/domain"
 	"github.com/gclub/internal/repository"
@@ -31,6 +32,10 @@ func (s *userService) Register(user *domain.User) error {
 		return errors.New("user already exists")
 	}
 
+	if err := validateProfile(user); err != nil {
+		return err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -38,6 +43,19 @@ func (s *userService) Register(user *domain.User) error {
 	}
 	user.Password = string(hashedPassword)
 
+	// Balances, roles and tiers are never taken from the sign-up request
+	user.Points = 0
+	user.Role = "member"
+	user.Tier = ""
+	user.TierGraceUntil = nil
+
+	// Every member gets a code to invite friends with
+	code, err := generateCouponCode("REF")
+	if err != nil {
+		return err
+	}
+	user.ReferralCode = &code
+
 	// Create user
 	return s.userRepo.Create(user)
 }
@@ -61,9 +79,42 @@ func (s *userService) GetUserByID(id string) (*domain.User, error) {
 }
 
 func (s *userService) UpdateUser(user *domain.User) error {
+	existing, err := s.userRepo.FindByID(user.ID.String())
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := validateProfile(user); err != nil {
+		return err
+	}
+
+	// Points only move through the ledger, tiers are earned, and the role
+	// and password have their own flows, so keep the stored values
+	user.Points = existing.Points
+	user.Role = existing.Role
+	user.Tier = existing.Tier
+	user.TierGraceUntil = existing.TierGraceUntil
+	user.Password = existing.Password
+	user.CreatedAt = existing.CreatedAt
+	user.ReferralCode = existing.ReferralCode
+	user.SignupIP = existing.SignupIP
+	user.DeviceID = existing.DeviceID
+
 	return s.userRepo.Update(user)
 }
 
 func (s *userService) DeleteUser(id string) error {
 	return s.userRepo.Delete(id)
 }
+
+func validateProfile(user *domain.User) error {
+	if user.Birthdate != nil && user.Birthdate.After(time.Now()) {
+		return errors.New("birthdate must be in the past")
+	}
+	if user.Timezone != "" {
+		if _, err := time.LoadLocation(user.Timezone); err != nil {
+			return errors.New("invalid timezone")
+		}
+	}
+	return nil
+}
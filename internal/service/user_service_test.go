@@ -2,8 +2,10 @@ package service
 
 import (
 	"testing"
+	"time"
 
 	"github.com/gclub/internal/domain"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
@@ -34,6 +36,14 @@ func (m *MockUserRepository) FindByEmail(email string) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByReferralCode(code string) (*domain.User, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(user *domain.User) error {
 	args := m.Called(user)
 	return args.Error(0)
@@ -44,6 +54,14 @@ func (m *MockUserRepository) Delete(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) List(offset, limit int) ([]*domain.User, error) {
+	args := m.Called(offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
 func TestUserService_Register(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
@@ -147,3 +165,58 @@ func TestUserService_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_Register_IgnoresPrivilegedFields(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo)
+
+	grace := time.Now().AddDate(1, 0, 0)
+	user := &domain.User{
+		Email:          "new@example.com",
+		Password:       "password123",
+		Points:         5000,
+		Role:           "admin",
+		Tier:           "platinum",
+		TierGraceUntil: &grace,
+	}
+	mockRepo.On("FindByEmail", "new@example.com").Return(nil, nil)
+	mockRepo.On("Create", user).Return(nil)
+
+	assert.NoError(t, service.Register(user))
+	assert.Equal(t, 0, user.Points)
+	assert.Equal(t, "member", user.Role)
+	assert.Equal(t, "", user.Tier)
+	assert.Nil(t, user.TierGraceUntil)
+}
+
+func TestUserService_UpdateUser_KeepsPrivilegedFields(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo)
+
+	grace := time.Now().AddDate(0, 1, 0)
+	existing := &domain.User{
+		ID:             uuid.New(),
+		Points:         120,
+		Role:           "member",
+		Tier:           "silver",
+		TierGraceUntil: &grace,
+	}
+	forged := time.Now().AddDate(5, 0, 0)
+	update := &domain.User{
+		ID:             existing.ID,
+		Name:           "New Name",
+		Points:         99999,
+		Role:           "admin",
+		Tier:           "platinum",
+		TierGraceUntil: &forged,
+	}
+	mockRepo.On("FindByID", existing.ID.String()).Return(existing, nil)
+	mockRepo.On("Update", update).Return(nil)
+
+	assert.NoError(t, service.UpdateUser(update))
+	assert.Equal(t, "New Name", update.Name)
+	assert.Equal(t, 120, update.Points)
+	assert.Equal(t, "member", update.Role)
+	assert.Equal(t, "silver", update.Tier)
+	assert.Equal(t, &grace, update.TierGraceUntil)
+}